@@ -0,0 +1,103 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GetCeremonies() ([]*contracts.Ceremony, error) {
+	var ceremonies []*contracts.Ceremony
+
+	_, err := c.client.R().
+		SetResult(&ceremonies).
+		Get(c.path("/api/awards"))
+
+	return ceremonies, err
+}
+
+func (c *Client) GetCeremonyByID(id int) (*contracts.CeremonyDetails, error) {
+	var ceremony contracts.CeremonyDetails
+
+	_, err := c.client.R().
+		SetResult(&ceremony).
+		Get(c.path("/api/awards/%d", id))
+
+	return &ceremony, err
+}
+
+func (c *Client) CreateCeremony(req *contracts.AuthenticatedRequest[*contracts.CreateCeremonyRequest]) (*contracts.Ceremony, error) {
+	var ceremony contracts.Ceremony
+
+	_, err := c.client.R().
+		SetResult(&ceremony).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/awards"))
+
+	return &ceremony, err
+}
+
+func (c *Client) UpdateCeremony(req *contracts.AuthenticatedRequest[*contracts.UpdateCeremonyRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Put(c.path("/api/awards/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) DeleteCeremony(req *contracts.AuthenticatedRequest[*contracts.DeleteCeremonyRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/awards/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) GetAwardCategories() ([]*contracts.AwardCategory, error) {
+	var categories []*contracts.AwardCategory
+
+	_, err := c.client.R().
+		SetResult(&categories).
+		Get(c.path("/api/awards/categories"))
+
+	return categories, err
+}
+
+func (c *Client) CreateAwardCategory(req *contracts.AuthenticatedRequest[*contracts.CreateAwardCategoryRequest]) (*contracts.AwardCategory, error) {
+	var category contracts.AwardCategory
+
+	_, err := c.client.R().
+		SetResult(&category).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/awards/categories"))
+
+	return &category, err
+}
+
+func (c *Client) CreateNomination(req *contracts.AuthenticatedRequest[*contracts.CreateNominationRequest]) (*contracts.Nomination, error) {
+	var nomination contracts.Nomination
+
+	_, err := c.client.R().
+		SetResult(&nomination).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/awards/%d/nominations", req.Request.CeremonyID))
+
+	return &nomination, err
+}
+
+func (c *Client) UpdateNomination(req *contracts.AuthenticatedRequest[*contracts.UpdateNominationRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Put(c.path("/api/awards/%d/nominations/%d", req.Request.CeremonyID, req.Request.NominationID))
+
+	return err
+}
+
+func (c *Client) DeleteNomination(req *contracts.AuthenticatedRequest[*contracts.DeleteNominationRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/awards/%d/nominations/%d", req.Request.CeremonyID, req.Request.NominationID))
+
+	return err
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"strconv"
+
+	"github.com/mkuptsov/movie-reviews/contracts"
+)
+
+// BulkImport imports the body, which is application/x-ndjson or text/csv as told by the content type.
+func (c *Client) BulkImport(req *contracts.AuthenticatedRequest[*contracts.BulkImportRequest], contentType string, body []byte) (*contracts.BulkReport, error) {
+	var report contracts.BulkReport
+
+	r := c.client.R().
+		SetResult(&report).
+		SetAuthToken(req.AccessToken).
+		SetHeader("Content-Type", contentType).
+		SetBody(body)
+	if req.Request.ChunkSize > 0 {
+		r.SetQueryParam("chunkSize", strconv.Itoa(req.Request.ChunkSize))
+	}
+	_, err := r.Post(c.path("/api/bulk/%s", req.Request.Entity))
+
+	return &report, err
+}
+
+func (c *Client) BulkExport(req *contracts.AuthenticatedRequest[*contracts.BulkExportRequest]) ([]byte, error) {
+	r := c.client.R().
+		SetAuthToken(req.AccessToken)
+	if req.Request.Format != "" {
+		r.SetQueryParam("format", req.Request.Format)
+	}
+	res, err := r.Get(c.path("/api/bulk/%s", req.Request.Entity))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body(), nil
+}
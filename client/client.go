@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/mkuptsov/movie-reviews/contracts"
 )
 
+const mergePatchContentType = "application/merge-patch+json"
+
 type Client struct {
 	client  *resty.Client
 	baseURL string
@@ -38,6 +41,55 @@ func (c *Client) path(f string, args ...any) string {
 	return fmt.Sprintf(c.baseURL+f, args...)
 }
 
+// NotModified reports whether the entity at the path still has the version, by the means of a conditional GET.
+func (c *Client) NotModified(path string, version int) (bool, error) {
+	return c.NotModifiedETag(path, etag(version))
+}
+
+// NotModifiedETag reports whether the entity at the path still has the entity tag, by the means of a conditional GET.
+func (c *Client) NotModifiedETag(path, tag string) (bool, error) {
+	res, err := c.client.R().
+		SetHeader("If-None-Match", tag).
+		Get(c.path(path))
+	if err != nil {
+		return false, err
+	}
+
+	return res.StatusCode() == http.StatusNotModified, nil
+}
+
+// ETag returns the entity tag of the entity at the path.
+func (c *Client) ETag(path string) (string, error) {
+	res, err := c.client.R().Get(c.path(path))
+	if err != nil {
+		return "", err
+	}
+
+	return res.Header().Get("ETag"), nil
+}
+
+// DeleteETag deletes the entity at the path on the condition that it still has the entity tag, e.g. the one of ETag.
+func (c *Client) DeleteETag(path, tag, token string) error {
+	_, err := c.client.R().
+		SetAuthToken(token).
+		SetHeader("If-Match", tag).
+		Delete(c.path(path))
+
+	return err
+}
+
+// ifMatch makes a request conditional on the version of the entity, if one is given.
+func ifMatch(version *int) map[string]string {
+	if version == nil {
+		return nil
+	}
+	return map[string]string{"If-Match": etag(*version)}
+}
+
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
 // func logRequest(client *resty.Client, request *resty.Request) error {
 // 	log.Printf("Request URL: %s", request.URL)
 // 	log.Printf("Request Method: %s", request.Method)
@@ -0,0 +1,53 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GetCollections(req *contracts.GetCollectionsRequest) (*contracts.PaginatedResponse[contracts.Collection], error) {
+	var res contracts.PaginatedResponse[contracts.Collection]
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/collections"))
+
+	return &res, err
+}
+
+func (c *Client) GetCollectionByID(id int) (*contracts.CollectionDetails, error) {
+	var collection contracts.CollectionDetails
+
+	_, err := c.client.R().
+		SetResult(&collection).
+		Get(c.path("/api/collections/%d", id))
+
+	return &collection, err
+}
+
+func (c *Client) CreateCollection(req *contracts.AuthenticatedRequest[*contracts.CreateCollectionRequest]) (*contracts.CollectionDetails, error) {
+	var collection contracts.CollectionDetails
+
+	_, err := c.client.R().
+		SetResult(&collection).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/collections"))
+
+	return &collection, err
+}
+
+func (c *Client) UpdateCollection(req *contracts.AuthenticatedRequest[*contracts.UpdateCollectionRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Put(c.path("/api/collections/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) DeleteCollection(req *contracts.AuthenticatedRequest[*contracts.DeleteCollectionRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/collections/%d", req.Request.ID))
+
+	return err
+}
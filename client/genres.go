@@ -39,16 +39,62 @@ func (c *Client) GetGenreByID(id int) (*contracts.Genre, error) {
 func (c *Client) UpdateGenre(req *contracts.AuthenticatedRequest[*contracts.UpdateGenreRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		SetBody(req.Request).
 		Put(c.path("/api/genres/%d", req.Request.ID))
 
 	return err
 }
 
+func (c *Client) PatchGenre(req *contracts.AuthenticatedRequest[*contracts.MergePatchRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
+		SetHeader("Content-Type", mergePatchContentType).
+		SetBody(req.Request.Patch).
+		Patch(c.path("/api/genres/%d", req.Request.ID))
+
+	return err
+}
+
 func (c *Client) DeleteGenre(req *contracts.AuthenticatedRequest[*contracts.DeleteGenreRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		Delete(c.path("/api/genres/%d", req.Request.ID))
 
 	return err
 }
+
+func (c *Client) CreateGenreAlias(req *contracts.AuthenticatedRequest[*contracts.CreateGenreAliasRequest]) (*contracts.Genre, error) {
+	var genre contracts.Genre
+
+	_, err := c.client.R().
+		SetResult(&genre).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/genres/%d/aliases", req.Request.ID))
+
+	return &genre, err
+}
+
+func (c *Client) DeleteGenreAlias(req *contracts.AuthenticatedRequest[*contracts.DeleteGenreAliasRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetPathParam("alias", req.Request.Name).
+		Delete(c.path("/api/genres/%d/aliases/{alias}", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) MergeGenre(req *contracts.AuthenticatedRequest[*contracts.MergeGenreRequest]) (*contracts.Genre, error) {
+	var genre contracts.Genre
+
+	_, err := c.client.R().
+		SetResult(&genre).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/genres/%d/merge", req.Request.ID))
+
+	return &genre, err
+}
@@ -0,0 +1,15 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GraphQL(req *contracts.AuthenticatedRequest[*contracts.GraphQLRequest]) (*contracts.GraphQLResponse, error) {
+	var res contracts.GraphQLResponse
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/graphql"))
+
+	return &res, err
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/mkuptsov/movie-reviews/contracts"
+)
+
+func (c *Client) UploadMovieImage(req *contracts.AuthenticatedRequest[*contracts.UploadImageRequest]) (*contracts.Image, error) {
+	return c.uploadImage(req, c.path("/api/movies/%d/images", req.Request.ID))
+}
+
+func (c *Client) UploadStarImage(req *contracts.AuthenticatedRequest[*contracts.UploadImageRequest]) (*contracts.Image, error) {
+	return c.uploadImage(req, c.path("/api/stars/%d/images", req.Request.ID))
+}
+
+func (c *Client) GetMovieImages(id int) ([]*contracts.Image, error) {
+	var images []*contracts.Image
+
+	_, err := c.client.R().
+		SetResult(&images).
+		Get(c.path("/api/movies/%d/images", id))
+
+	return images, err
+}
+
+func (c *Client) GetStarImages(id int) ([]*contracts.Image, error) {
+	var images []*contracts.Image
+
+	_, err := c.client.R().
+		SetResult(&images).
+		Get(c.path("/api/stars/%d/images", id))
+
+	return images, err
+}
+
+func (c *Client) GetImage(id int) (*contracts.Image, error) {
+	var image contracts.Image
+
+	_, err := c.client.R().
+		SetResult(&image).
+		Get(c.path("/api/images/%d", id))
+
+	return &image, err
+}
+
+// GetImageContent downloads a variant of an image, e.g. "original" or "thumb".
+func (c *Client) GetImageContent(id int, variant string) ([]byte, error) {
+	res, err := c.client.R().
+		Get(c.path("/api/images/%d/%s", id, variant))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body(), nil
+}
+
+func (c *Client) SetPrimaryImage(req *contracts.AuthenticatedRequest[*contracts.SetPrimaryImageRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Put(c.path("/api/images/%d/primary", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) DeleteImage(req *contracts.AuthenticatedRequest[*contracts.DeleteImageRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/images/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) uploadImage(req *contracts.AuthenticatedRequest[*contracts.UploadImageRequest], url string) (*contracts.Image, error) {
+	var image contracts.Image
+
+	formData := map[string]string{"primary": strconv.FormatBool(req.Request.Primary)}
+	if req.Request.Kind != "" {
+		formData["kind"] = req.Request.Kind
+	}
+
+	_, err := c.client.R().
+		SetResult(&image).
+		SetAuthToken(req.AccessToken).
+		SetFormData(formData).
+		SetFileReader("file", req.Request.FileName, bytes.NewReader(req.Request.Content)).
+		Post(url)
+
+	return &image, err
+}
@@ -0,0 +1,49 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GetJobs(req *contracts.AuthenticatedRequest[*contracts.GetJobsRequest]) (*contracts.PaginatedResponse[contracts.Job], error) {
+	var res contracts.PaginatedResponse[contracts.Job]
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetAuthToken(req.AccessToken).
+		SetQueryParams(req.Request.ToQueryParams()).
+		Get(c.path("/api/jobs"))
+
+	return &res, err
+}
+
+func (c *Client) GetJob(req *contracts.AuthenticatedRequest[*contracts.GetJobRequest]) (*contracts.Job, error) {
+	var job contracts.Job
+
+	_, err := c.client.R().
+		SetResult(&job).
+		SetAuthToken(req.AccessToken).
+		Get(c.path("/api/jobs/%d", req.Request.ID))
+
+	return &job, err
+}
+
+func (c *Client) EnqueueJob(req *contracts.AuthenticatedRequest[*contracts.EnqueueJobRequest]) (*contracts.Job, error) {
+	var job contracts.Job
+
+	_, err := c.client.R().
+		SetResult(&job).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/jobs"))
+
+	return &job, err
+}
+
+func (c *Client) RetryJob(req *contracts.AuthenticatedRequest[*contracts.RetryJobRequest]) (*contracts.Job, error) {
+	var job contracts.Job
+
+	_, err := c.client.R().
+		SetResult(&job).
+		SetAuthToken(req.AccessToken).
+		Post(c.path("/api/jobs/%d/retry", req.Request.ID))
+
+	return &job, err
+}
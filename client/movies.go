@@ -35,6 +35,17 @@ func (c *Client) GetMovies(req *contracts.GetMoviesRequest) (*contracts.Paginate
 	return &res, err
 }
 
+func (c *Client) GetMovieSuggestions(req *contracts.GetMovieSuggestionsRequest) ([]*contracts.MovieSuggestion, error) {
+	var suggestions []*contracts.MovieSuggestion
+
+	_, err := c.client.R().
+		SetResult(&suggestions).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/movies/suggestions"))
+
+	return suggestions, err
+}
+
 func (c *Client) UpdateMovie(req *contracts.AuthenticatedRequest[*contracts.UpdateMovieRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
@@ -44,10 +55,110 @@ func (c *Client) UpdateMovie(req *contracts.AuthenticatedRequest[*contracts.Upda
 	return err
 }
 
+func (c *Client) PatchMovie(req *contracts.AuthenticatedRequest[*contracts.MergePatchRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
+		SetHeader("Content-Type", mergePatchContentType).
+		SetBody(req.Request.Patch).
+		Patch(c.path("/api/movies/%d", req.Request.ID))
+
+	return err
+}
+
 func (c *Client) DeleteMovie(req *contracts.AuthenticatedRequest[*contracts.DeleteMovieRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		Delete(c.path("/api/movies/%d", req.Request.ID))
 
 	return err
 }
+
+func (c *Client) GetLocalizedMovieByID(id int, acceptLanguage string) (*contracts.MovieDetails, error) {
+	var movie contracts.MovieDetails
+
+	_, err := c.client.R().
+		SetResult(&movie).
+		SetHeader("Accept-Language", acceptLanguage).
+		Get(c.path("/api/movies/%d", id))
+
+	return &movie, err
+}
+
+func (c *Client) GetLocalizedMovies(req *contracts.GetMoviesRequest, acceptLanguage string) (*contracts.PaginatedResponse[contracts.Movie], error) {
+	var res contracts.PaginatedResponse[contracts.Movie]
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetHeader("Accept-Language", acceptLanguage).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/movies"))
+
+	return &res, err
+}
+
+func (c *Client) GetMovieTranslations(id int) ([]*contracts.MovieTranslation, error) {
+	var translations []*contracts.MovieTranslation
+
+	_, err := c.client.R().
+		SetResult(&translations).
+		Get(c.path("/api/movies/%d/translations", id))
+
+	return translations, err
+}
+
+func (c *Client) PutMovieTranslation(req *contracts.AuthenticatedRequest[*contracts.PutMovieTranslationRequest]) (*contracts.MovieTranslation, error) {
+	var translation contracts.MovieTranslation
+
+	_, err := c.client.R().
+		SetResult(&translation).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Put(c.path("/api/movies/%d/translations/%s", req.Request.ID, req.Request.Locale))
+
+	return &translation, err
+}
+
+func (c *Client) DeleteMovieTranslation(req *contracts.AuthenticatedRequest[*contracts.DeleteMovieTranslationRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/movies/%d/translations/%s", req.Request.ID, req.Request.Locale))
+
+	return err
+}
+
+func (c *Client) GetMovieWithRelations(req *contracts.GetMovieByIDRequest) (*contracts.MovieDetails, error) {
+	var movie contracts.MovieDetails
+
+	_, err := c.client.R().
+		SetResult(&movie).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/movies/%d", req.ID))
+
+	return &movie, err
+}
+
+func (c *Client) MergeMovie(req *contracts.AuthenticatedRequest[*contracts.MergeMovieRequest]) (*contracts.MovieDetails, error) {
+	var movie contracts.MovieDetails
+
+	_, err := c.client.R().
+		SetResult(&movie).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/movies/%d/merge", req.Request.ID))
+
+	return &movie, err
+}
+
+func (c *Client) GetMovieDuplicates(req *contracts.AuthenticatedRequest[*contracts.GetDuplicatesRequest]) ([]*contracts.MovieDuplicate, error) {
+	var duplicates []*contracts.MovieDuplicate
+
+	_, err := c.client.R().
+		SetResult(&duplicates).
+		SetAuthToken(req.AccessToken).
+		SetQueryParams(req.Request.ToQueryParams()).
+		Get(c.path("/api/movies/duplicates"))
+
+	return duplicates, err
+}
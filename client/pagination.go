@@ -16,11 +16,11 @@ func Paginate[I any, Req contracts.PaginationSetter](
 
 		items = append(items, res.Items...)
 
-		if len(items) >= res.Total {
+		if res.Next == nil {
 			break
 		}
 
-		req.SetPage(res.Page + 1)
+		req.SetAfter(*res.Next)
 	}
 	return items, nil
 }
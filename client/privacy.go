@@ -0,0 +1,33 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) ExportUserData(req *contracts.AuthenticatedRequest[*contracts.ExportUserDataRequest]) (*contracts.UserExport, error) {
+	var export contracts.UserExport
+
+	_, err := c.client.R().
+		SetResult(&export).
+		SetAuthToken(req.AccessToken).
+		Get(c.path("/api/users/%d/export", req.Request.UserID))
+
+	return &export, err
+}
+
+func (c *Client) ScheduleErasure(req *contracts.AuthenticatedRequest[*contracts.ScheduleErasureRequest]) (*contracts.Erasure, error) {
+	var erasure contracts.Erasure
+
+	_, err := c.client.R().
+		SetResult(&erasure).
+		SetAuthToken(req.AccessToken).
+		Post(c.path("/api/users/%d/erasure", req.Request.UserID))
+
+	return &erasure, err
+}
+
+func (c *Client) CancelErasure(req *contracts.AuthenticatedRequest[*contracts.CancelErasureRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/users/%d/erasure", req.Request.UserID))
+
+	return err
+}
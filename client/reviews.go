@@ -17,6 +17,7 @@ func (c *Client) CreateReview(req *contracts.AuthenticatedRequest[*contracts.Cre
 func (c *Client) UpdateReview(req *contracts.AuthenticatedRequest[*contracts.UpdateReviewRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		SetBody(req.Request).
 		Put(c.path("/api/users/%d/reviews/%d", req.Request.UserID, req.Request.ReviewID))
 
@@ -26,6 +27,7 @@ func (c *Client) UpdateReview(req *contracts.AuthenticatedRequest[*contracts.Upd
 func (c *Client) DeleteReview(req *contracts.AuthenticatedRequest[*contracts.DeleteReviewRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		Delete(c.path("/api/users/%d/reviews/%d", req.Request.UserID, req.Request.ReviewID))
 
 	return err
@@ -51,3 +53,14 @@ func (c *Client) GetReviews(req *contracts.GetReviewsRequest) (*contracts.Pagina
 
 	return &res, err
 }
+
+func (c *Client) GetReviewWithRelations(req *contracts.GetReviewRequest) (*contracts.Review, error) {
+	var review contracts.Review
+
+	_, err := c.client.R().
+		SetResult(&review).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/reviews/%d", req.ReviewID))
+
+	return &review, err
+}
@@ -35,19 +35,110 @@ func (c *Client) GetStars(req *contracts.GetStarsRequest) (*contracts.PaginatedR
 	return &res, err
 }
 
+func (c *Client) GetStarSuggestions(req *contracts.GetStarSuggestionsRequest) ([]*contracts.StarSuggestion, error) {
+	var suggestions []*contracts.StarSuggestion
+
+	_, err := c.client.R().
+		SetResult(&suggestions).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/stars/suggestions"))
+
+	return suggestions, err
+}
+
 func (c *Client) UpdateStar(req *contracts.AuthenticatedRequest[*contracts.UpdateStarRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		SetBody(req.Request).
 		Put(c.path("/api/stars/%d", req.Request.ID))
 
 	return err
 }
 
+func (c *Client) PatchStar(req *contracts.AuthenticatedRequest[*contracts.MergePatchRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
+		SetHeader("Content-Type", mergePatchContentType).
+		SetBody(req.Request.Patch).
+		Patch(c.path("/api/stars/%d", req.Request.ID))
+
+	return err
+}
+
 func (c *Client) DeleteStar(req *contracts.AuthenticatedRequest[*contracts.DeleteStarRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		Delete(c.path("/api/stars/%d", req.Request.ID))
 
 	return err
 }
+
+func (c *Client) GetStarWithRelations(req *contracts.GetStarByIDRequest) (*contracts.StarDetails, error) {
+	var star contracts.StarDetails
+
+	_, err := c.client.R().
+		SetResult(&star).
+		SetQueryParams(req.ToQueryParams()).
+		Get(c.path("/api/stars/%d", req.ID))
+
+	return &star, err
+}
+
+func (c *Client) GetStarFilmography(id int) (*contracts.Filmography, error) {
+	var filmography contracts.Filmography
+
+	_, err := c.client.R().
+		SetResult(&filmography).
+		Get(c.path("/api/stars/%d/filmography", id))
+
+	return &filmography, err
+}
+
+func (c *Client) GetCreditRoles() ([]*contracts.CreditRole, error) {
+	var roles []*contracts.CreditRole
+
+	_, err := c.client.R().
+		SetResult(&roles).
+		Get(c.path("/api/credit-roles"))
+
+	return roles, err
+}
+
+func (c *Client) CreateCreditRole(req *contracts.AuthenticatedRequest[*contracts.CreateCreditRoleRequest]) (*contracts.CreditRole, error) {
+	var role contracts.CreditRole
+
+	_, err := c.client.R().
+		SetResult(&role).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/credit-roles"))
+
+	return &role, err
+}
+
+func (c *Client) MergeStar(req *contracts.AuthenticatedRequest[*contracts.MergeStarRequest]) (*contracts.StarDetails, error) {
+	var star contracts.StarDetails
+
+	_, err := c.client.R().
+		SetResult(&star).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/stars/%d/merge", req.Request.ID))
+
+	return &star, err
+}
+
+func (c *Client) GetStarDuplicates(req *contracts.AuthenticatedRequest[*contracts.GetDuplicatesRequest]) ([]*contracts.StarDuplicate, error) {
+	var duplicates []*contracts.StarDuplicate
+
+	_, err := c.client.R().
+		SetResult(&duplicates).
+		SetAuthToken(req.AccessToken).
+		SetQueryParams(req.Request.ToQueryParams()).
+		Get(c.path("/api/stars/duplicates"))
+
+	return duplicates, err
+}
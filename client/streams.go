@@ -0,0 +1,78 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mkuptsov/movie-reviews/contracts"
+)
+
+// UpdateStream reads the live updates of a server-sent events stream.
+type UpdateStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Next blocks until the next update arrives, it returns io.EOF when the server ends the stream.
+func (s *UpdateStream) Next() (*contracts.LiveUpdate, error) {
+	var data strings.Builder
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "" && data.Len() > 0:
+			var update contracts.LiveUpdate
+			if err := json.Unmarshal([]byte(data.String()), &update); err != nil {
+				return nil, err
+			}
+			return &update, nil
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *UpdateStream) Close() error {
+	return s.body.Close()
+}
+
+func (c *Client) StreamMovieUpdates(id int) (*UpdateStream, error) {
+	return c.stream(c.path("/api/movies/%d/events", id), "")
+}
+
+func (c *Client) StreamUpdates(req *contracts.AuthenticatedRequest[*contracts.StreamUpdatesRequest]) (*UpdateStream, error) {
+	return c.stream(c.path("/api/events"), req.AccessToken)
+}
+
+// stream opens a server-sent events stream, it bypasses resty which reads the whole response.
+func (c *Client) stream(url, accessToken string) (*UpdateStream, error) {
+	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	res, err := c.client.GetClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		herr := contracts.HTTPError{}
+		_ = json.NewDecoder(res.Body).Decode(&herr)
+		return nil, &Error{Code: res.StatusCode, Message: herr.Message}
+	}
+
+	return &UpdateStream{body: res.Body, scanner: bufio.NewScanner(res.Body)}, nil
+}
@@ -0,0 +1,23 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GetTrash(req *contracts.AuthenticatedRequest[*contracts.GetTrashRequest]) (*contracts.PaginatedResponse[contracts.TrashItem], error) {
+	var res contracts.PaginatedResponse[contracts.TrashItem]
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetAuthToken(req.AccessToken).
+		SetQueryParams(req.Request.ToQueryParams()).
+		Get(c.path("/api/trash/%s", req.Request.Kind))
+
+	return &res, err
+}
+
+func (c *Client) RestoreFromTrash(req *contracts.AuthenticatedRequest[*contracts.RestoreRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Post(c.path("/api/trash/%s/%d/restore", req.Request.Kind, req.Request.ID))
+
+	return err
+}
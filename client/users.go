@@ -22,18 +22,43 @@ func (c *Client) GetUserByID(id int) (*contracts.User, error) {
 	return &u, err
 }
 
+// GetPrivateUserByID gets the user with the details only they and the admins may see, e.g. the scheduled erasure.
+func (c *Client) GetPrivateUserByID(req *contracts.AuthenticatedRequest[*contracts.GetUserByIDRequest]) (*contracts.User, error) {
+	var u contracts.User
+
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetResult(&u).
+		Get(c.path("/api/users/%d", req.Request.UserID))
+
+	return &u, err
+}
+
 func (c *Client) UpdateUser(req *contracts.AuthenticatedRequest[*contracts.UpdateUserRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		SetBody(req.Request).
 		Put(c.path("/api/users/%d", req.Request.UserID))
 
 	return err
 }
 
+func (c *Client) PatchUser(req *contracts.AuthenticatedRequest[*contracts.MergePatchRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
+		SetHeader("Content-Type", mergePatchContentType).
+		SetBody(req.Request.Patch).
+		Patch(c.path("/api/users/%d", req.Request.ID))
+
+	return err
+}
+
 func (c *Client) DeleteUser(req *contracts.AuthenticatedRequest[*contracts.DeleteUserRequest]) error {
 	_, err := c.client.R().
 		SetAuthToken(req.AccessToken).
+		SetHeaders(ifMatch(req.Request.Version)).
 		Delete(c.path("/api/users/%d", req.Request.UserID))
 
 	return err
@@ -0,0 +1,77 @@
+package client
+
+import "github.com/mkuptsov/movie-reviews/contracts"
+
+func (c *Client) GetWebhooks(req *contracts.AuthenticatedRequest[*contracts.GetWebhooksRequest]) ([]*contracts.Webhook, error) {
+	var webhooks []*contracts.Webhook
+
+	_, err := c.client.R().
+		SetResult(&webhooks).
+		SetAuthToken(req.AccessToken).
+		Get(c.path("/api/webhooks"))
+
+	return webhooks, err
+}
+
+func (c *Client) GetWebhook(req *contracts.AuthenticatedRequest[*contracts.GetWebhookRequest]) (*contracts.Webhook, error) {
+	var webhook contracts.Webhook
+
+	_, err := c.client.R().
+		SetResult(&webhook).
+		SetAuthToken(req.AccessToken).
+		Get(c.path("/api/webhooks/%d", req.Request.ID))
+
+	return &webhook, err
+}
+
+func (c *Client) CreateWebhook(req *contracts.AuthenticatedRequest[*contracts.CreateWebhookRequest]) (*contracts.Webhook, error) {
+	var webhook contracts.Webhook
+
+	_, err := c.client.R().
+		SetResult(&webhook).
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Post(c.path("/api/webhooks"))
+
+	return &webhook, err
+}
+
+func (c *Client) UpdateWebhook(req *contracts.AuthenticatedRequest[*contracts.UpdateWebhookRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		SetBody(req.Request).
+		Put(c.path("/api/webhooks/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) DeleteWebhook(req *contracts.AuthenticatedRequest[*contracts.DeleteWebhookRequest]) error {
+	_, err := c.client.R().
+		SetAuthToken(req.AccessToken).
+		Delete(c.path("/api/webhooks/%d", req.Request.ID))
+
+	return err
+}
+
+func (c *Client) GetWebhookDeliveries(req *contracts.AuthenticatedRequest[*contracts.GetWebhookDeliveriesRequest]) (*contracts.PaginatedResponse[contracts.WebhookDelivery], error) {
+	var res contracts.PaginatedResponse[contracts.WebhookDelivery]
+
+	_, err := c.client.R().
+		SetResult(&res).
+		SetAuthToken(req.AccessToken).
+		SetQueryParams(req.Request.ToQueryParams()).
+		Get(c.path("/api/webhooks/%d/deliveries", req.Request.WebhookID))
+
+	return &res, err
+}
+
+func (c *Client) ReplayWebhookDelivery(req *contracts.AuthenticatedRequest[*contracts.ReplayWebhookDeliveryRequest]) (*contracts.WebhookDelivery, error) {
+	var delivery contracts.WebhookDelivery
+
+	_, err := c.client.R().
+		SetResult(&delivery).
+		SetAuthToken(req.AccessToken).
+		Post(c.path("/api/webhooks/%d/deliveries/%d/replay", req.Request.WebhookID, req.Request.DeliveryID))
+
+	return &delivery, err
+}
@@ -0,0 +1,94 @@
+package contracts
+
+import "time"
+
+type Ceremony struct {
+	ID     int        `json:"id"`
+	Name   string     `json:"name"`
+	Year   int        `json:"year"`
+	HeldOn *time.Time `json:"held_on,omitempty"`
+}
+
+type CeremonyDetails struct {
+	Ceremony
+	Nominations []*Nomination `json:"nominations"`
+}
+
+type AwardCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Nomination struct {
+	ID       int             `json:"id"`
+	Ceremony *Ceremony       `json:"ceremony"`
+	Category *AwardCategory  `json:"category"`
+	Movie    *NominatedMovie `json:"movie"`
+	Star     *NominatedStar  `json:"star,omitempty"`
+	Winner   bool            `json:"winner"`
+}
+
+type NominatedMovie struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type NominatedStar struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type AwardSummary struct {
+	Wins        int           `json:"wins"`
+	Nominations int           `json:"nominations"`
+	Items       []*Nomination `json:"items"`
+}
+
+type GetCeremonyRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type CreateCeremonyRequest struct {
+	Name   string     `json:"name" validate:"min=1,max=255"`
+	Year   int        `json:"year" validate:"min=1900,max=2100"`
+	HeldOn *time.Time `json:"held_on,omitempty"`
+}
+
+type UpdateCeremonyRequest struct {
+	ID     int        `param:"id" validate:"nonzero"`
+	Name   string     `json:"name" validate:"min=1,max=255"`
+	Year   int        `json:"year" validate:"min=1900,max=2100"`
+	HeldOn *time.Time `json:"held_on,omitempty"`
+}
+
+type DeleteCeremonyRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type CreateAwardCategoryRequest struct {
+	Name string `json:"name" validate:"min=1,max=100"`
+}
+
+// CreateNominationRequest nominates a movie, and a star for personal categories such as Best Director.
+type CreateNominationRequest struct {
+	CeremonyID int  `param:"id" validate:"nonzero"`
+	CategoryID int  `json:"category_id" validate:"nonzero"`
+	MovieID    int  `json:"movie_id" validate:"nonzero"`
+	StarID     *int `json:"star_id,omitempty"`
+	Winner     bool `json:"winner"`
+}
+
+type UpdateNominationRequest struct {
+	CeremonyID   int  `param:"id" validate:"nonzero"`
+	NominationID int  `param:"nominationId" validate:"nonzero"`
+	CategoryID   int  `json:"category_id" validate:"nonzero"`
+	MovieID      int  `json:"movie_id" validate:"nonzero"`
+	StarID       *int `json:"star_id,omitempty"`
+	Winner       bool `json:"winner"`
+}
+
+type DeleteNominationRequest struct {
+	CeremonyID   int `param:"id" validate:"nonzero"`
+	NominationID int `param:"nominationId" validate:"nonzero"`
+}
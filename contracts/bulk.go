@@ -0,0 +1,31 @@
+package contracts
+
+// BulkReport tells how a bulk import went row by row. When it runs in a single transaction,
+// any failed row rolls the whole import back.
+type BulkReport struct {
+	Total      int             `json:"total"`
+	Created    int             `json:"created"`
+	Updated    int             `json:"updated"`
+	Failed     int             `json:"failed"`
+	RolledBack bool            `json:"rolled_back,omitempty"`
+	Errors     []*BulkRowError `json:"errors"`
+}
+
+type BulkRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// BulkImportRequest imports the NDJSON or CSV body, depending on its content type, into the entity,
+// which is one of genres, stars, movies and credits. The whole import runs in a single transaction
+// unless ChunkSize is given.
+type BulkImportRequest struct {
+	Entity    string `param:"entity" validate:"regexp=^(genres|stars|movies|credits)$"`
+	ChunkSize int    `query:"chunkSize" validate:"min=0"`
+}
+
+// BulkExportRequest streams all the records of the entity as NDJSON, which is the default, or CSV.
+type BulkExportRequest struct {
+	Entity string `param:"entity" validate:"regexp=^(genres|stars|movies|credits)$"`
+	Format string `query:"format" validate:"regexp=^(ndjson|csv)?$"`
+}
@@ -0,0 +1,67 @@
+package contracts
+
+import "time"
+
+type Collection struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	AvgRating   *float64  `json:"avg_rating,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CollectionDetails struct {
+	Collection
+	Movies []*CollectionMovie `json:"movies"`
+}
+
+type CollectionMovie struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+	AvgRating   *float64  `json:"avg_rating,omitempty"`
+}
+
+// MovieCollection is a collection of a movie with the movies just before and after it.
+type MovieCollection struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Position int              `json:"position"`
+	Previous *CollectionMovie `json:"previous,omitempty"`
+	Next     *CollectionMovie `json:"next,omitempty"`
+}
+
+type GetCollectionsRequest struct {
+	PaginatedRequest
+	Sort *string `query:"sort" validate:"sort=name|rating|created_at"`
+}
+
+func (r *GetCollectionsRequest) ToQueryParams() map[string]string {
+	params := r.PaginatedRequest.ToQueryParams()
+	if r.Sort != nil {
+		params["sort"] = *r.Sort
+	}
+	return params
+}
+
+type GetCollectionByIDRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+// CreateCollectionRequest lists the movies of the collection in their order, e.g. by the order of release.
+type CreateCollectionRequest struct {
+	Name        string `json:"name" validate:"min=1,max=255"`
+	Description string `json:"description"`
+	MovieIDs    []int  `json:"movie_ids"`
+}
+
+type UpdateCollectionRequest struct {
+	ID          int    `param:"id" validate:"nonzero"`
+	Name        string `json:"name" validate:"min=1,max=255"`
+	Description string `json:"description"`
+	MovieIDs    []int  `json:"movie_ids"`
+}
+
+type DeleteCollectionRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
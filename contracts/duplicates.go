@@ -0,0 +1,16 @@
+package contracts
+
+import "strconv"
+
+// GetDuplicatesRequest requests the report of possible duplicates, the most similar pairs first.
+type GetDuplicatesRequest struct {
+	Limit int `query:"limit" validate:"min=0,max=100"`
+}
+
+func (r *GetDuplicatesRequest) ToQueryParams() map[string]string {
+	params := make(map[string]string)
+	if r.Limit > 0 {
+		params["limit"] = strconv.Itoa(r.Limit)
+	}
+	return params
+}
@@ -1,8 +1,11 @@
 package contracts
 
 type Genre struct {
-	ID   int    `param:"id"`
-	Name string `json:"name"`
+	ID       int      `param:"id"`
+	Name     string   `json:"name"`
+	ParentID *int     `json:"parent_id,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Version  int      `json:"version"`
 }
 
 type GetGenreByIDRequest struct {
@@ -10,14 +13,35 @@ type GetGenreByIDRequest struct {
 }
 
 type CreateGenreRequest struct {
-	Name string `json:"name" validate:"min=3,max=50"`
+	Name     string `json:"name" validate:"min=3,max=50"`
+	ParentID *int   `json:"parent_id,omitempty"`
 }
 
 type UpdateGenreRequest struct {
-	ID   int    `param:"id" validate:"nonzero"`
-	Name string `json:"name" validate:"min=3,max=50"`
+	ID       int    `param:"id" validate:"nonzero"`
+	Name     string `json:"name" validate:"min=3,max=50"`
+	ParentID *int   `json:"parent_id,omitempty"`
+	// Version is sent as If-Match, the update is unconditional without it
+	Version *int `json:"-"`
 }
 
 type DeleteGenreRequest struct {
-	ID int `param:"id" validate:"nonzero"`
+	ID      int  `param:"id" validate:"nonzero"`
+	Version *int `json:"-"`
+}
+
+type CreateGenreAliasRequest struct {
+	ID   int    `param:"id" validate:"nonzero"`
+	Name string `json:"name" validate:"min=2,max=50"`
+}
+
+type DeleteGenreAliasRequest struct {
+	ID   int    `param:"id" validate:"nonzero"`
+	Name string `param:"alias" validate:"min=1"`
+}
+
+// MergeGenreRequest merges the genre into the target one, the name of the merged genre becomes an alias.
+type MergeGenreRequest struct {
+	ID       int `param:"id" validate:"nonzero"`
+	TargetID int `json:"target_id" validate:"nonzero"`
 }
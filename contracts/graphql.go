@@ -0,0 +1,21 @@
+package contracts
+
+import "encoding/json"
+
+type GraphQLRequest struct {
+	Query         string         `json:"query" validate:"nonzero"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse contains the data resolved despite the errors, if any. The code of an error is in its extensions.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []*GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
@@ -0,0 +1,68 @@
+package contracts
+
+import "time"
+
+type Image struct {
+	ID          int             `json:"id"`
+	MovieID     *int            `json:"movie_id,omitempty"`
+	StarID      *int            `json:"star_id,omitempty"`
+	Kind        string          `json:"kind"`
+	ContentType string          `json:"content_type"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Size        int             `json:"size"`
+	Primary     bool            `json:"primary"`
+	Variants    []*ImageVariant `json:"variants"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type ImageVariant struct {
+	Name        string `json:"name"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	URL         string `json:"url"`
+}
+
+// UploadImageRequest is sent by the client as multipart/form-data with the content in the "file" part.
+type UploadImageRequest struct {
+	ID       int
+	Kind     string
+	Primary  bool
+	FileName string
+	Content  []byte
+}
+
+type UploadMovieImageRequest struct {
+	ID      int    `param:"id" validate:"nonzero"`
+	Kind    string `form:"kind" validate:"regexp=^(poster|backdrop|still)$"`
+	Primary bool   `form:"primary"`
+}
+
+type UploadStarImageRequest struct {
+	ID      int    `param:"id" validate:"nonzero"`
+	Kind    string `form:"kind" validate:"regexp=^(photo)?$"`
+	Primary bool   `form:"primary"`
+}
+
+type GetImagesRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type GetImageRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type GetImageContentRequest struct {
+	ID      int    `param:"id" validate:"nonzero"`
+	Variant string `param:"variant" validate:"min=1"`
+}
+
+type SetPrimaryImageRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type DeleteImageRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
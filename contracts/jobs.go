@@ -0,0 +1,51 @@
+package contracts
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a background job. A failed job is retried with a growing backoff until it runs out of attempts.
+type Job struct {
+	ID          int             `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LastError   *string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+type GetJobsRequest struct {
+	PaginatedRequest
+	Status *string `query:"status" validate:"regexp=^(pending|running|succeeded|failed)?$"`
+	Kind   *string `query:"kind"`
+}
+
+func (r *GetJobsRequest) ToQueryParams() map[string]string {
+	params := r.PaginatedRequest.ToQueryParams()
+	if r.Status != nil {
+		params["status"] = *r.Status
+	}
+	if r.Kind != nil {
+		params["kind"] = *r.Kind
+	}
+	return params
+}
+
+type GetJobRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+// EnqueueJobRequest enqueues a job of one of the registered kinds, e.g. trash.purge.
+type EnqueueJobRequest struct {
+	Kind    string          `json:"kind" validate:"min=1,max=50"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type RetryJobRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
@@ -7,30 +7,78 @@ import (
 
 type Movie struct {
 	ID          int        `json:"id"`
+	Kind        string     `json:"kind"`
+	ParentID    *int       `json:"parent_id,omitempty"`
+	Number      *int       `json:"number,omitempty"`
 	Title       string     `json:"title"`
 	ReleaseDate time.Time  `json:"release_date"`
+	Language    string     `json:"language"`
+	Locale      *string    `json:"locale,omitempty"`
+	Original    *string    `json:"original_title,omitempty"`
 	AvgRating   *float64   `json:"avg_rating,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// Relations below are present in lists only when requested with include.
+	Genres  []*Genre       `json:"genres,omitempty"`
+	Cast    []*MovieCredit `json:"cast,omitempty"`
+	Reviews []*Review      `json:"reviews,omitempty"`
+	Image   *Image         `json:"image,omitempty"`
+	Images  []*Image       `json:"images,omitempty"`
 }
 
 type MovieDetails struct {
 	Movie
-	Description string         `json:"description"`
-	Version     int            `json:"version"`
-	Genres      []*Genre       `json:"genres"`
-	Cast        []*MovieCredit `json:"cast"`
+	Description     string             `json:"description"`
+	Tagline         *string            `json:"tagline,omitempty"`
+	Runtime         *int               `json:"runtime,omitempty"`
+	Countries       []string           `json:"countries,omitempty"`
+	SpokenLanguages []string           `json:"spoken_languages,omitempty"`
+	Certifications  []*Certification   `json:"certifications,omitempty"`
+	ExternalIDs     map[string]string  `json:"external_ids,omitempty"`
+	Collections     []*MovieCollection `json:"collections,omitempty"`
+	Awards          *AwardSummary      `json:"awards,omitempty"`
+	Version         int                `json:"version"`
+	Genres          []*Genre           `json:"genres"`
+	Cast            []*MovieCredit     `json:"cast"`
+}
+
+type Certification struct {
+	Country       string `json:"country" validate:"regexp=^[a-zA-Z]{2}$"`
+	Certification string `json:"certification" validate:"min=1,max=20"`
 }
 
 type GetMovieByIDRequest struct {
-	ID int `param:"id" validate:"nonzero"`
+	ID      int     `param:"id" validate:"nonzero"`
+	Fields  *string `query:"fields"`
+	Include *string `query:"include" validate:"include=genres|cast|reviews|image|images"`
+}
+
+func (r *GetMovieByIDRequest) ToQueryParams() map[string]string {
+	params := make(map[string]string)
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
+	return params
 }
 
 type GetMoviesRequest struct {
 	PaginatedRequest
-	StarID       *int    `query:"starID"`
-	SearchTerm   *string `query:"q"`
-	SortByRating *string `query:"sortByRating" validate:"sort"`
+	StarID   *int    `query:"starID"`
+	Kind     *string `query:"kind" validate:"regexp=^(movie|series|season|episode)$"`
+	ParentID *int    `query:"parentID"`
+	// AwardCategoryID and AwardWinner select nominated movies, e.g. Best Picture winners
+	AwardCategoryID *int    `query:"awardCategoryID"`
+	AwardWinner     *bool   `query:"awardWinner"`
+	SearchTerm      *string `query:"q"`
+	Language        *string `query:"lang" validate:"regexp=^[a-z]{2}$"`
+	Sort            *string `query:"sort" validate:"sort=rating|release_date|title|created_at"`
+	// Deprecated: SortByRating is an alias of sort=rating for asc and sort=-rating for desc, Sort takes precedence.
+	SortByRating *string `query:"sortByRating" validate:"regexp=^(asc|desc)$"`
+	Fields       *string `query:"fields"`
+	Include      *string `query:"include" validate:"include=genres|cast|reviews|image|images"`
 }
 
 func (r *GetMoviesRequest) ToQueryParams() map[string]string {
@@ -38,33 +86,143 @@ func (r *GetMoviesRequest) ToQueryParams() map[string]string {
 	if r.StarID != nil {
 		params["starID"] = strconv.Itoa(*r.StarID)
 	}
+	if r.Kind != nil {
+		params["kind"] = *r.Kind
+	}
+	if r.ParentID != nil {
+		params["parentID"] = strconv.Itoa(*r.ParentID)
+	}
+	if r.AwardCategoryID != nil {
+		params["awardCategoryID"] = strconv.Itoa(*r.AwardCategoryID)
+	}
+	if r.AwardWinner != nil {
+		params["awardWinner"] = strconv.FormatBool(*r.AwardWinner)
+	}
 	if r.SearchTerm != nil {
 		params["q"] = *r.SearchTerm
 	}
+	if r.Language != nil {
+		params["lang"] = *r.Language
+	}
+	if r.Sort != nil {
+		params["sort"] = *r.Sort
+	}
 	if r.SortByRating != nil {
 		params["sortByRating"] = *r.SortByRating
 	}
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
 	return params
 }
 
+// SortOrAlias returns the sort parameter, falling back to the one the deprecated sortByRating stands for.
+func (r *GetMoviesRequest) SortOrAlias() *string {
+	switch {
+	case r.Sort != nil || r.SortByRating == nil:
+		return r.Sort
+	case *r.SortByRating == "desc":
+		return Ptr("-rating")
+	default:
+		return Ptr("rating")
+	}
+}
+
 type CreateMovieRequest struct {
-	Title       string             `json:"title" validate:"min=1,max=255"`
-	ReleaseDate time.Time          `json:"release_date" validate:"nonzero"`
-	Description string             `json:"description"`
-	Genres      []int              `json:"genres"`
-	Cast        []*MovieCreditInfo `json:"cast"`
+	// Kind defaults to movie. Seasons and episodes require the parent title and their number within it.
+	Kind        string    `json:"kind,omitempty" validate:"regexp=^(movie|series|season|episode)?$"`
+	ParentID    *int      `json:"parent_id,omitempty"`
+	Number      *int      `json:"number,omitempty" validate:"min=1"`
+	Title       string    `json:"title" validate:"min=1,max=255"`
+	ReleaseDate time.Time `json:"release_date" validate:"nonzero"`
+	Description string    `json:"description"`
+	Language    string    `json:"language,omitempty" validate:"regexp=^([a-z]{2})?$"`
+	MovieMetadata
+	Genres []int              `json:"genres"`
+	Cast   []*MovieCreditInfo `json:"cast"`
 }
 
 type UpdateMovieRequest struct {
-	ID          int                `param:"id" validate:"nonzero"`
-	Title       string             `json:"title"`
-	ReleaseDate time.Time          `json:"release_date"`
-	Description string             `json:"description"`
-	Version     int                `json:"version" validate:"min=0"`
-	Genres      []int              `json:"genres"`
-	Cast        []*MovieCreditInfo `json:"cast"`
+	ID          int       `param:"id" validate:"nonzero"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+	Description string    `json:"description"`
+	Language    string    `json:"language,omitempty" validate:"regexp=^([a-z]{2})?$"`
+	Version     int       `json:"version" validate:"min=0"`
+	MovieMetadata
+	Genres []int              `json:"genres"`
+	Cast   []*MovieCreditInfo `json:"cast"`
+}
+
+// MovieMetadata holds the optional details of a movie shared by create and update requests.
+// Countries are ISO 3166-1 and languages are ISO 639-1 codes, external IDs are keyed by source, e.g. "imdb".
+type MovieMetadata struct {
+	Tagline         *string           `json:"tagline,omitempty" validate:"max=255"`
+	Runtime         *int              `json:"runtime,omitempty" validate:"min=1"`
+	Countries       []string          `json:"countries,omitempty" validate:"codes"`
+	SpokenLanguages []string          `json:"spoken_languages,omitempty" validate:"codes"`
+	Certifications  []*Certification  `json:"certifications,omitempty"`
+	ExternalIDs     map[string]string `json:"external_ids,omitempty" validate:"externalids"`
 }
 
 type DeleteMovieRequest struct {
+	ID      int  `param:"id" validate:"nonzero"`
+	Version *int `json:"-"`
+}
+
+type MovieSuggestion struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+	Similarity  float64   `json:"similarity"`
+}
+
+type GetMovieSuggestionsRequest struct {
+	Query string `query:"q" validate:"min=1,max=255"`
+	Limit int    `query:"limit" validate:"min=0,max=50"`
+}
+
+func (r *GetMovieSuggestionsRequest) ToQueryParams() map[string]string {
+	params := map[string]string{"q": r.Query}
+	if r.Limit > 0 {
+		params["limit"] = strconv.Itoa(r.Limit)
+	}
+	return params
+}
+
+type MovieTranslation struct {
+	Locale      string  `json:"locale"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type GetMovieTranslationsRequest struct {
 	ID int `param:"id" validate:"nonzero"`
 }
+
+type PutMovieTranslationRequest struct {
+	ID          int     `json:"-" param:"id" validate:"nonzero"`
+	Locale      string  `json:"-" param:"locale" validate:"regexp=^[a-zA-Z]{2}([-_][a-zA-Z]{2})?$"`
+	Title       *string `json:"title,omitempty" validate:"min=1,max=255"`
+	Description *string `json:"description,omitempty"`
+}
+
+type DeleteMovieTranslationRequest struct {
+	ID     int    `param:"id" validate:"nonzero"`
+	Locale string `param:"locale" validate:"regexp=^[a-zA-Z]{2}([-_][a-zA-Z]{2})?$"`
+}
+
+// MergeMovieRequest merges the title into the target one, which takes over its credits, genres and reviews.
+type MergeMovieRequest struct {
+	ID       int `param:"id" validate:"nonzero"`
+	TargetID int `json:"target_id" validate:"nonzero"`
+}
+
+type MovieDuplicate struct {
+	Movie      *Movie  `json:"movie"`
+	Duplicate  *Movie  `json:"duplicate"`
+	Similarity float64 `json:"similarity"`
+}
@@ -1,22 +1,77 @@
 package contracts
 
-import "strconv"
+import (
+	"encoding/json"
+	"strconv"
+)
 
+// PaginatedRequest selects a page either by its number or by a cursor taken from Next/Prev of a previous response.
+// Cursors are opaque and take precedence over the page number.
 type PaginatedRequest struct {
-	Page int `json:"page" query:"page"`
-	Size int `json:"size" query:"size"`
+	Page      int    `json:"page" query:"page"`
+	Size      int    `json:"size" query:"size"`
+	After     string `json:"after,omitempty" query:"after"`
+	Before    string `json:"before,omitempty" query:"before"`
+	WithTotal *bool  `json:"with_total,omitempty" query:"withTotal"`
 }
 
+// PaginatedResponse of a page-numbered request always has the page and the total, unless the total is explicitly
+// disabled. Cursor pages have no page number and have the total only on demand, Uncounted marks its absence.
 type PaginatedResponse[T any] struct {
-	Page  int  `json:"page" validate:"min=0"`
-	Size  int  `json:"size" validate:"min=0"`
-	Total int  `json:"total"`
-	Items []*T `json:"items"`
+	Page      int     `json:"page" validate:"min=0"`
+	Size      int     `json:"size" validate:"min=0"`
+	Total     int     `json:"total"`
+	Uncounted bool    `json:"-"`
+	Next      *string `json:"next,omitempty"`
+	Prev      *string `json:"prev,omitempty"`
+	Items     []*T    `json:"items"`
+}
+
+func (r PaginatedResponse[T]) MarshalJSON() ([]byte, error) {
+	res := struct {
+		Page  *int    `json:"page,omitempty"`
+		Size  int     `json:"size"`
+		Total *int    `json:"total,omitempty"`
+		Next  *string `json:"next,omitempty"`
+		Prev  *string `json:"prev,omitempty"`
+		Items []*T    `json:"items"`
+	}{Size: r.Size, Next: r.Next, Prev: r.Prev, Items: r.Items}
+
+	if r.Page > 0 {
+		res.Page = &r.Page
+	}
+	if !r.Uncounted {
+		res.Total = &r.Total
+	}
+	return json.Marshal(res)
+}
+
+func (r *PaginatedResponse[T]) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Page  int     `json:"page"`
+		Size  int     `json:"size"`
+		Total *int    `json:"total"`
+		Next  *string `json:"next"`
+		Prev  *string `json:"prev"`
+		Items []*T    `json:"items"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	*r = PaginatedResponse[T]{Page: res.Page, Size: res.Size, Next: res.Next, Prev: res.Prev, Items: res.Items}
+	if res.Total != nil {
+		r.Total = *res.Total
+	} else {
+		r.Uncounted = true
+	}
+	return nil
 }
 
 type PaginationSetter interface {
 	SetPage(page int)
 	SetSize(size int)
+	SetAfter(cursor string)
 }
 
 var _ PaginationSetter = (*PaginatedRequest)(nil)
@@ -29,13 +84,28 @@ func (r *PaginatedRequest) SetSize(size int) {
 	r.Size = size
 }
 
+func (r *PaginatedRequest) SetAfter(cursor string) {
+	r.Page = 0
+	r.Before = ""
+	r.After = cursor
+}
+
 func (r *PaginatedRequest) ToQueryParams() map[string]string {
-	params := make(map[string]string, 2)
+	params := make(map[string]string, 5)
 	if r.Page > 0 {
 		params["page"] = strconv.Itoa(r.Page)
 	}
 	if r.Size > 0 {
 		params["size"] = strconv.Itoa(r.Size)
 	}
+	if r.After != "" {
+		params["after"] = r.After
+	}
+	if r.Before != "" {
+		params["before"] = r.Before
+	}
+	if r.WithTotal != nil {
+		params["withTotal"] = strconv.FormatBool(*r.WithTotal)
+	}
 	return params
 }
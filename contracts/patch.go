@@ -0,0 +1,10 @@
+package contracts
+
+// MergePatchRequest carries an RFC 7396 merge patch of an entity. The members of the patch are named
+// as in the update request of the entity and null removes an optional member.
+type MergePatchRequest struct {
+	ID int
+	// Version is sent as If-Match, without it the patch applies to the version it was read against
+	Version *int
+	Patch   map[string]any
+}
@@ -0,0 +1,28 @@
+package contracts
+
+import "time"
+
+// UserExport is the archive of the personal data of a user.
+type UserExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Profile    *User     `json:"profile"`
+	Reviews    []*Review `json:"reviews"`
+}
+
+// Erasure is a scheduled erasure of a user account, which can be cancelled until EraseAt.
+type Erasure struct {
+	UserID  int       `json:"user_id"`
+	EraseAt time.Time `json:"erase_at"`
+}
+
+type ExportUserDataRequest struct {
+	UserID int `param:"userId" validate:"nonzero"`
+}
+
+type ScheduleErasureRequest struct {
+	UserID int `param:"userId" validate:"nonzero"`
+}
+
+type CancelErasureRequest struct {
+	UserID int `param:"userId" validate:"nonzero"`
+}
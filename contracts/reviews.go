@@ -14,12 +14,23 @@ type Review struct {
 	Content   string     `json:"content"`
 	CreatedAt time.Time  `json:"created_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Version   int        `json:"version"`
+	Movie     *Movie     `json:"movie,omitempty"`
+	User      *Author    `json:"user,omitempty"`
+}
+
+type Author struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
 }
 
 type GetReviewsRequest struct {
 	PaginatedRequest
-	MovieID *int `query:"movieId"`
-	UserID  *int `query:"userId"`
+	MovieID *int    `query:"movieId"`
+	UserID  *int    `query:"userId"`
+	Sort    *string `query:"sort" validate:"sort=created_at|rating"`
+	Fields  *string `query:"fields"`
+	Include *string `query:"include" validate:"include=movie|user"`
 }
 
 func (r *GetReviewsRequest) ToQueryParams() map[string]string {
@@ -30,11 +41,33 @@ func (r *GetReviewsRequest) ToQueryParams() map[string]string {
 	if r.UserID != nil {
 		params["userId"] = strconv.Itoa(*r.UserID)
 	}
+	if r.Sort != nil {
+		params["sort"] = *r.Sort
+	}
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
 	return params
 }
 
 type GetReviewRequest struct {
-	ReviewID int `param:"reviewId" validate:"nonzero"`
+	ReviewID int     `param:"reviewId" validate:"nonzero"`
+	Fields   *string `query:"fields"`
+	Include  *string `query:"include" validate:"include=movie|user"`
+}
+
+func (r *GetReviewRequest) ToQueryParams() map[string]string {
+	params := make(map[string]string)
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
+	return params
 }
 
 type CreateReviewRequest struct {
@@ -51,9 +84,12 @@ type UpdateReviewRequest struct {
 	Rating   int    `json:"rating" validate:"min=1,max=10"`
 	Title    string `json:"title" validate:"min=3,max=255"`
 	Content  string `json:"content" validate:"min=20,max=2000"`
+	// Version is sent as If-Match, the update is unconditional without it
+	Version *int `json:"-"`
 }
 
 type DeleteReviewRequest struct {
-	ReviewID int `param:"reviewId" validate:"nonzero"`
-	UserID   int `param:"userId" validate:"nonzero"`
+	ReviewID int  `param:"reviewId" validate:"nonzero"`
+	UserID   int  `param:"userId" validate:"nonzero"`
+	Version  *int `json:"-"`
 }
@@ -6,20 +6,59 @@ import (
 )
 
 type Star struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate time.Time  `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID        int           `json:"id"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	BirthDate time.Time     `json:"birth_date,omitempty"`
+	DeathDate *time.Time    `json:"death_date,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"`
+	Movies    []*StarCredit `json:"movies,omitempty"`
+	Image     *Image        `json:"image,omitempty"`
+	Images    []*Image      `json:"images,omitempty"`
 }
 
 type StarDetails struct {
 	Star
-	MiddleName *string `json:"middle_name,omitempty"`
-	BirthPlace *string `json:"birth_place,omitempty"`
-	Bio        *string `json:"bio,omitempty"`
+	MiddleName *string       `json:"middle_name,omitempty"`
+	BirthPlace *string       `json:"birth_place,omitempty"`
+	Bio        *string       `json:"bio,omitempty"`
+	Version    int           `json:"version"`
+	Awards     *AwardSummary `json:"awards,omitempty"`
+}
+
+type StarCredit struct {
+	Movie StarMovie `json:"movie"`
+	Credit
+}
+
+type StarMovie struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+}
+
+type Filmography struct {
+	StarID int                `json:"star_id"`
+	Total  int                `json:"total"`
+	Roles  []*FilmographyRole `json:"roles"`
+}
+
+type FilmographyRole struct {
+	Role       string               `json:"role"`
+	Department string               `json:"department"`
+	Count      int                  `json:"count"`
+	Credits    []*FilmographyCredit `json:"credits"`
+}
+
+type FilmographyCredit struct {
+	Movie StarMovie `json:"movie"`
+	Credit
+	OrderNo int `json:"order_no"`
+}
+
+type GetStarFilmographyRequest struct {
+	ID int `param:"id" validate:"nonzero"`
 }
 
 type CreateStarRequest struct {
@@ -33,12 +72,28 @@ type CreateStarRequest struct {
 }
 
 type GetStarByIDRequest struct {
-	ID int `param:"id" validate:"nonzero"`
+	ID      int     `param:"id" validate:"nonzero"`
+	Fields  *string `query:"fields"`
+	Include *string `query:"include" validate:"include=movies|image|images"`
+}
+
+func (r *GetStarByIDRequest) ToQueryParams() map[string]string {
+	params := make(map[string]string)
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
+	return params
 }
 
 type GetStarsRequest struct {
 	PaginatedRequest
-	MovieID *int `query:"movieID"`
+	MovieID *int    `query:"movieID"`
+	Sort    *string `query:"sort" validate:"sort=name|birth_date|created_at|credits"`
+	Fields  *string `query:"fields"`
+	Include *string `query:"include" validate:"include=movies|image|images"`
 }
 
 func (r *GetStarsRequest) ToQueryParams() map[string]string {
@@ -46,6 +101,36 @@ func (r *GetStarsRequest) ToQueryParams() map[string]string {
 	if r.MovieID != nil {
 		params["movieID"] = strconv.Itoa(*r.MovieID)
 	}
+	if r.Sort != nil {
+		params["sort"] = *r.Sort
+	}
+	if r.Fields != nil {
+		params["fields"] = *r.Fields
+	}
+	if r.Include != nil {
+		params["include"] = *r.Include
+	}
+	return params
+}
+
+type StarSuggestion struct {
+	ID         int       `json:"id"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	BirthDate  time.Time `json:"birth_date"`
+	Similarity float64   `json:"similarity"`
+}
+
+type GetStarSuggestionsRequest struct {
+	Query string `query:"q" validate:"min=1,max=255"`
+	Limit int    `query:"limit" validate:"min=0,max=50"`
+}
+
+func (r *GetStarSuggestionsRequest) ToQueryParams() map[string]string {
+	params := map[string]string{"q": r.Query}
+	if r.Limit > 0 {
+		params["limit"] = strconv.Itoa(r.Limit)
+	}
 	return params
 }
 
@@ -58,20 +143,60 @@ type UpdateStarRequest struct {
 	BirthPlace *string    `json:"birth_place,omitempty" validate:"max=100"`
 	DeathDate  *time.Time `json:"death_date,omitempty"`
 	Bio        *string    `json:"bio,omitempty"`
+	// Version is sent as If-Match, the update is unconditional without it
+	Version *int `json:"-"`
 }
 
 type DeleteStarRequest struct {
-	ID int `param:"id" validate:"nonzero"`
+	ID      int  `param:"id" validate:"nonzero"`
+	Version *int `json:"-"`
+}
+
+type Credit struct {
+	Role       string   `json:"role"`
+	Department string   `json:"department"`
+	Characters []string `json:"characters,omitempty"`
+	Job        *string  `json:"job,omitempty"`
+	Uncredited bool     `json:"uncredited,omitempty"`
+	Voice      bool     `json:"voice,omitempty"`
 }
 
 type MovieCredit struct {
-	Star    Star    `json:"star"`
-	Role    string  `json:"role"`
-	Details *string `json:"details"`
+	Star Star `json:"star"`
+	Credit
+	OrderNo int `json:"order_no"`
 }
 
+// MovieCreditInfo is a credit of a created or updated movie. Its billing order is the position in the cast list.
 type MovieCreditInfo struct {
-	StarID  int     `json:"star_id"`
-	Role    string  `json:"role"`
-	Details *string `json:"details"`
+	StarID     int      `json:"star_id" validate:"nonzero"`
+	Role       string   `json:"role" validate:"min=1,max=50"`
+	Characters []string `json:"characters,omitempty"`
+	Job        *string  `json:"job,omitempty" validate:"max=100"`
+	Uncredited bool     `json:"uncredited,omitempty"`
+	Voice      bool     `json:"voice,omitempty"`
+}
+
+type CreditRole struct {
+	Name       string `json:"name"`
+	Department string `json:"department"`
+	IsCast     bool   `json:"is_cast"`
+}
+
+type CreateCreditRoleRequest struct {
+	Name       string `json:"name" validate:"min=1,max=50"`
+	Department string `json:"department" validate:"min=1,max=50"`
+	IsCast     bool   `json:"is_cast"`
+}
+
+// MergeStarRequest merges the star into the target one, which takes over its credits.
+type MergeStarRequest struct {
+	ID       int `param:"id" validate:"nonzero"`
+	TargetID int `json:"target_id" validate:"nonzero"`
+}
+
+type StarDuplicate struct {
+	Star       *Star   `json:"star"`
+	Duplicate  *Star   `json:"duplicate"`
+	Similarity float64 `json:"similarity"`
 }
@@ -0,0 +1,16 @@
+package contracts
+
+import "encoding/json"
+
+// LiveUpdate is a change of a movie pushed by a server-sent events stream, e.g. review.created or rating.changed.
+type LiveUpdate struct {
+	Type    string          `json:"type"`
+	MovieID int             `json:"movie_id"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type StreamMovieUpdatesRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type StreamUpdatesRequest struct{}
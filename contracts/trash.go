@@ -0,0 +1,23 @@
+package contracts
+
+import "time"
+
+// TrashItem is a soft-deleted entity, which is purged for good at PurgeAt unless it is restored.
+type TrashItem struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAt    time.Time `json:"purge_at"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+}
+
+// GetTrashRequest lists the deleted entities of the kind, which is one of movies, stars, users and reviews.
+type GetTrashRequest struct {
+	PaginatedRequest
+	Kind string `param:"kind" validate:"regexp=^(movies|stars|users|reviews)$"`
+}
+
+type RestoreRequest struct {
+	Kind string `param:"kind" validate:"regexp=^(movies|stars|users|reviews)$"`
+	ID   int    `param:"id" validate:"nonzero"`
+}
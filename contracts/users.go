@@ -10,6 +10,8 @@ type User struct {
 	Bio       *string    `json:"bio,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	EraseAt   *time.Time `json:"erase_at,omitempty"`
+	Version   int        `json:"version"`
 }
 type GetUserByIDRequest struct {
 	UserID int `param:"userId" validate:"nonzero"`
@@ -18,11 +20,15 @@ type GetUserByUserNameRequest struct {
 	UserName string `param:"userName" validate:"nonzero"`
 }
 type UpdateUserRequest struct {
-	UserID int     `param:"userId" validate:"nonzero"`
-	Bio    *string `json:"bio"`
+	UserID int `param:"userId" validate:"nonzero"`
+	// Bio is kept as it is when absent, an empty one clears it
+	Bio *string `json:"bio"`
+	// Version is sent as If-Match, the update is unconditional without it
+	Version *int `json:"-"`
 }
 type DeleteUserRequest struct {
-	UserID int `param:"userId" validate:"nonzero"`
+	UserID  int  `param:"userId" validate:"nonzero"`
+	Version *int `json:"-"`
 }
 type SetUserRoleRequest struct {
 	UserID int    `param:"userId" validate:"nonzero"`
@@ -0,0 +1,78 @@
+package contracts
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is notified of the domain events of its types, "*" stands for all of them.
+// The secret signs the deliveries and is never returned.
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is a POST of an event to a webhook, it is retried with a growing backoff until it succeeds
+// or runs out of attempts. A replay is a new delivery of the same event.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Body           json.RawMessage `json:"body"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	ReplayOf       *int64          `json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type GetWebhooksRequest struct{}
+
+type GetWebhookRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"url"`
+	EventTypes []string `json:"event_types" validate:"eventtypes"`
+	Secret     string   `json:"secret" validate:"min=16,max=255"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	ID         int      `param:"id" validate:"nonzero"`
+	URL        string   `json:"url" validate:"url"`
+	EventTypes []string `json:"event_types" validate:"eventtypes"`
+	Secret     string   `json:"secret" validate:"min=16,max=255"`
+	Active     bool     `json:"active"`
+}
+
+type DeleteWebhookRequest struct {
+	ID int `param:"id" validate:"nonzero"`
+}
+
+type GetWebhookDeliveriesRequest struct {
+	PaginatedRequest
+	WebhookID int     `param:"id" validate:"nonzero"`
+	Status    *string `query:"status" validate:"regexp=^(pending|succeeded|failed)?$"`
+}
+
+func (r *GetWebhookDeliveriesRequest) ToQueryParams() map[string]string {
+	params := r.PaginatedRequest.ToQueryParams()
+	if r.Status != nil {
+		params["status"] = *r.Status
+	}
+	return params
+}
+
+type ReplayWebhookDeliveryRequest struct {
+	WebhookID  int   `param:"id" validate:"nonzero"`
+	DeliveryID int64 `param:"deliveryId" validate:"nonzero"`
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+func awardsAPIChecks(t *testing.T, c *client.Client) {
+	var bestPicture, bestDirector *contracts.AwardCategory
+	t.Run("awards.GetAwardCategories: success", func(t *testing.T) {
+		categories, err := c.GetAwardCategories()
+		require.NoError(t, err)
+		for _, category := range categories {
+			switch category.Name {
+			case "Best Picture":
+				bestPicture = category
+			case "Best Director":
+				bestDirector = category
+			}
+		}
+		require.NotNil(t, bestPicture)
+		require.NotNil(t, bestDirector)
+	})
+
+	t.Run("awards.CreateAwardCategory: already exists", func(t *testing.T) {
+		_, err := c.CreateAwardCategory(contracts.NewAuthenticated(&contracts.CreateAwardCategoryRequest{
+			Name: bestPicture.Name,
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "award category", "name", bestPicture.Name)
+	})
+
+	var oscars *contracts.Ceremony
+	t.Run("awards.CreateCeremony: success", func(t *testing.T) {
+		var err error
+		oscars, err = c.CreateCeremony(contracts.NewAuthenticated(&contracts.CreateCeremonyRequest{
+			Name:   "Academy Awards",
+			Year:   1978,
+			HeldOn: contracts.Ptr(time.Date(1978, time.April, 3, 0, 0, 0, 0, time.UTC)),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, oscars.ID)
+	})
+
+	t.Run("awards.CreateCeremony: already exists", func(t *testing.T) {
+		_, err := c.CreateCeremony(contracts.NewAuthenticated(&contracts.CreateCeremonyRequest{
+			Name: oscars.Name,
+			Year: oscars.Year,
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "Academy Awards ceremony", "year", 1978)
+	})
+
+	var picture, director *contracts.Nomination
+	t.Run("awards.CreateNomination: success", func(t *testing.T) {
+		var err error
+		picture, err = c.CreateNomination(contracts.NewAuthenticated(&contracts.CreateNominationRequest{
+			CeremonyID: oscars.ID,
+			CategoryID: bestPicture.ID,
+			MovieID:    starWars.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, starWars.Title, picture.Movie.Title)
+		require.Nil(t, picture.Star)
+
+		director, err = c.CreateNomination(contracts.NewAuthenticated(&contracts.CreateNominationRequest{
+			CeremonyID: oscars.ID,
+			CategoryID: bestDirector.ID,
+			MovieID:    starWars.ID,
+			StarID:     &lucas.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, lucas.ID, director.Star.ID)
+	})
+
+	t.Run("awards.CreateNomination: already exists", func(t *testing.T) {
+		_, err := c.CreateNomination(contracts.NewAuthenticated(&contracts.CreateNominationRequest{
+			CeremonyID: oscars.ID,
+			CategoryID: bestPicture.ID,
+			MovieID:    starWars.ID,
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "nomination", "movie_id", starWars.ID)
+	})
+
+	t.Run("awards.CreateNomination: deleted movie", func(t *testing.T) {
+		_, err := c.CreateNomination(contracts.NewAuthenticated(&contracts.CreateNominationRequest{
+			CeremonyID: oscars.ID,
+			CategoryID: bestPicture.ID,
+			MovieID:    trainspotting.ID,
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "movie", "id", trainspotting.ID)
+	})
+
+	t.Run("awards.CreateNomination: unknown category", func(t *testing.T) {
+		_, err := c.CreateNomination(contracts.NewAuthenticated(&contracts.CreateNominationRequest{
+			CeremonyID: oscars.ID,
+			CategoryID: fakeID,
+			MovieID:    kingsMan.ID,
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "award category", "id", fakeID)
+	})
+
+	t.Run("awards.UpdateNomination: winner", func(t *testing.T) {
+		err := c.UpdateNomination(contracts.NewAuthenticated(&contracts.UpdateNominationRequest{
+			CeremonyID:   oscars.ID,
+			NominationID: picture.ID,
+			CategoryID:   bestPicture.ID,
+			MovieID:      starWars.ID,
+			Winner:       true,
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		ceremony, err := c.GetCeremonyByID(oscars.ID)
+		require.NoError(t, err)
+		require.Len(t, ceremony.Nominations, 2)
+	})
+
+	t.Run("movies.GetMovieByID: awards summary", func(t *testing.T) {
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.NotNil(t, movie.Awards)
+		require.Equal(t, 1, movie.Awards.Wins)
+		require.Equal(t, 2, movie.Awards.Nominations)
+	})
+
+	t.Run("stars.GetStarByID: awards summary", func(t *testing.T) {
+		star, err := c.GetStarByID(lucas.ID)
+		require.NoError(t, err)
+		require.NotNil(t, star.Awards)
+		require.Equal(t, 0, star.Awards.Wins)
+		require.Equal(t, 1, star.Awards.Nominations)
+		require.Equal(t, bestDirector.Name, star.Awards.Items[0].Category.Name)
+	})
+
+	t.Run("movies.GetMovies: Best Picture winners", func(t *testing.T) {
+		res, err := c.GetMovies(&contracts.GetMoviesRequest{
+			AwardCategoryID: &bestPicture.ID,
+			AwardWinner:     contracts.Ptr(true),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		require.Equal(t, starWars.ID, res.Items[0].ID)
+
+		res, err = c.GetMovies(&contracts.GetMoviesRequest{
+			AwardCategoryID: &bestDirector.ID,
+			AwardWinner:     contracts.Ptr(true),
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.Items)
+	})
+
+	t.Run("awards.DeleteNomination: not found", func(t *testing.T) {
+		err := c.DeleteNomination(contracts.NewAuthenticated(&contracts.DeleteNominationRequest{
+			CeremonyID:   oscars.ID,
+			NominationID: fakeID,
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "nomination", "id", fakeID)
+	})
+
+	t.Run("awards.DeleteCeremony: success", func(t *testing.T) {
+		err := c.DeleteCeremony(contracts.NewAuthenticated(&contracts.DeleteCeremonyRequest{ID: oscars.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		_, err = c.GetCeremonyByID(oscars.ID)
+		requireNotFoundError(t, err, "ceremony", "id", oscars.ID)
+
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.Nil(t, movie.Awards)
+	})
+}
@@ -19,9 +19,19 @@ const (
 	postgresUser          = "user"
 	postgresPassword      = "pass"
 	postgresDb            = "moviereviewsdb"
+
+	// MinIO stands in for S3, so that images are stored the same way as in production
+	minioContainerName = "movie-reviews-e2e-minio"
+	minioUser          = "minioadmin"
+	minioPassword      = "minioadmin"
 )
 
-func prepareInfrastructure(t *testing.T, runFunc func(t *testing.T, connString string)) {
+type infrastructure struct {
+	PgConnString string
+	S3Endpoint   string
+}
+
+func prepareInfrastructure(t *testing.T, runFunc func(t *testing.T, infra *infrastructure)) {
 	// Start Postgres container
 	postgres, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
@@ -44,12 +54,36 @@ func prepareInfrastructure(t *testing.T, runFunc func(t *testing.T, connString s
 	require.NoError(t, err)
 	pgConnString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", postgresUser, postgresPassword, "localhost", postgresPort.Int(), postgresDb)
 
+	// Start MinIO container
+	minio, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Name:  minioContainerName,
+			Image: "minio/minio:RELEASE.2023-06-19T19-52-50Z",
+			Cmd:   []string{"server", "/data"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     minioUser,
+				"MINIO_ROOT_PASSWORD": minioPassword,
+			},
+			ExposedPorts: []string{"9000/tcp"},
+			WaitingFor:   wait.ForHTTP("/minio/health/live").WithPort("9000/tcp"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	defer cleanUp(t, minio.Terminate)
+
+	minioPort, err := minio.MappedPort(context.Background(), "9000")
+	require.NoError(t, err)
+
 	// Run migrations
 	time.Sleep(1 * time.Second) // It's a hack, but it works
 	runMigrations(t, pgConnString)
 
 	// Run tests
-	runFunc(t, pgConnString)
+	runFunc(t, &infrastructure{
+		PgConnString: pgConnString,
+		S3Endpoint:   fmt.Sprintf("http://localhost:%d", minioPort.Int()),
+	})
 }
 
 func runMigrations(t *testing.T, connString string) {
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	csvContentType    = "text/csv"
+)
+
+func bulkAPIChecks(t *testing.T, c *client.Client) {
+	t.Run("bulk.BulkImport: insufficient permissions", func(t *testing.T) {
+		_, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "genres"}, johnDoeToken),
+			ndjsonContentType, []byte(`{"name":"Film Noir"}`))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("bulk.BulkImport: unsupported content type", func(t *testing.T) {
+		_, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "genres"}, adminToken),
+			"application/json", []byte(`{"name":"Film Noir"}`))
+		requireBadRequestError(t, err, "unsupported content type")
+	})
+
+	t.Run("bulk.BulkImport: genres", func(t *testing.T) {
+		body := strings.Join([]string{
+			`{"name":"Film Noir","parent":"Drama"}`,
+			`{"name":"Neo-Noir","parent":"Film Noir"}`,
+		}, "\n")
+		report, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "genres"}, adminToken),
+			ndjsonContentType, []byte(body))
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Total)
+		require.Equal(t, 2, report.Created)
+		require.Empty(t, report.Errors)
+
+		genres, err := c.GetGenres()
+		require.NoError(t, err)
+		parents := make(map[string]*int)
+		for _, genre := range genres {
+			parents[genre.Name] = genre.ParentID
+		}
+		require.Equal(t, Drama.ID, *parents["Film Noir"])
+		require.NotNil(t, parents["Neo-Noir"])
+	})
+
+	t.Run("bulk.BulkImport: rolled back in a single transaction", func(t *testing.T) {
+		body := strings.Join([]string{
+			`{"name":"Heist","parent":"Action"}`,
+			`{"name":"Caper","parent":"Unknown Genre"}`,
+		}, "\n")
+		report, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "genres"}, adminToken),
+			ndjsonContentType, []byte(body))
+		require.NoError(t, err)
+		require.True(t, report.RolledBack)
+		require.Equal(t, 1, report.Failed)
+		require.Zero(t, report.Created)
+		require.Len(t, report.Errors, 1)
+		require.Equal(t, 2, report.Errors[0].Row)
+		require.Contains(t, report.Errors[0].Error, "Unknown Genre")
+
+		genres, err := c.GetGenres()
+		require.NoError(t, err)
+		for _, genre := range genres {
+			require.NotEqual(t, "Heist", genre.Name)
+		}
+	})
+
+	t.Run("bulk.BulkImport: stars and movies in chunks", func(t *testing.T) {
+		stars := strings.Join([]string{
+			"external_ids,first_name,last_name,birth_date",
+			"bulk:nm0000148,Harrison,Ford,1942-07-13",
+			"bulk:nm0000180,Sean,Young,1959-11-20",
+			"bulk:nm0000111,Rutger,Hauer,not a date",
+		}, "\n")
+		report, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "stars", ChunkSize: 2}, adminToken),
+			csvContentType, []byte(stars))
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Total)
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, 1, report.Failed)
+		require.False(t, report.RolledBack)
+		require.Equal(t, 3, report.Errors[0].Row)
+		require.Contains(t, report.Errors[0].Error, "birth_date")
+
+		movies := `{"external_ids":{"bulk":"tt0083658"},"title":"Blade Runner","release_date":"1982-06-25T00:00:00Z","genres":["Neo-Noir","Drama"]}`
+		report, err = c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "movies", ChunkSize: 2}, adminToken),
+			ndjsonContentType, []byte(movies))
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Created)
+
+		credits := strings.Join([]string{
+			`{"movie":"bulk:tt0083658","star":"bulk:nm0000148","role":"actor","characters":["Rick Deckard"],"order_no":0}`,
+			`{"movie":"bulk:tt0083658","star":"bulk:nm0000180","role":"actor","characters":["Rachael"],"order_no":1}`,
+			`{"movie":"bulk:tt0083658","star":"bulk:nm0000111","role":"actor","characters":["Roy Batty"],"order_no":2}`,
+		}, "\n")
+		report, err = c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "credits", ChunkSize: 2}, adminToken),
+			ndjsonContentType, []byte(credits))
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, 1, report.Failed)
+		require.Contains(t, report.Errors[0].Error, "nm0000111")
+	})
+
+	t.Run("bulk.BulkImport: update by external id", func(t *testing.T) {
+		movies := `{"external_ids":{"bulk":"tt0083658","other":"78"},"title":"Blade Runner","tagline":"Man has made his match... now it's his problem.","release_date":"1982-06-25T00:00:00Z","genres":["Neo-Noir"]}`
+		report, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "movies"}, adminToken),
+			ndjsonContentType, []byte(movies))
+		require.NoError(t, err)
+		require.Zero(t, report.Created)
+		require.Equal(t, 1, report.Updated)
+	})
+
+	t.Run("bulk.BulkExport: success", func(t *testing.T) {
+		movies, err := c.BulkExport(contracts.NewAuthenticated(&contracts.BulkExportRequest{Entity: "movies"}, adminToken))
+		require.NoError(t, err)
+		require.Contains(t, string(movies), `"external_ids":{"bulk":"tt0083658","other":"78"}`)
+		require.Contains(t, string(movies), `"genres":["Neo-Noir"]`)
+
+		genres, err := c.BulkExport(contracts.NewAuthenticated(&contracts.BulkExportRequest{Entity: "genres", Format: "csv"}, adminToken))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(genres), "name,parent\n"))
+		require.Contains(t, string(genres), "Neo-Noir,Film Noir\n")
+
+		credits, err := c.BulkExport(contracts.NewAuthenticated(&contracts.BulkExportRequest{Entity: "credits", Format: "csv"}, adminToken))
+		require.NoError(t, err)
+		require.Contains(t, string(credits), "bulk:tt0083658,bulk:nm0000148,actor,Rick Deckard,,false,false,0\n")
+	})
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+func collectionsAPIChecks(t *testing.T, c *client.Client) {
+	var collection *contracts.CollectionDetails
+	t.Run("collections.CreateCollection: success", func(t *testing.T) {
+		var err error
+		collection, err = c.CreateCollection(contracts.NewAuthenticated(&contracts.CreateCollectionRequest{
+			Name:        "Favorites",
+			Description: "Movies every editor has to watch",
+			MovieIDs:    []int{starWars.ID, kingsMan.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, collection.ID)
+		require.Len(t, collection.Movies, 2)
+		require.Equal(t, starWars.ID, collection.Movies[0].ID)
+		require.Equal(t, kingsMan.ID, collection.Movies[1].ID)
+		// only Star Wars has reviews, rated with 10 and 9
+		requireRatingEqual(t, 9.5, *collection.AvgRating)
+	})
+
+	t.Run("collections.CreateCollection: already exists", func(t *testing.T) {
+		_, err := c.CreateCollection(contracts.NewAuthenticated(&contracts.CreateCollectionRequest{
+			Name: collection.Name,
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "collection", "name", collection.Name)
+	})
+
+	t.Run("collections.CreateCollection: deleted movie", func(t *testing.T) {
+		_, err := c.CreateCollection(contracts.NewAuthenticated(&contracts.CreateCollectionRequest{
+			Name:     "Danny Boyle",
+			MovieIDs: []int{trainspotting.ID},
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "movie", "id", trainspotting.ID)
+	})
+
+	t.Run("collections.CreateCollection: insufficient permissions", func(t *testing.T) {
+		user := registerRandomUser(t, c)
+		token := login(t, c, user.Email, standardPassword)
+		_, err := c.CreateCollection(contracts.NewAuthenticated(&contracts.CreateCollectionRequest{
+			Name: "Mine",
+		}, token))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("movies.GetMovieByID: previous and next in collection", func(t *testing.T) {
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.Len(t, movie.Collections, 1)
+		require.Equal(t, collection.ID, movie.Collections[0].ID)
+		require.Equal(t, 1, movie.Collections[0].Position)
+		require.Nil(t, movie.Collections[0].Previous)
+		require.Equal(t, kingsMan.ID, movie.Collections[0].Next.ID)
+
+		movie, err = c.GetMovieByID(kingsMan.ID)
+		require.NoError(t, err)
+		require.Len(t, movie.Collections, 1)
+		require.Equal(t, 2, movie.Collections[0].Position)
+		require.Equal(t, starWars.ID, movie.Collections[0].Previous.ID)
+		require.Nil(t, movie.Collections[0].Next)
+	})
+
+	t.Run("collections.UpdateCollection: reorder", func(t *testing.T) {
+		err := c.UpdateCollection(contracts.NewAuthenticated(&contracts.UpdateCollectionRequest{
+			ID:          collection.ID,
+			Name:        "Editor's choice",
+			Description: collection.Description,
+			MovieIDs:    []int{kingsMan.ID, starWars.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		collection, err = c.GetCollectionByID(collection.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Editor's choice", collection.Name)
+		require.Equal(t, kingsMan.ID, collection.Movies[0].ID)
+		require.Equal(t, starWars.ID, collection.Movies[1].ID)
+	})
+
+	t.Run("collections.UpdateCollection: not found", func(t *testing.T) {
+		err := c.UpdateCollection(contracts.NewAuthenticated(&contracts.UpdateCollectionRequest{
+			ID:   fakeID,
+			Name: "Nothing",
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "collection", "id", fakeID)
+	})
+
+	t.Run("collections.GetCollections: success", func(t *testing.T) {
+		res, err := c.GetCollections(&contracts.GetCollectionsRequest{})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		require.Equal(t, collection.ID, res.Items[0].ID)
+	})
+
+	t.Run("collections.DeleteCollection: success", func(t *testing.T) {
+		err := c.DeleteCollection(contracts.NewAuthenticated(&contracts.DeleteCollectionRequest{ID: collection.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		_, err = c.GetCollectionByID(collection.ID)
+		requireNotFoundError(t, err, "collection", "id", collection.ID)
+
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.Empty(t, movie.Collections)
+	})
+}
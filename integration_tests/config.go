@@ -6,11 +6,14 @@ import (
 	"github.com/mkuptsov/movie-reviews/internal/config"
 )
 
-const testPaginationSize = 2
+const (
+	testPaginationSize = 2
+	testImageMaxSize   = 1 << 20
+)
 
-func getConfig(pgConnString string) *config.Config {
+func getConfig(infra *infrastructure) *config.Config {
 	return &config.Config{
-		DbURL: pgConnString,
+		DbURL: infra.PgConnString,
 		Port:  0, // random port
 		Jwt: config.JwtConfig{
 			Secret:           "secret",
@@ -25,6 +28,63 @@ func getConfig(pgConnString string) *config.Config {
 			DefaultSize: testPaginationSize,
 			MaxSize:     50,
 		},
+		Storage: config.StorageConfig{
+			Driver: "s3",
+			S3: config.S3Config{
+				Endpoint:     infra.S3Endpoint,
+				Region:       "us-east-1",
+				Bucket:       "media",
+				AccessKey:    minioUser,
+				SecretKey:    minioPassword,
+				PathStyle:    true,
+				CreateBucket: true,
+			},
+		},
+		Images: config.ImagesConfig{
+			MaxSize: testImageMaxSize,
+		},
+		Trash: config.TrashConfig{
+			Retention:     time.Hour * 720,
+			PurgeSchedule: "@hourly",
+		},
+		Privacy: config.PrivacyConfig{
+			ErasureGracePeriod: time.Hour * 168,
+		},
+		Jobs: config.JobsConfig{
+			Workers:      1,
+			PollInterval: time.Millisecond * 100,
+			LockTimeout:  time.Minute * 15,
+			MaxAttempts:  3,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   time.Minute,
+		},
+		Events: config.EventsConfig{
+			Dispatch:        true,
+			PollInterval:    time.Millisecond * 100,
+			BatchSize:       100,
+			LeaseTimeout:    time.Minute,
+			MaxAttempts:     5,
+			BaseBackoff:     time.Second,
+			MaxBackoff:      time.Minute,
+			Retention:       time.Hour * 168,
+			CleanupSchedule: "@daily",
+			SinkTimeout:     time.Second * 5,
+		},
+		Webhooks: config.WebhooksConfig{
+			Timeout:         time.Second * 5,
+			Retention:       time.Hour * 720,
+			CleanupSchedule: "@daily",
+		},
+		Live: config.LiveConfig{
+			Heartbeat:      time.Second * 15,
+			BufferSize:     32,
+			ReconnectDelay: time.Second,
+		},
+		GraphQL: config.GraphQLConfig{
+			MaxDepth:       10,
+			MaxParallelism: 20,
+			MaxNodes:       200,
+		},
 		Local:    false,
 		LogLevel: "error",
 	}
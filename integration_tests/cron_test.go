@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronParse(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"@hourly",
+		"@daily",
+		"@weekly",
+		"@monthly",
+		"@yearly",
+		"*/15 9-17 * * 1-5",
+		"0,30 0-6/2 1,15 1-12 0,7",
+		"5/10 * * * *",
+	}
+	for _, spec := range valid {
+		t.Run("cron.Parse: valid "+spec, func(t *testing.T) {
+			_, err := cron.Parse(spec)
+			require.NoError(t, err)
+		})
+	}
+
+	invalid := []struct {
+		spec string
+		msg  string
+	}{
+		{"", "must have 5 fields"},
+		{"* * * *", "must have 5 fields"},
+		{"* * * * * *", "must have 5 fields"},
+		{"@every 5m", "must have 5 fields"},
+		{"60 * * * *", "minute"},
+		{"* 24 * * *", "hour"},
+		{"* * 0 * *", "day of month"},
+		{"* * * 13 *", "month"},
+		{"* * * * 8", "day of week"},
+		{"5-1 * * * *", "invalid range"},
+		{"*/0 * * * *", "invalid step"},
+		{"a * * * *", "invalid value"},
+		{"1,,2 * * * *", "invalid value"},
+	}
+	for _, tc := range invalid {
+		t.Run("cron.Parse: invalid "+tc.spec, func(t *testing.T) {
+			_, err := cron.Parse(tc.spec)
+			require.ErrorContains(t, err, tc.msg)
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Monday
+	from := time.Date(2024, time.January, 15, 10, 30, 45, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", from, time.Date(2024, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"hourly", "@hourly", from, time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"strictly after", "@hourly", time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC), time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", from, time.Date(2024, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"same time tomorrow", "30 10 * * *", from, time.Date(2024, time.January, 16, 10, 30, 0, 0, time.UTC)},
+		{"working hours", "0 9-17 * * 1-5", time.Date(2024, time.January, 19, 17, 30, 0, 0, time.UTC), time.Date(2024, time.January, 22, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 0", "@weekly", from, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", from, time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", from, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", "@yearly", from, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", from, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"next leap day", "0 0 29 2 *", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"day of month or day of week", "0 0 13 * 5", from, time.Date(2024, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"day of month and any day of week", "0 0 13 * *", from, time.Date(2024, time.February, 13, 0, 0, 0, 0, time.UTC)},
+		{"end of year", "59 23 31 12 *", from, time.Date(2024, time.December, 31, 23, 59, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", from, time.Time{}},
+	}
+	for _, tc := range cases {
+		t.Run("cron.Next: "+tc.name, func(t *testing.T) {
+			s, err := cron.Parse(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, s.Next(tc.from))
+		})
+	}
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	cases := []struct {
+		interval time.Duration
+		want     string
+	}{
+		{0, ""},
+		{-time.Minute, ""},
+		{15 * time.Minute, "*/15 * * * *"},
+		{30 * time.Minute, "*/30 * * * *"},
+		{time.Hour, "0 */1 * * *"},
+		{6 * time.Hour, "0 */6 * * *"},
+		{24 * time.Hour, "0 */24 * * *"},
+	}
+	for _, tc := range cases {
+		t.Run("config.IntervalSchedule: "+tc.interval.String(), func(t *testing.T) {
+			spec, err := config.IntervalSchedule(tc.interval)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, spec)
+			if spec != "" {
+				_, err = cron.Parse(spec)
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	for _, interval := range []time.Duration{7 * time.Minute, 90 * time.Minute, 48 * time.Hour, 30 * time.Second} {
+		t.Run("config.IntervalSchedule: no cron equivalent for "+interval.String(), func(t *testing.T) {
+			_, err := config.IntervalSchedule(interval)
+			require.ErrorContains(t, err, "TRASH_PURGE_SCHEDULE")
+		})
+	}
+}
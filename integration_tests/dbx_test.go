@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactions(t *testing.T) {
+	prepareInfrastructure(t, func(t *testing.T, infra *infrastructure) {
+		transactionChecks(t, infra.PgConnString)
+	})
+}
+
+func transactionChecks(t *testing.T, connString string) {
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, connString)
+	require.NoError(t, err)
+	defer db.Close()
+
+	countGenres := func(t *testing.T, name string) int {
+		var count int
+		err := db.QueryRow(ctx, "SELECT count(*) FROM genres WHERE name = $1", name).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("dbx.InTransaction: rolled back on error", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := dbx.InTransaction(ctx, db, func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, "INSERT INTO genres (name) VALUES ('Rolled Back')")
+			require.NoError(t, err)
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+		require.Zero(t, countGenres(t, "Rolled Back"))
+	})
+
+	t.Run("dbx.InTransaction: committed on success", func(t *testing.T) {
+		err := dbx.InTransaction(ctx, db, func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, "INSERT INTO genres (name) VALUES ('Committed')")
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, countGenres(t, "Committed"))
+	})
+}
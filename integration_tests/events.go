@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+type sinkEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	SubjectID int             `json:"subject_id"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// eventSink records the events posted by the dispatcher, deduplicated by ID.
+type eventSink struct {
+	mu     sync.Mutex
+	events map[int64]*sinkEvent
+}
+
+func newEventSink() *eventSink {
+	return &eventSink{events: make(map[int64]*sinkEvent)}
+}
+
+func (s *eventSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event sinkEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.events[event.ID] = &event
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *eventSink) find(eventType string, subjectID int) *sinkEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event.Type == eventType && event.SubjectID == subjectID {
+			return event
+		}
+	}
+	return nil
+}
+
+func requireEventDelivered(t *testing.T, sink *eventSink, eventType string, subjectID int) *sinkEvent {
+	var event *sinkEvent
+	require.Eventually(t, func() bool {
+		event = sink.find(eventType, subjectID)
+		return event != nil
+	}, 10*time.Second, 100*time.Millisecond, "%s of %d is not delivered", eventType, subjectID)
+	return event
+}
+
+func eventsAPIChecks(t *testing.T, c *client.Client, sink *eventSink) {
+	var movie *contracts.MovieDetails
+
+	t.Run("events.CreateMovie: movie.created delivered", func(t *testing.T) {
+		var err error
+		movie, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "Alien",
+			ReleaseDate: time.Date(1979, time.May, 25, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Drama.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		event := requireEventDelivered(t, sink, "movie.created", movie.ID)
+		var payload struct {
+			Title string `json:"title"`
+		}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, "Alien", payload.Title)
+	})
+
+	t.Run("events.CreateReview: user.registered and review.posted delivered", func(t *testing.T) {
+		reviewer := registerRandomUser(t, c)
+		requireEventDelivered(t, sink, "user.registered", reviewer.ID)
+
+		review, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+			MovieID: movie.ID,
+			UserID:  reviewer.ID,
+			Rating:  9,
+			Title:   "In space no one can hear you scream",
+			Content: "A perfect blend of horror and science fiction.",
+		}, login(t, c, reviewer.Email, standardPassword)))
+		require.NoError(t, err)
+
+		event := requireEventDelivered(t, sink, "review.posted", review.ID)
+		var payload struct {
+			MovieID int `json:"movie_id"`
+			Rating  int `json:"rating"`
+		}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, movie.ID, payload.MovieID)
+		require.Equal(t, 9, payload.Rating)
+	})
+
+	t.Run("events.BulkImport: star.created and star.updated delivered", func(t *testing.T) {
+		star := `{"external_ids":{"bulk":"nm0000244"},"first_name":"Sigourney","last_name":"Weaver","birth_date":"1949-10-08T00:00:00Z"}`
+		for _, created := range []int{1, 0} {
+			report, err := c.BulkImport(contracts.NewAuthenticated(&contracts.BulkImportRequest{Entity: "stars"}, adminToken),
+				ndjsonContentType, []byte(star))
+			require.NoError(t, err)
+			require.Equal(t, created, report.Created)
+		}
+
+		stars, err := c.GetStars(&contracts.GetStarsRequest{Sort: contracts.Ptr("-created_at")})
+		require.NoError(t, err)
+		weaver := stars.Items[0]
+		require.Equal(t, "Weaver", weaver.LastName)
+
+		requireEventDelivered(t, sink, "star.created", weaver.ID)
+		requireEventDelivered(t, sink, "star.updated", weaver.ID)
+	})
+
+	t.Run("events.PutMovieTranslation: movie.updated delivered", func(t *testing.T) {
+		_, err := c.PutMovieTranslation(contracts.NewAuthenticated(&contracts.PutMovieTranslationRequest{
+			ID:     movie.ID,
+			Locale: "fr",
+			Title:  contracts.Ptr("Alien, le huitième passager"),
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		requireEventDelivered(t, sink, "movie.updated", movie.ID)
+	})
+
+	t.Run("events.DeleteMovie: movie.deleted delivered", func(t *testing.T) {
+		err := c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: movie.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		requireEventDelivered(t, sink, "movie.deleted", movie.ID)
+	})
+
+	t.Run("events.RestoreFromTrash: movie.restored delivered", func(t *testing.T) {
+		err := c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "movies", ID: movie.ID}, adminToken))
+		require.NoError(t, err)
+
+		event := requireEventDelivered(t, sink, "movie.restored", movie.ID)
+		var payload struct {
+			Title string `json:"title"`
+		}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, "Alien", payload.Title)
+	})
+
+	t.Run("events.MergeStar: star.deleted with merged_into delivered", func(t *testing.T) {
+		var ids []int
+		for i := 0; i < 2; i++ {
+			star, err := c.CreateStar(contracts.NewAuthenticated(&contracts.CreateStarRequest{
+				FirstName: "Tom",
+				LastName:  "Skerritt",
+				BirthDate: time.Date(1933, time.August, 25, 0, 0, 0, 0, time.UTC),
+			}, johnDoeToken))
+			require.NoError(t, err)
+			ids = append(ids, star.ID)
+		}
+
+		_, err := c.MergeStar(contracts.NewAuthenticated(&contracts.MergeStarRequest{ID: ids[0], TargetID: ids[1]}, johnDoeToken))
+		require.NoError(t, err)
+
+		event := requireEventDelivered(t, sink, "star.deleted", ids[0])
+		var payload struct {
+			MergedInto int `json:"merged_into"`
+		}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, ids[1], payload.MergedInto)
+		requireEventDelivered(t, sink, "star.updated", ids[1])
+	})
+}
@@ -1,8 +1,10 @@
 package tests
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/mkuptsov/movie-reviews/client"
 	"github.com/mkuptsov/movie-reviews/contracts"
@@ -93,6 +95,47 @@ func genresAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, req.Name, Spooky.Name)
 	})
 
+	t.Run("genres.UpdateGenre: stale version", func(t *testing.T) {
+		req := &contracts.UpdateGenreRequest{
+			ID:      Spooky.ID,
+			Name:    "Horror",
+			Version: contracts.Ptr(Spooky.Version - 1),
+		}
+		err := c.UpdateGenre(contracts.NewAuthenticated(req, johnDoeToken))
+		requireVersionMismatch(t, err, "genre", "id", Spooky.ID, *req.Version)
+	})
+
+	t.Run("genres.GetGenre: not modified", func(t *testing.T) {
+		path := fmt.Sprintf("/api/genres/%d", Spooky.ID)
+		notModified, err := c.NotModified(path, Spooky.Version)
+		require.NoError(t, err)
+		require.True(t, notModified)
+
+		notModified, err = c.NotModified(path, Spooky.Version-1)
+		require.NoError(t, err)
+		require.False(t, notModified)
+	})
+
+	t.Run("genres.PatchGenre: success", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    Spooky.ID,
+			Patch: map[string]any{"parent_id": Drama.ID},
+		}
+		err := c.PatchGenre(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		genre := getGenre(t, c, Spooky.ID)
+		require.Equal(t, Spooky.Name, genre.Name)
+		require.Equal(t, Drama.ID, *genre.ParentID)
+
+		req.Patch = map[string]any{"parent_id": nil}
+		err = c.PatchGenre(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		Spooky = getGenre(t, c, Spooky.ID)
+		require.Nil(t, Spooky.ParentID)
+	})
+
 	t.Run("genres.UpdateGenre: unauthorized", func(t *testing.T) {
 		req := &contracts.UpdateGenreRequest{
 			ID:   Spooky.ID,
@@ -148,3 +191,150 @@ func getGenre(t *testing.T, c *client.Client, id int) *contracts.Genre {
 
 	return u
 }
+
+func genreHierarchyAPIChecks(t *testing.T, c *client.Client) {
+	var scienceFiction, spaceOpera, sciFi *contracts.Genre
+	t.Run("genres.CreateGenre: subgenre", func(t *testing.T) {
+		var err error
+		scienceFiction, err = c.CreateGenre(contracts.NewAuthenticated(&contracts.CreateGenreRequest{
+			Name: "Science Fiction",
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		spaceOpera, err = c.CreateGenre(contracts.NewAuthenticated(&contracts.CreateGenreRequest{
+			Name:     "Space Opera",
+			ParentID: &scienceFiction.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, scienceFiction.ID, *spaceOpera.ParentID)
+
+		sciFi, err = c.CreateGenre(contracts.NewAuthenticated(&contracts.CreateGenreRequest{
+			Name: "Sci-Fi",
+		}, johnDoeToken))
+		require.NoError(t, err)
+	})
+
+	t.Run("genres.CreateGenre: unknown parent", func(t *testing.T) {
+		_, err := c.CreateGenre(contracts.NewAuthenticated(&contracts.CreateGenreRequest{
+			Name:     "Cyberpunk",
+			ParentID: contracts.Ptr(fakeID),
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "genre", "id", fakeID)
+	})
+
+	t.Run("genres.UpdateGenre: nested into itself", func(t *testing.T) {
+		err := c.UpdateGenre(contracts.NewAuthenticated(&contracts.UpdateGenreRequest{
+			ID:       scienceFiction.ID,
+			Name:     scienceFiction.Name,
+			ParentID: &spaceOpera.ID,
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "genre can't be nested into itself")
+	})
+
+	t.Run("genres.CreateGenreAlias: success", func(t *testing.T) {
+		genre, err := c.CreateGenreAlias(contracts.NewAuthenticated(&contracts.CreateGenreAliasRequest{
+			ID:   scienceFiction.ID,
+			Name: "S.F.",
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, []string{"S.F."}, genre.Aliases)
+	})
+
+	t.Run("genres.CreateGenreAlias: name of a genre", func(t *testing.T) {
+		_, err := c.CreateGenreAlias(contracts.NewAuthenticated(&contracts.CreateGenreAliasRequest{
+			ID:   scienceFiction.ID,
+			Name: "drama",
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "genre", "name", "drama")
+	})
+
+	t.Run("genres.CreateGenre: name of an alias", func(t *testing.T) {
+		_, err := c.CreateGenre(contracts.NewAuthenticated(&contracts.CreateGenreRequest{
+			Name: "s.f.",
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "genre alias", "name", "s.f.")
+	})
+
+	var first, second *contracts.MovieDetails
+	t.Run("genres.MergeGenre: success", func(t *testing.T) {
+		var err error
+		first, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "Forbidden Planet",
+			ReleaseDate: time.Date(1956, time.March, 15, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{sciFi.ID, Action.ID, scienceFiction.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		second, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "Solaris",
+			ReleaseDate: time.Date(1972, time.March, 20, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Drama.ID, sciFi.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		genre, err := c.MergeGenre(contracts.NewAuthenticated(&contracts.MergeGenreRequest{
+			ID:       sciFi.ID,
+			TargetID: scienceFiction.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"S.F.", "Sci-Fi"}, genre.Aliases)
+
+		movie, err := c.GetMovieByID(first.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{scienceFiction.ID, Action.ID}, genreIDs(movie.Genres))
+
+		movie, err = c.GetMovieByID(second.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{Drama.ID, scienceFiction.ID}, genreIDs(movie.Genres))
+
+		_, err = c.GetGenreByID(sciFi.ID)
+		requireNotFoundError(t, err, "genre", "id", sciFi.ID)
+	})
+
+	t.Run("genres.MergeGenre: into itself", func(t *testing.T) {
+		_, err := c.MergeGenre(contracts.NewAuthenticated(&contracts.MergeGenreRequest{
+			ID:       scienceFiction.ID,
+			TargetID: scienceFiction.ID,
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "genre can't be merged into itself")
+	})
+
+	t.Run("genres.MergeGenre: parent into subgenre", func(t *testing.T) {
+		genre, err := c.MergeGenre(contracts.NewAuthenticated(&contracts.MergeGenreRequest{
+			ID:       scienceFiction.ID,
+			TargetID: spaceOpera.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Nil(t, genre.ParentID)
+		require.ElementsMatch(t, []string{"S.F.", "Sci-Fi", "Science Fiction"}, genre.Aliases)
+	})
+
+	t.Run("genres.DeleteGenreAlias: success", func(t *testing.T) {
+		err := c.DeleteGenreAlias(contracts.NewAuthenticated(&contracts.DeleteGenreAliasRequest{
+			ID:   spaceOpera.ID,
+			Name: "Science Fiction",
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		err = c.DeleteGenreAlias(contracts.NewAuthenticated(&contracts.DeleteGenreAliasRequest{
+			ID:   spaceOpera.ID,
+			Name: "Science Fiction",
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "genre alias", "name", "Science Fiction")
+	})
+
+	for _, movie := range []*contracts.MovieDetails{first, second} {
+		err := c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: movie.ID}, johnDoeToken))
+		require.NoError(t, err)
+	}
+	err := c.DeleteGenre(contracts.NewAuthenticated(&contracts.DeleteGenreRequest{ID: spaceOpera.ID}, johnDoeToken))
+	require.NoError(t, err)
+}
+
+func genreIDs(genres []*contracts.Genre) []int {
+	ids := make([]int, len(genres))
+	for i, genre := range genres {
+		ids[i] = genre.ID
+	}
+	return ids
+}
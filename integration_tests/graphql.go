@@ -0,0 +1,353 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+const graphqlMovieQuery = `
+query Movie($id: Int!) {
+	movie(id: $id) {
+		title
+		genres { name }
+		cast {
+			role
+			characters
+			star {
+				firstName
+				credits { movie { title } }
+			}
+		}
+		reviews {
+			items {
+				rating
+				author { username reviews { items { movie { title } } } }
+			}
+		}
+	}
+}`
+
+const graphqlCreateReviewMutation = `
+mutation CreateReview($userId: Int!, $input: CreateReviewInput!) {
+	createReview(userId: $userId, input: $input) { id rating movie { title } author { username } }
+}`
+
+type graphqlMovie struct {
+	Title  string `json:"title"`
+	Genres []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Cast []struct {
+		Role       string   `json:"role"`
+		Characters []string `json:"characters"`
+		Star       struct {
+			FirstName string `json:"firstName"`
+			Credits   []struct {
+				Movie struct {
+					Title string `json:"title"`
+				} `json:"movie"`
+			} `json:"credits"`
+		} `json:"star"`
+	} `json:"cast"`
+	Reviews struct {
+		Items []struct {
+			Rating int `json:"rating"`
+			Author struct {
+				Username string `json:"username"`
+				Reviews  struct {
+					Items []struct {
+						Movie struct {
+							Title string `json:"title"`
+						} `json:"movie"`
+					} `json:"items"`
+				} `json:"reviews"`
+			} `json:"author"`
+		} `json:"items"`
+	} `json:"reviews"`
+}
+
+type graphqlReviewPage struct {
+	Items []struct {
+		Movie struct {
+			Title string `json:"title"`
+		} `json:"movie"`
+	} `json:"items"`
+	Next  *string `json:"next"`
+	Total *int    `json:"total"`
+}
+
+func graphql(t *testing.T, c *client.Client, token, query string, variables map[string]any) *contracts.GraphQLResponse {
+	res, err := c.GraphQL(contracts.NewAuthenticated(&contracts.GraphQLRequest{
+		Query:     query,
+		Variables: variables,
+	}, token))
+	require.NoError(t, err)
+	return res
+}
+
+// requireGraphQLData checks that the query has succeeded and unmarshals its data.
+func requireGraphQLData(t *testing.T, res *contracts.GraphQLResponse, data any) {
+	require.Empty(t, res.Errors)
+	require.NoError(t, json.Unmarshal(res.Data, data))
+}
+
+func requireGraphQLError(t *testing.T, res *contracts.GraphQLResponse, code, msg string) {
+	require.Len(t, res.Errors, 1)
+	require.Equal(t, code, res.Errors[0].Extensions["code"])
+	require.Contains(t, res.Errors[0].Message, msg)
+}
+
+func graphqlAPIChecks(t *testing.T, c *client.Client) {
+	alien, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Alien",
+		ReleaseDate: time.Date(1979, time.May, 25, 0, 0, 0, 0, time.UTC),
+		Genres:      []int{Drama.ID, Action.ID},
+		Cast: []*contracts.MovieCreditInfo{
+			{StarID: hamill.ID, Role: "actor", Characters: []string{"Dallas"}},
+			{StarID: lucas.ID, Role: "actor", Characters: []string{"Ash"}},
+		},
+	}, johnDoeToken))
+	require.NoError(t, err)
+	aliens, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Aliens",
+		ReleaseDate: time.Date(1986, time.July, 18, 0, 0, 0, 0, time.UTC),
+		Genres:      []int{Action.ID},
+		Cast: []*contracts.MovieCreditInfo{
+			{StarID: hamill.ID, Role: "actor", Characters: []string{"Hicks"}},
+		},
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	reviewer := registerRandomUser(t, c)
+	reviewerToken := login(t, c, reviewer.Email, standardPassword)
+	reviewInput := map[string]any{
+		"movieId": alien.ID,
+		"rating":  9,
+		"title":   "In space no one can hear you scream",
+		"content": "The scariest thing about it is how little it shows.",
+	}
+
+	t.Run("graphql.GraphQL: empty query", func(t *testing.T) {
+		_, err := c.GraphQL(contracts.NewAuthenticated(&contracts.GraphQLRequest{}, ""))
+		requireBadRequestError(t, err, "Query")
+	})
+
+	t.Run("graphql.GraphQL: movie not found", func(t *testing.T) {
+		var data struct {
+			Movie *graphqlMovie `json:"movie"`
+		}
+		res := graphql(t, c, "", graphqlMovieQuery, map[string]any{"id": fakeID})
+		requireGraphQLData(t, res, &data)
+		require.Nil(t, data.Movie)
+	})
+
+	t.Run("graphql.GraphQL: reviews without movie or user", func(t *testing.T) {
+		res := graphql(t, c, "", `{ reviews { items { id } } }`, nil)
+		requireGraphQLError(t, res, "BAD_REQUEST", "either movieId or userId must be provided")
+	})
+
+	t.Run("graphql.GraphQL: createReview without token", func(t *testing.T) {
+		res := graphql(t, c, "", graphqlCreateReviewMutation, map[string]any{"userId": reviewer.ID, "input": reviewInput})
+		requireGraphQLError(t, res, "UNAUTHORIZED", "invalid or missing token")
+	})
+
+	t.Run("graphql.GraphQL: createReview for another user", func(t *testing.T) {
+		res := graphql(t, c, reviewerToken, graphqlCreateReviewMutation, map[string]any{"userId": johnDoe.ID, "input": reviewInput})
+		requireGraphQLError(t, res, "FORBIDDEN", "insufficient permissions")
+	})
+
+	t.Run("graphql.GraphQL: createReview invalid rating", func(t *testing.T) {
+		input := map[string]any{
+			"movieId": alien.ID,
+			"rating":  11,
+			"title":   reviewInput["title"],
+			"content": reviewInput["content"],
+		}
+		res := graphql(t, c, reviewerToken, graphqlCreateReviewMutation, map[string]any{"userId": reviewer.ID, "input": input})
+		requireGraphQLError(t, res, "BAD_REQUEST", "Rating")
+	})
+
+	t.Run("graphql.GraphQL: createReview success", func(t *testing.T) {
+		var data struct {
+			CreateReview struct {
+				ID     int `json:"id"`
+				Rating int `json:"rating"`
+				Movie  struct {
+					Title string `json:"title"`
+				} `json:"movie"`
+				Author struct {
+					Username string `json:"username"`
+				} `json:"author"`
+			} `json:"createReview"`
+		}
+		res := graphql(t, c, reviewerToken, graphqlCreateReviewMutation, map[string]any{"userId": reviewer.ID, "input": reviewInput})
+		requireGraphQLData(t, res, &data)
+		require.NotZero(t, data.CreateReview.ID)
+		require.Equal(t, 9, data.CreateReview.Rating)
+		require.Equal(t, "Alien", data.CreateReview.Movie.Title)
+		require.Equal(t, reviewer.Username, data.CreateReview.Author.Username)
+	})
+
+	t.Run("graphql.GraphQL: nested movie", func(t *testing.T) {
+		var data struct {
+			Movie *graphqlMovie `json:"movie"`
+		}
+		res := graphql(t, c, "", graphqlMovieQuery, map[string]any{"id": alien.ID})
+		requireGraphQLData(t, res, &data)
+
+		movie := data.Movie
+		require.NotNil(t, movie)
+		require.Equal(t, "Alien", movie.Title)
+		require.Len(t, movie.Genres, 2)
+		require.Len(t, movie.Cast, 2)
+
+		credits := make(map[string][]string)
+		for _, credit := range movie.Cast {
+			for _, starCredit := range credit.Star.Credits {
+				credits[credit.Star.FirstName] = append(credits[credit.Star.FirstName], starCredit.Movie.Title)
+			}
+		}
+		require.Contains(t, credits[hamill.FirstName], "Alien")
+		require.Contains(t, credits[hamill.FirstName], aliens.Title)
+
+		require.Len(t, movie.Reviews.Items, 1)
+		require.Equal(t, reviewer.Username, movie.Reviews.Items[0].Author.Username)
+		require.Equal(t, "Alien", movie.Reviews.Items[0].Author.Reviews.Items[0].Movie.Title)
+	})
+
+	t.Run("graphql.GraphQL: user reviews paginated", func(t *testing.T) {
+		_, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+			MovieID: aliens.ID,
+			UserID:  reviewer.ID,
+			Rating:  8,
+			Title:   "This time it's war",
+			Content: "Louder than the first one, and it knows it.",
+		}, reviewerToken))
+		require.NoError(t, err)
+
+		var data struct {
+			User struct {
+				Reviews graphqlReviewPage `json:"reviews"`
+			} `json:"user"`
+		}
+		query := `query UserReviews($id: Int!, $after: String) {
+			user(id: $id) { reviews(size: 1, after: $after) { items { movie { title } } next total } }
+		}`
+		res := graphql(t, c, "", query, map[string]any{"id": reviewer.ID})
+		requireGraphQLData(t, res, &data)
+		first := data.User.Reviews
+		require.Len(t, first.Items, 1)
+		require.NotNil(t, first.Next)
+
+		res = graphql(t, c, "", query, map[string]any{"id": reviewer.ID, "after": *first.Next})
+		requireGraphQLData(t, res, &data)
+		second := data.User.Reviews
+		require.Len(t, second.Items, 1)
+		require.Nil(t, second.Next)
+		require.ElementsMatch(t, []string{"Alien", aliens.Title}, []string{first.Items[0].Movie.Title, second.Items[0].Movie.Title})
+	})
+
+	t.Run("graphql.GraphQL: reviews of the movies of a page", func(t *testing.T) {
+		type reviewPage struct {
+			Items []struct {
+				Rating int `json:"rating"`
+			} `json:"items"`
+			Next  *string `json:"next"`
+			Total *int    `json:"total"`
+		}
+		var data struct {
+			Movies struct {
+				Items []struct {
+					ID      int        `json:"id"`
+					Reviews reviewPage `json:"reviews"`
+					First   reviewPage `json:"first"`
+				} `json:"items"`
+			} `json:"movies"`
+		}
+		query := `query Movies($starId: Int) {
+			movies(starId: $starId, size: 50) {
+				items { id reviews(sort: "-rating") { items { rating } next total } first: reviews(size: 1) { items { rating } next total } }
+			}
+		}`
+		res := graphql(t, c, "", query, map[string]any{"starId": hamill.ID})
+		requireGraphQLData(t, res, &data)
+
+		// the aliases page the reviews differently, both are loaded for all the movies of the page
+		ratings := make(map[int]int)
+		for _, m := range data.Movies.Items {
+			if m.ID != alien.ID && m.ID != aliens.ID {
+				continue
+			}
+			for _, page := range []reviewPage{m.Reviews, m.First} {
+				require.Len(t, page.Items, 1)
+				require.Nil(t, page.Next)
+				require.Equal(t, 1, *page.Total)
+			}
+			ratings[m.ID] = m.Reviews.Items[0].Rating
+		}
+		require.Equal(t, map[int]int{alien.ID: 9, aliens.ID: 8}, ratings)
+	})
+
+	t.Run("graphql.GraphQL: too many objects", func(t *testing.T) {
+		// every alias lists all the genres again, together they exceed the limit of the test config
+		var query strings.Builder
+		query.WriteString("{")
+		for i := 0; i < 201; i++ {
+			fmt.Fprintf(&query, " g%d: genres { id }", i)
+		}
+		query.WriteString(" }")
+
+		res := graphql(t, c, "", query.String(), nil)
+		require.NotEmpty(t, res.Errors)
+		require.Equal(t, "BAD_REQUEST", res.Errors[0].Extensions["code"])
+		require.Contains(t, res.Errors[0].Message, "more than 200 objects")
+	})
+
+	t.Run("graphql.GraphQL: movies page", func(t *testing.T) {
+		var data struct {
+			Movies struct {
+				Items []struct {
+					Title  string `json:"title"`
+					Genres []struct {
+						Name string `json:"name"`
+					} `json:"genres"`
+				} `json:"items"`
+				Next *string `json:"next"`
+			} `json:"movies"`
+		}
+		query := `query Movies($starId: Int) { movies(starId: $starId, sort: "release_date", size: 1) { items { title genres { name } } next } }`
+		res := graphql(t, c, "", query, map[string]any{"starId": hamill.ID})
+		requireGraphQLData(t, res, &data)
+		require.Len(t, data.Movies.Items, 1)
+		require.NotEmpty(t, data.Movies.Items[0].Genres)
+		require.NotNil(t, data.Movies.Next)
+	})
+
+	t.Run("graphql.GraphQL: createGenre insufficient permissions", func(t *testing.T) {
+		res := graphql(t, c, reviewerToken, `mutation { createGenre(input: {name: "Space Horror"}) { id } }`, nil)
+		requireGraphQLError(t, res, "FORBIDDEN", "insufficient permissions")
+	})
+
+	t.Run("graphql.GraphQL: createGenre success", func(t *testing.T) {
+		var data struct {
+			CreateGenre struct {
+				Name   string `json:"name"`
+				Parent struct {
+					Name string `json:"name"`
+				} `json:"parent"`
+			} `json:"createGenre"`
+		}
+		query := `mutation CreateGenre($parentId: Int) { createGenre(input: {name: "Space Horror", parentId: $parentId}) { name parent { name } } }`
+		res := graphql(t, c, johnDoeToken, query, map[string]any{"parentId": Drama.ID})
+		requireGraphQLData(t, res, &data)
+		require.Equal(t, "Space Horror", data.CreateGenre.Name)
+		require.Equal(t, Drama.Name, data.CreateGenre.Parent.Name)
+	})
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func imagesAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
+	var poster1, poster2, photo *contracts.Image
+	t.Run("images.UploadMovieImage: success", func(t *testing.T) {
+		var err error
+		poster1, err = c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "poster",
+			FileName: "poster1.png",
+			Content:  testImage(t, 600, 900),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, starWars.ID, *poster1.MovieID)
+		require.Equal(t, "image/png", poster1.ContentType)
+		require.Equal(t, 600, poster1.Width)
+		require.True(t, poster1.Primary, "the first image must become the primary one")
+
+		require.Equal(t, []string{"original", "medium", "thumb"}, variantNames(poster1))
+		thumb := poster1.Variants[2]
+		require.Equal(t, 185, thumb.Width)
+		require.Equal(t, 277, thumb.Height)
+		require.Equal(t, fmt.Sprintf("/api/images/%d/thumb", poster1.ID), thumb.URL)
+
+		poster2, err = c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "poster",
+			Primary:  true,
+			FileName: "poster2.png",
+			Content:  testImage(t, 300, 450),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.True(t, poster2.Primary)
+		require.Equal(t, []string{"original", "thumb"}, variantNames(poster2))
+	})
+
+	t.Run("images.UploadMovieImage: unsupported type", func(t *testing.T) {
+		_, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "poster",
+			FileName: "poster.txt",
+			Content:  []byte("definitely not an image"),
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "unsupported image type text/plain")
+	})
+
+	t.Run("images.UploadMovieImage: too large", func(t *testing.T) {
+		_, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "backdrop",
+			FileName: "backdrop.png",
+			Content:  make([]byte, cfg.Images.MaxSize+1),
+		}, johnDoeToken))
+		requireBadRequestError(t, err, fmt.Sprintf("image must not be larger than %d bytes", cfg.Images.MaxSize))
+	})
+
+	t.Run("images.UploadMovieImage: unknown kind", func(t *testing.T) {
+		_, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "photo",
+			FileName: "photo.png",
+			Content:  testImage(t, 100, 100),
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "Kind")
+	})
+
+	t.Run("images.UploadMovieImage: movie not found", func(t *testing.T) {
+		_, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       fakeID,
+			Kind:     "poster",
+			FileName: "poster.png",
+			Content:  testImage(t, 100, 100),
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "movie", "id", fakeID)
+	})
+
+	t.Run("images.UploadMovieImage: unauthorized", func(t *testing.T) {
+		_, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       starWars.ID,
+			Kind:     "poster",
+			FileName: "poster.png",
+			Content:  testImage(t, 100, 100),
+		}, ""))
+		requireUnauthorizedError(t, err, "invalid or missing token")
+	})
+
+	t.Run("images.GetMovieImages: primary goes first", func(t *testing.T) {
+		images, err := c.GetMovieImages(starWars.ID)
+		require.NoError(t, err)
+		require.Len(t, images, 2)
+		require.Equal(t, poster2.ID, images[0].ID)
+		require.Equal(t, poster1.ID, images[1].ID)
+		require.False(t, images[1].Primary)
+	})
+
+	t.Run("movies.GetMovieByID: primary image", func(t *testing.T) {
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.Equal(t, poster2, movie.Image)
+	})
+
+	t.Run("images.GetImageContent: success", func(t *testing.T) {
+		content, err := c.GetImageContent(poster1.ID, "medium")
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(content))
+		require.NoError(t, err)
+		require.Equal(t, 500, img.Bounds().Dx())
+		require.Equal(t, 750, img.Bounds().Dy())
+	})
+
+	t.Run("images.GetImageContent: unknown variant", func(t *testing.T) {
+		_, err := c.GetImageContent(poster2.ID, "medium")
+		requireNotFoundError(t, err, "image variant", "name", "medium")
+	})
+
+	t.Run("images.UploadStarImage: success", func(t *testing.T) {
+		var err error
+		photo, err = c.UploadStarImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       hamill.ID,
+			FileName: "hamill.png",
+			Content:  testImage(t, 200, 300),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, "photo", photo.Kind)
+		require.Equal(t, hamill.ID, *photo.StarID)
+
+		star, err := c.GetStarByID(hamill.ID)
+		require.NoError(t, err)
+		require.Equal(t, photo, star.Image)
+	})
+
+	t.Run("images.SetPrimaryImage: success", func(t *testing.T) {
+		err := c.SetPrimaryImage(contracts.NewAuthenticated(&contracts.SetPrimaryImageRequest{ID: poster1.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		images, err := c.GetMovieImages(starWars.ID)
+		require.NoError(t, err)
+		require.Equal(t, poster1.ID, images[0].ID)
+		require.True(t, images[0].Primary)
+		require.False(t, images[1].Primary)
+	})
+
+	t.Run("images.DeleteImage: primary is replaced", func(t *testing.T) {
+		err := c.DeleteImage(contracts.NewAuthenticated(&contracts.DeleteImageRequest{ID: poster1.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		_, err = c.GetImage(poster1.ID)
+		requireNotFoundError(t, err, "image", "id", poster1.ID)
+
+		image, err := c.GetImage(poster2.ID)
+		require.NoError(t, err)
+		require.True(t, image.Primary)
+	})
+
+	t.Run("images.DeleteImage: not found", func(t *testing.T) {
+		err := c.DeleteImage(contracts.NewAuthenticated(&contracts.DeleteImageRequest{ID: poster1.ID}, johnDoeToken))
+		requireNotFoundError(t, err, "image", "id", poster1.ID)
+	})
+}
+
+// testImage encodes a gradient of the given size as PNG.
+func testImage(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func variantNames(image *contracts.Image) []string {
+	var names []string
+	for _, v := range image.Variants {
+		names = append(names, v.Name)
+	}
+	return names
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// runJob enqueues a job of the kind and waits for it to succeed.
+func runJob(t *testing.T, c *client.Client, kind string) *contracts.Job {
+	job, err := c.EnqueueJob(contracts.NewAuthenticated(&contracts.EnqueueJobRequest{Kind: kind}, adminToken))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		job, err = c.GetJob(contracts.NewAuthenticated(&contracts.GetJobRequest{ID: job.ID}, adminToken))
+		require.NoError(t, err)
+		return job.Status == "succeeded"
+	}, 10*time.Second, 100*time.Millisecond)
+	return job
+}
+
+func jobsAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
+	var purge *contracts.Job
+
+	t.Run("jobs.GetJobs: insufficient permissions", func(t *testing.T) {
+		_, err := c.GetJobs(contracts.NewAuthenticated(&contracts.GetJobsRequest{}, johnDoeToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("jobs.EnqueueJob: unknown kind", func(t *testing.T) {
+		_, err := c.EnqueueJob(contracts.NewAuthenticated(&contracts.EnqueueJobRequest{Kind: "unknown"}, adminToken))
+		requireBadRequestError(t, err, "unknown job kind")
+	})
+
+	t.Run("jobs.EnqueueJob: success", func(t *testing.T) {
+		job, err := c.EnqueueJob(contracts.NewAuthenticated(&contracts.EnqueueJobRequest{Kind: "trash.purge"}, adminToken))
+		require.NoError(t, err)
+		require.Equal(t, "trash.purge", job.Kind)
+		require.Equal(t, "pending", job.Status)
+		require.Equal(t, 3, job.MaxAttempts)
+
+		require.Eventually(t, func() bool {
+			purge, err = c.GetJob(contracts.NewAuthenticated(&contracts.GetJobRequest{ID: job.ID}, adminToken))
+			require.NoError(t, err)
+			return purge.Status == "succeeded"
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, 1, purge.Attempts)
+		require.NotNil(t, purge.FinishedAt)
+	})
+
+	t.Run("jobs.GetJobs: filtered by status and kind", func(t *testing.T) {
+		status, kind := "succeeded", "trash.purge"
+		res, err := c.GetJobs(contracts.NewAuthenticated(&contracts.GetJobsRequest{Status: &status, Kind: &kind}, adminToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Items)
+		require.Equal(t, purge.ID, res.Items[0].ID)
+		for _, job := range res.Items {
+			require.Equal(t, status, job.Status)
+			require.Equal(t, kind, job.Kind)
+		}
+	})
+
+	t.Run("jobs.RetryJob: not failed", func(t *testing.T) {
+		_, err := c.RetryJob(contracts.NewAuthenticated(&contracts.RetryJobRequest{ID: purge.ID}, adminToken))
+		requireBadRequestError(t, err, "only failed jobs can be retried")
+	})
+
+	t.Run("jobs.RetryJob: success", func(t *testing.T) {
+		ctx := context.Background()
+		conn, err := pgx.Connect(ctx, cfg.DbURL)
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		_, err = conn.Exec(ctx, "UPDATE jobs SET status = 'failed' WHERE id = $1", purge.ID)
+		require.NoError(t, err)
+
+		job, err := c.RetryJob(contracts.NewAuthenticated(&contracts.RetryJobRequest{ID: purge.ID}, adminToken))
+		require.NoError(t, err)
+		require.Equal(t, "pending", job.Status)
+		require.Equal(t, 0, job.Attempts)
+
+		require.Eventually(t, func() bool {
+			job, err = c.GetJob(contracts.NewAuthenticated(&contracts.GetJobRequest{ID: purge.ID}, adminToken))
+			require.NoError(t, err)
+			return job.Status == "succeeded"
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, 1, job.Attempts)
+
+		// the claims of the first run still count, a worker that lost the first lease can't finish the retry
+		var claims int
+		require.NoError(t, conn.QueryRow(ctx, "SELECT claims FROM jobs WHERE id = $1", purge.ID).Scan(&claims))
+		require.Equal(t, 2, claims)
+	})
+
+	t.Run("jobs.RetryJob: not found", func(t *testing.T) {
+		_, err := c.RetryJob(contracts.NewAuthenticated(&contracts.RetryJobRequest{ID: fakeID}, adminToken))
+		requireNotFoundError(t, err, "job", "id", fakeID)
+	})
+}
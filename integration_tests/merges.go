@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+func mergesAPIChecks(t *testing.T, c *client.Client) {
+	birthDate := time.Date(1942, time.July, 13, 0, 0, 0, 0, time.UTC)
+	ford, err := c.CreateStar(contracts.NewAuthenticated(&contracts.CreateStarRequest{
+		FirstName: "Harrison",
+		LastName:  "Ford",
+		BirthDate: birthDate,
+	}, johnDoeToken))
+	require.NoError(t, err)
+	fordDuplicate, err := c.CreateStar(contracts.NewAuthenticated(&contracts.CreateStarRequest{
+		FirstName: "Harisson",
+		LastName:  "Ford",
+		BirthDate: birthDate,
+		Bio:       contracts.Ptr("Han Solo and Indiana Jones"),
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	releaseDate := time.Date(1982, time.June, 25, 0, 0, 0, 0, time.UTC)
+	bladeRunner, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Blade Runner",
+		ReleaseDate: releaseDate,
+		Genres:      []int{Action.ID},
+		Cast: []*contracts.MovieCreditInfo{
+			{StarID: ford.ID, Role: "actor", Characters: []string{"Rick Deckard"}},
+		},
+	}, johnDoeToken))
+	require.NoError(t, err)
+	bladeRunnerDuplicate, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Blade Runner.",
+		ReleaseDate: releaseDate,
+		Genres:      []int{Drama.ID, Action.ID},
+		Cast: []*contracts.MovieCreditInfo{
+			{StarID: fordDuplicate.ID, Role: "actor", Characters: []string{"Rick Deckard"}},
+		},
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	reviewer := registerRandomUser(t, c)
+	reviewerToken := login(t, c, reviewer.Email, standardPassword)
+	for _, review := range []struct {
+		movieID, rating int
+	}{
+		{bladeRunner.ID, 6},
+		{bladeRunnerDuplicate.ID, 8},
+	} {
+		_, err = c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+			MovieID: review.movieID,
+			UserID:  reviewer.ID,
+			Rating:  review.rating,
+			Title:   "Tears in rain",
+			Content: "All those moments will be lost in time, like tears in rain.",
+		}, reviewerToken))
+		require.NoError(t, err)
+	}
+
+	t.Run("stars.GetStarDuplicates: success", func(t *testing.T) {
+		duplicates, err := c.GetStarDuplicates(contracts.NewAuthenticated(&contracts.GetDuplicatesRequest{}, johnDoeToken))
+		require.NoError(t, err)
+		require.Condition(t, func() bool {
+			for _, d := range duplicates {
+				if d.Star.ID == ford.ID && d.Duplicate.ID == fordDuplicate.ID {
+					return true
+				}
+			}
+			return false
+		})
+	})
+
+	t.Run("stars.GetStarDuplicates: insufficient permissions", func(t *testing.T) {
+		_, err := c.GetStarDuplicates(contracts.NewAuthenticated(&contracts.GetDuplicatesRequest{}, reviewerToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("movies.GetMovieDuplicates: success", func(t *testing.T) {
+		duplicates, err := c.GetMovieDuplicates(contracts.NewAuthenticated(&contracts.GetDuplicatesRequest{}, johnDoeToken))
+		require.NoError(t, err)
+		require.Condition(t, func() bool {
+			for _, d := range duplicates {
+				if d.Movie.ID == bladeRunner.ID && d.Duplicate.ID == bladeRunnerDuplicate.ID {
+					return true
+				}
+			}
+			return false
+		})
+	})
+
+	t.Run("stars.MergeStar: success", func(t *testing.T) {
+		star, err := c.MergeStar(contracts.NewAuthenticated(&contracts.MergeStarRequest{
+			ID:       fordDuplicate.ID,
+			TargetID: ford.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, ford.ID, star.ID)
+		require.Equal(t, fordDuplicate.Bio, star.Bio)
+
+		filmography, err := c.GetStarFilmography(ford.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, filmography.Total)
+	})
+
+	t.Run("stars.GetStarByID: redirect from merged star", func(t *testing.T) {
+		star, err := c.GetStarByID(fordDuplicate.ID)
+		require.NoError(t, err)
+		require.Equal(t, ford.ID, star.ID)
+	})
+
+	t.Run("stars.MergeStar: merged star", func(t *testing.T) {
+		_, err := c.MergeStar(contracts.NewAuthenticated(&contracts.MergeStarRequest{
+			ID:       fordDuplicate.ID,
+			TargetID: ford.ID,
+		}, johnDoeToken))
+		requireNotFoundError(t, err, "star", "id", fordDuplicate.ID)
+	})
+
+	t.Run("movies.MergeMovie: success", func(t *testing.T) {
+		movie, err := c.MergeMovie(contracts.NewAuthenticated(&contracts.MergeMovieRequest{
+			ID:       bladeRunnerDuplicate.ID,
+			TargetID: bladeRunner.ID,
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, bladeRunner.ID, movie.ID)
+		require.Equal(t, []int{Action.ID, Drama.ID}, genreIDs(movie.Genres))
+		require.Len(t, movie.Cast, 1)
+		require.Equal(t, ford.ID, movie.Cast[0].Star.ID)
+		// the latest review of the reviewer is kept
+		requireRatingEqual(t, 8, *movie.AvgRating)
+	})
+
+	t.Run("movies.GetMovieByID: redirect from merged movie", func(t *testing.T) {
+		movie, err := c.GetMovieByID(bladeRunnerDuplicate.ID)
+		require.NoError(t, err)
+		require.Equal(t, bladeRunner.ID, movie.ID)
+	})
+
+	t.Run("movies.MergeMovie: into itself", func(t *testing.T) {
+		_, err := c.MergeMovie(contracts.NewAuthenticated(&contracts.MergeMovieRequest{
+			ID:       bladeRunner.ID,
+			TargetID: bladeRunner.ID,
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "movie can't be merged into itself")
+	})
+
+	err = c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: bladeRunner.ID}, johnDoeToken))
+	require.NoError(t, err)
+}
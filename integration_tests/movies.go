@@ -28,6 +28,14 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 					 the ruthless Empire agent Darth Vader. Before she is captured, Leia hides the plans in the memory 
 					 system of astromech droid R2-D2, who flees in an escape pod to the nearby desert planet Tatooine 
 					 alongside his companion, protocol droid C-3PO.`,
+					MovieMetadata: contracts.MovieMetadata{
+						Tagline:         contracts.Ptr("A long time ago in a galaxy far, far away..."),
+						Runtime:         contracts.Ptr(121),
+						Countries:       []string{"us"},
+						SpokenLanguages: []string{"EN"},
+						Certifications:  []*contracts.Certification{{Country: "us", Certification: "PG"}},
+						ExternalIDs:     map[string]string{"imdb": "tt0076759"},
+					},
 					Genres: []int{Action.ID, Drama.ID},
 					Cast: []*contracts.MovieCreditInfo{
 						{
@@ -35,9 +43,9 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 							Role:   "director",
 						},
 						{
-							StarID:  hamill.ID,
-							Role:    "actor",
-							Details: contracts.Ptr("char1, char2"),
+							StarID:     hamill.ID,
+							Role:       "actor",
+							Characters: []string{"char1", "char2"},
 						},
 					},
 				},
@@ -53,9 +61,9 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 					Genres: []int{Action.ID},
 					Cast: []*contracts.MovieCreditInfo{
 						{
-							StarID:  hamill.ID,
-							Role:    "actor",
-							Details: contracts.Ptr("char3"),
+							StarID:     hamill.ID,
+							Role:       "actor",
+							Characters: []string{"char3"},
 						},
 					},
 				},
@@ -74,9 +82,9 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 					Genres: []int{Drama.ID},
 					Cast: []*contracts.MovieCreditInfo{
 						{
-							StarID:  mcgregor.ID,
-							Role:    "actor",
-							Details: contracts.Ptr("char4"),
+							StarID:     mcgregor.ID,
+							Role:       "actor",
+							Characters: []string{"char4"},
 						},
 					},
 				},
@@ -92,6 +100,7 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 			*cc.addr = movie
 			require.NotEmpty(t, movie.ID)
 			require.NotEmpty(t, movie.CreatedAt)
+			require.Equal(t, "en", movie.Language)
 			require.NotEmpty(t, movie.Genres)
 			require.Equal(t, len(cc.req.Genres), len(movie.Genres))
 			require.NotEmpty(t, movie.Cast)
@@ -109,6 +118,17 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 		requireUnauthorizedError(t, err, "invalid or missing token")
 	})
 
+	t.Run("movies.Create: unsupported language", func(t *testing.T) {
+		req := &contracts.CreateMovieRequest{
+			Title:       "Le Fabuleux Destin d'Amélie Poulain",
+			ReleaseDate: time.Date(2001, time.April, 25, 0, 0, 0, 0, time.UTC),
+			Language:    "xx",
+		}
+
+		_, err := c.CreateMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireBadRequestError(t, err, `unsupported language "xx"`)
+	})
+
 	t.Run("movies.GetMovieByID: success", func(t *testing.T) {
 		movie, err := c.GetMovieByID(starWars.ID)
 		require.NoError(t, err)
@@ -123,6 +143,43 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 		}
 	})
 
+	t.Run("movies.GetMovieByID: metadata", func(t *testing.T) {
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		require.Equal(t, "A long time ago in a galaxy far, far away...", *movie.Tagline)
+		require.Equal(t, 121, *movie.Runtime)
+		require.Equal(t, []string{"US"}, movie.Countries)
+		require.Equal(t, []string{"en"}, movie.SpokenLanguages)
+		require.Equal(t, []*contracts.Certification{{Country: "US", Certification: "PG"}}, movie.Certifications)
+		require.Equal(t, map[string]string{"imdb": "tt0076759"}, movie.ExternalIDs)
+	})
+
+	t.Run("movies.Create: invalid country code", func(t *testing.T) {
+		req := &contracts.CreateMovieRequest{
+			Title:       "The Empire Strikes Back",
+			ReleaseDate: time.Date(1980, time.May, 21, 0, 0, 0, 0, time.UTC),
+			MovieMetadata: contracts.MovieMetadata{
+				Countries: []string{"USA"},
+			},
+		}
+
+		_, err := c.CreateMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireBadRequestError(t, err, `invalid code "USA"`)
+	})
+
+	t.Run("movies.Create: external id already exists", func(t *testing.T) {
+		req := &contracts.CreateMovieRequest{
+			Title:       "Star Wars: Episode IV - A New Hope",
+			ReleaseDate: time.Date(1977, time.May, 25, 0, 0, 0, 0, time.UTC),
+			MovieMetadata: contracts.MovieMetadata{
+				ExternalIDs: map[string]string{"imdb": "tt0076759"},
+			},
+		}
+
+		_, err := c.CreateMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireAlreadyExistsError(t, err, "movie", "imdb", "tt0076759")
+	})
+
 	t.Run("movies.GetMovieByID: not found", func(t *testing.T) {
 		_, err := c.GetMovieByID(fakeID)
 		requireNotFoundError(t, err, "movie", "id", fakeID)
@@ -148,6 +205,137 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, []*contracts.Movie{&trainspotting.Movie}, res.Items)
 	})
 
+	t.Run("movies.GetAll: paginate with cursors", func(t *testing.T) {
+		movies, err := client.Paginate(&contracts.GetMoviesRequest{}, c.GetMovies)
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Movie{&starWars.Movie, &kingsMan.Movie, &trainspotting.Movie}, movies)
+	})
+
+	t.Run("movies.GetAll: include genres and cast", func(t *testing.T) {
+		req := &contracts.GetMoviesRequest{
+			Include: contracts.Ptr("genres,cast"),
+		}
+		res, err := c.GetMovies(req)
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		require.Equal(t, starWars.Genres, res.Items[0].Genres)
+		require.Equal(t, starWars.Cast, res.Items[0].Cast)
+		require.Equal(t, kingsMan.Genres, res.Items[1].Genres)
+		require.Equal(t, kingsMan.Cast, res.Items[1].Cast)
+	})
+
+	t.Run("movies.GetAll: sparse fields", func(t *testing.T) {
+		req := &contracts.GetMoviesRequest{
+			Fields: contracts.Ptr("title"),
+		}
+		res, err := c.GetMovies(req)
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Movie{
+			{ID: starWars.ID, Title: starWars.Title},
+			{ID: kingsMan.ID, Title: kingsMan.Title},
+		}, res.Items)
+	})
+
+	t.Run("movies.GetMovieByID: without relations", func(t *testing.T) {
+		req := &contracts.GetMovieByIDRequest{
+			ID:      starWars.ID,
+			Include: contracts.Ptr(""),
+			Fields:  contracts.Ptr("title,description"),
+		}
+		movie, err := c.GetMovieWithRelations(req)
+		require.NoError(t, err)
+		require.Equal(t, starWars.Title, movie.Title)
+		require.Equal(t, starWars.Description, movie.Description)
+		require.Empty(t, movie.Genres)
+		require.Empty(t, movie.Cast)
+		require.True(t, movie.ReleaseDate.IsZero())
+	})
+
+	t.Run("movies.GetMovieByID: unknown include", func(t *testing.T) {
+		req := &contracts.GetMovieByIDRequest{
+			ID:      starWars.ID,
+			Include: contracts.Ptr("awards"),
+		}
+		_, err := c.GetMovieWithRelations(req)
+		requireBadRequestError(t, err, "include must be a list of genres, cast, reviews")
+	})
+
+	t.Run("stars.GetStarByID: include movies", func(t *testing.T) {
+		req := &contracts.GetStarByIDRequest{
+			ID:      hamill.ID,
+			Include: contracts.Ptr("movies"),
+		}
+		star, err := c.GetStarWithRelations(req)
+		require.NoError(t, err)
+		require.Len(t, star.Movies, 2)
+		require.Equal(t, starWars.ID, star.Movies[0].Movie.ID)
+		require.Equal(t, "actor", star.Movies[0].Role)
+		require.Equal(t, kingsMan.ID, star.Movies[1].Movie.ID)
+	})
+
+	t.Run("stars.CreateCreditRole: success", func(t *testing.T) {
+		req := &contracts.CreateCreditRoleRequest{
+			Name:       "cinematographer",
+			Department: "Camera",
+		}
+		role, err := c.CreateCreditRole(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, &contracts.CreditRole{Name: req.Name, Department: req.Department}, role)
+
+		roles, err := c.GetCreditRoles()
+		require.NoError(t, err)
+		require.Contains(t, roles, role)
+		require.Contains(t, roles, &contracts.CreditRole{Name: "actor", Department: "Acting", IsCast: true})
+	})
+
+	t.Run("stars.CreateCreditRole: already exists", func(t *testing.T) {
+		req := &contracts.CreateCreditRoleRequest{
+			Name:       "actor",
+			Department: "Acting",
+		}
+		_, err := c.CreateCreditRole(contracts.NewAuthenticated(req, johnDoeToken))
+		requireAlreadyExistsError(t, err, "credit role", "name", req.Name)
+	})
+
+	t.Run("movies.CreateMovie: unknown credit role", func(t *testing.T) {
+		req := &contracts.CreateMovieRequest{
+			Title:       "Unknown role",
+			ReleaseDate: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Description: "Movie with a credit role that doesn't exist",
+			Cast: []*contracts.MovieCreditInfo{
+				{
+					StarID: lucas.ID,
+					Role:   "stuntman",
+				},
+			},
+		}
+		_, err := c.CreateMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireBadRequestError(t, err, `unknown credit role "stuntman"`)
+	})
+
+	t.Run("stars.GetStarFilmography: success", func(t *testing.T) {
+		filmography, err := c.GetStarFilmography(hamill.ID)
+		require.NoError(t, err)
+		require.Equal(t, hamill.ID, filmography.StarID)
+		require.Equal(t, 2, filmography.Total)
+		require.Len(t, filmography.Roles, 1)
+
+		actor := filmography.Roles[0]
+		require.Equal(t, "actor", actor.Role)
+		require.Equal(t, "Acting", actor.Department)
+		require.Equal(t, 2, actor.Count)
+		require.Equal(t, kingsMan.ID, actor.Credits[0].Movie.ID)
+		require.Equal(t, []string{"char3"}, actor.Credits[0].Characters)
+		require.Equal(t, starWars.ID, actor.Credits[1].Movie.ID)
+		require.Equal(t, []string{"char1", "char2"}, actor.Credits[1].Characters)
+		require.Equal(t, 1, actor.Credits[1].OrderNo)
+	})
+
+	t.Run("stars.GetStarFilmography: not found", func(t *testing.T) {
+		_, err := c.GetStarFilmography(fakeID)
+		requireNotFoundError(t, err, "star", "id", fakeID)
+	})
+
 	t.Run("movies.GetAll: by star ID", func(t *testing.T) {
 		req := contracts.GetMoviesRequest{
 			StarID: contracts.Ptr(hamill.ID),
@@ -185,6 +373,144 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, []*contracts.Movie{&kingsMan.Movie}, res.Items)
 	})
 
+	t.Run("movies.GetAll: about Eggsy with language hint", func(t *testing.T) {
+		req := &contracts.GetMoviesRequest{
+			SearchTerm: contracts.Ptr("Eggsy"),
+			Language:   contracts.Ptr("en"),
+		}
+		res, err := c.GetMovies(req)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Total)
+		require.Equal(t, []*contracts.Movie{&kingsMan.Movie}, res.Items)
+	})
+
+	t.Run("movies.GetMovieSuggestions: typo", func(t *testing.T) {
+		req := &contracts.GetMovieSuggestionsRequest{
+			Query: "Trainspoting",
+		}
+		res, err := c.GetMovieSuggestions(req)
+		require.NoError(t, err)
+		require.NotEmpty(t, res)
+		require.Equal(t, trainspotting.ID, res[0].ID)
+		require.Equal(t, trainspotting.Title, res[0].Title)
+		require.Greater(t, res[0].Similarity, 0.5)
+	})
+
+	t.Run("movies.GetMovieSuggestions: no matches", func(t *testing.T) {
+		req := &contracts.GetMovieSuggestionsRequest{
+			Query: "Qwxz",
+		}
+		res, err := c.GetMovieSuggestions(req)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		require.Empty(t, res)
+	})
+
+	t.Run("movies.PutMovieTranslation: success", func(t *testing.T) {
+		req := &contracts.PutMovieTranslationRequest{
+			ID:          starWars.ID,
+			Locale:      "de",
+			Title:       contracts.Ptr("Krieg der Sterne"),
+			Description: contracts.Ptr("Es war einmal vor langer Zeit in einer weit, weit entfernten Galaxis."),
+		}
+		translation, err := c.PutMovieTranslation(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, "de", translation.Locale)
+		require.Equal(t, req.Title, translation.Title)
+
+		translations, err := c.GetMovieTranslations(starWars.ID)
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.MovieTranslation{translation}, translations)
+	})
+
+	t.Run("movies.PutMovieTranslation: empty", func(t *testing.T) {
+		req := &contracts.PutMovieTranslationRequest{
+			ID:     starWars.ID,
+			Locale: "fr",
+		}
+		_, err := c.PutMovieTranslation(contracts.NewAuthenticated(req, johnDoeToken))
+		requireBadRequestError(t, err, "translation must contain a title or a description")
+	})
+
+	t.Run("movies.PutMovieTranslation: unauthorized", func(t *testing.T) {
+		req := &contracts.PutMovieTranslationRequest{
+			ID:     starWars.ID,
+			Locale: "fr",
+			Title:  contracts.Ptr("La Guerre des étoiles"),
+		}
+		_, err := c.PutMovieTranslation(contracts.NewAuthenticated(req, ""))
+		requireUnauthorizedError(t, err, "invalid or missing token")
+	})
+
+	t.Run("movies.PutMovieTranslation: not found", func(t *testing.T) {
+		req := &contracts.PutMovieTranslationRequest{
+			ID:     fakeID,
+			Locale: "fr",
+			Title:  contracts.Ptr("La Guerre des étoiles"),
+		}
+		_, err := c.PutMovieTranslation(contracts.NewAuthenticated(req, johnDoeToken))
+		requireNotFoundError(t, err, "movie", "id", fakeID)
+	})
+
+	t.Run("movies.GetMovieByID: localized", func(t *testing.T) {
+		movie, err := c.GetLocalizedMovieByID(starWars.ID, "de-AT,en;q=0.5")
+		require.NoError(t, err)
+		require.Equal(t, "Krieg der Sterne", movie.Title)
+		require.Equal(t, contracts.Ptr(starWars.Title), movie.Original)
+		require.Equal(t, contracts.Ptr("de"), movie.Locale)
+		require.Equal(t, "Es war einmal vor langer Zeit in einer weit, weit entfernten Galaxis.", movie.Description)
+	})
+
+	t.Run("movies.GetMovieByID: localized fallback to original", func(t *testing.T) {
+		movie, err := c.GetLocalizedMovieByID(starWars.ID, "fr-FR,fr;q=0.9")
+		require.NoError(t, err)
+		require.Equal(t, starWars.Title, movie.Title)
+		require.Nil(t, movie.Original)
+		require.Nil(t, movie.Locale)
+		require.Equal(t, starWars.Description, movie.Description)
+	})
+
+	t.Run("movies.GetMovieByID: original language preferred", func(t *testing.T) {
+		movie, err := c.GetLocalizedMovieByID(starWars.ID, "en,de;q=0.5")
+		require.NoError(t, err)
+		require.Equal(t, starWars.Title, movie.Title)
+		require.Nil(t, movie.Original)
+		require.Nil(t, movie.Locale)
+		require.Equal(t, starWars.Description, movie.Description)
+	})
+
+	t.Run("movies.GetAll: localized", func(t *testing.T) {
+		req := &contracts.GetMoviesRequest{}
+		res, err := c.GetLocalizedMovies(req, "de")
+		require.NoError(t, err)
+		require.Equal(t, "Krieg der Sterne", res.Items[0].Title)
+		require.Equal(t, contracts.Ptr(starWars.Title), res.Items[0].Original)
+		require.Equal(t, kingsMan.Title, res.Items[1].Title)
+		require.Nil(t, res.Items[1].Locale)
+	})
+
+	t.Run("movies.DeleteMovieTranslation: success", func(t *testing.T) {
+		req := &contracts.DeleteMovieTranslationRequest{
+			ID:     starWars.ID,
+			Locale: "de",
+		}
+		err := c.DeleteMovieTranslation(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		translations, err := c.GetMovieTranslations(starWars.ID)
+		require.NoError(t, err)
+		require.Empty(t, translations)
+	})
+
+	t.Run("movies.DeleteMovieTranslation: not found", func(t *testing.T) {
+		req := &contracts.DeleteMovieTranslationRequest{
+			ID:     starWars.ID,
+			Locale: "de",
+		}
+		err := c.DeleteMovieTranslation(contracts.NewAuthenticated(req, johnDoeToken))
+		requireNotFoundError(t, err, "movie translation", "locale", "de")
+	})
+
 	t.Run("movies.Update: the same genre success", func(t *testing.T) {
 		req := &contracts.UpdateMovieRequest{
 			ID:          trainspotting.ID,
@@ -291,6 +617,62 @@ func moviesAPIChecks(t *testing.T, c *client.Client) {
 		requireVersionMismatch(t, err, "movie", "id", req.ID, req.Version)
 	})
 
+	t.Run("movies.PatchMovie: success", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID: trainspotting.ID,
+			Patch: map[string]any{
+				"description": "patched description",
+				"tagline":     "Choose life",
+			},
+		}
+		err := c.PatchMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		res, err := c.GetMovieByID(trainspotting.ID)
+		require.NoError(t, err)
+		require.Equal(t, "patched description", res.Description)
+		require.Equal(t, "Choose life", *res.Tagline)
+		require.Equal(t, trainspotting.Title, res.Title)
+		require.Equal(t, 3, res.Version)
+		// Relations which are not in the patch are kept
+		require.Equal(t, []int{Action.ID}, genreIDs(res.Genres))
+		require.Len(t, res.Cast, 1)
+		require.Equal(t, hamill.ID, res.Cast[0].Star.ID)
+	})
+
+	t.Run("movies.PatchMovie: remove a field", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    trainspotting.ID,
+			Patch: map[string]any{"tagline": nil},
+		}
+		err := c.PatchMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		res, err := c.GetMovieByID(trainspotting.ID)
+		require.NoError(t, err)
+		require.Nil(t, res.Tagline)
+		require.Equal(t, "patched description", res.Description)
+	})
+
+	t.Run("movies.PatchMovie: stale version", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:      trainspotting.ID,
+			Version: contracts.Ptr(0),
+			Patch:   map[string]any{"description": "stale description"},
+		}
+		err := c.PatchMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireVersionMismatch(t, err, "movie", "id", req.ID, *req.Version)
+	})
+
+	t.Run("movies.PatchMovie: not found", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    fakeID,
+			Patch: map[string]any{"description": "patched description"},
+		}
+		err := c.PatchMovie(contracts.NewAuthenticated(req, johnDoeToken))
+		requireNotFoundError(t, err, "movie", "id", fakeID)
+	})
+
 	t.Run("movies.Delete: unauthorized", func(t *testing.T) {
 		req := &contracts.DeleteMovieRequest{
 			ID: trainspotting.ID,
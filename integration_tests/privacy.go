@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func privacyAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
+	user := registerRandomUser(t, c)
+	userToken := login(t, c, user.Email, standardPassword)
+	review, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+		MovieID: starWars.ID,
+		UserID:  user.ID,
+		Rating:  7,
+		Title:   "A long time ago",
+		Content: "A fairy tale in space that holds up surprisingly well.",
+	}, userToken))
+	require.NoError(t, err)
+
+	getPrivateUser := func(t *testing.T, token string) *contracts.User {
+		u, err := c.GetPrivateUserByID(contracts.NewAuthenticated(&contracts.GetUserByIDRequest{UserID: user.ID}, token))
+		require.NoError(t, err)
+		return u
+	}
+
+	t.Run("privacy.ExportUserData: success", func(t *testing.T) {
+		export, err := c.ExportUserData(contracts.NewAuthenticated(&contracts.ExportUserDataRequest{UserID: user.ID}, userToken))
+		require.NoError(t, err)
+		require.Equal(t, user.ID, export.Profile.ID)
+		require.Equal(t, user.Email, export.Profile.Email)
+		require.Len(t, export.Reviews, 1)
+		require.Equal(t, review.ID, export.Reviews[0].ID)
+		require.Equal(t, starWars.ID, export.Reviews[0].Movie.ID)
+	})
+
+	t.Run("privacy.ExportUserData: insufficient permissions", func(t *testing.T) {
+		_, err := c.ExportUserData(contracts.NewAuthenticated(&contracts.ExportUserDataRequest{UserID: user.ID}, johnDoeToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("privacy.ScheduleErasure: success", func(t *testing.T) {
+		erasure, err := c.ScheduleErasure(contracts.NewAuthenticated(&contracts.ScheduleErasureRequest{UserID: user.ID}, userToken))
+		require.NoError(t, err)
+		require.Equal(t, user.ID, erasure.UserID)
+		require.True(t, erasure.EraseAt.After(time.Now().Add(-time.Minute)))
+
+		scheduled := getPrivateUser(t, userToken)
+		require.NotNil(t, scheduled.EraseAt)
+		require.Equal(t, erasure.EraseAt, *scheduled.EraseAt)
+
+		// scheduling again keeps the original date
+		again, err := c.ScheduleErasure(contracts.NewAuthenticated(&contracts.ScheduleErasureRequest{UserID: user.ID}, userToken))
+		require.NoError(t, err)
+		require.Equal(t, erasure.EraseAt, again.EraseAt)
+	})
+
+	t.Run("privacy.ScheduleErasure: erase_at hidden from others", func(t *testing.T) {
+		require.Nil(t, getUser(t, c, user.ID).EraseAt)
+		require.Nil(t, getPrivateUser(t, johnDoeToken).EraseAt)
+		require.NotNil(t, getPrivateUser(t, adminToken).EraseAt)
+	})
+
+	t.Run("privacy.CancelErasure: success", func(t *testing.T) {
+		err := c.CancelErasure(contracts.NewAuthenticated(&contracts.CancelErasureRequest{UserID: user.ID}, userToken))
+		require.NoError(t, err)
+		require.Nil(t, getPrivateUser(t, userToken).EraseAt)
+	})
+
+	t.Run("privacy.CancelErasure: not scheduled", func(t *testing.T) {
+		err := c.CancelErasure(contracts.NewAuthenticated(&contracts.CancelErasureRequest{UserID: user.ID}, userToken))
+		requireBadRequestError(t, err, "no erasure is scheduled")
+	})
+
+	t.Run("privacy.Erase: user and reviews removed, rating recalculated", func(t *testing.T) {
+		_, err := c.ScheduleErasure(contracts.NewAuthenticated(&contracts.ScheduleErasureRequest{UserID: user.ID}, userToken))
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		conn, err := pgx.Connect(ctx, cfg.DbURL)
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		_, err = conn.Exec(ctx, "UPDATE users SET erase_at = NOW() - interval '1 minute' WHERE id = $1", user.ID)
+		require.NoError(t, err)
+
+		runJob(t, c, "privacy.erase")
+
+		require.Nil(t, getUser(t, c, user.ID))
+		_, err = c.GetReview(review.ID)
+		requireNotFoundError(t, err, "review", "id", review.ID)
+
+		res, err := c.GetReviews(&contracts.GetReviewsRequest{
+			PaginatedRequest: contracts.PaginatedRequest{Size: 50},
+			MovieID:          &starWars.ID,
+		})
+		require.NoError(t, err)
+		movie, err := c.GetMovieByID(starWars.ID)
+		require.NoError(t, err)
+		if len(res.Items) == 0 {
+			require.Nil(t, movie.AvgRating)
+		} else {
+			var sum int
+			for _, r := range res.Items {
+				sum += r.Rating
+			}
+			require.NotNil(t, movie.AvgRating)
+			require.InDelta(t, float64(sum)/float64(len(res.Items)), *movie.AvgRating, 0.001)
+		}
+
+		// the events of the user are left with the IDs only
+		var payload string
+		err = conn.QueryRow(ctx, "SELECT payload::text FROM outbox_events WHERE type = 'user.registered' and subject_id = $1", user.ID).
+			Scan(&payload)
+		require.NoError(t, err)
+		require.JSONEq(t, fmt.Sprintf(`{"id": %d}`, user.ID), payload)
+	})
+}
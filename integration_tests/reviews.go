@@ -16,6 +16,19 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 	reviewer1Token := login(t, c, reviewer1.Email, standardPassword)
 	reviewer2Token := login(t, c, reviewer2.Email, standardPassword)
 
+	starWarsPath := fmt.Sprintf("/api/movies/%d", starWars.ID)
+	var starWarsETag string
+	t.Run("movies.GetMovieByID: not modified", func(t *testing.T) {
+		var err error
+		starWarsETag, err = c.ETag(starWarsPath)
+		require.NoError(t, err)
+		require.NotEmpty(t, starWarsETag)
+
+		notModified, err := c.NotModifiedETag(starWarsPath, starWarsETag)
+		require.NoError(t, err)
+		require.True(t, notModified)
+	})
+
 	var review1, review2, review3 *contracts.Review
 	t.Run("reviews.CreateReview: success", func(t *testing.T) {
 		cases := []struct {
@@ -70,6 +83,12 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 		}
 	})
 
+	t.Run("movies.GetMovieByID: modified by reviews", func(t *testing.T) {
+		notModified, err := c.NotModifiedETag(starWarsPath, starWarsETag)
+		require.NoError(t, err)
+		require.False(t, notModified)
+	})
+
 	t.Run("reviews.CreateReview: already exists", func(t *testing.T) {
 		req := &contracts.CreateReviewRequest{
 			MovieID: review1.MovieID,
@@ -125,6 +144,49 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 		}
 	})
 
+	t.Run("reviews.GetReviews: include movie and user", func(t *testing.T) {
+		res, err := c.GetReviews(&contracts.GetReviewsRequest{
+			MovieID: contracts.Ptr(starWars.ID),
+			Include: contracts.Ptr("movie,user"),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 2)
+		for _, review := range res.Items {
+			require.Equal(t, starWars.ID, review.Movie.ID)
+			require.Equal(t, starWars.Title, review.Movie.Title)
+		}
+		require.Equal(t, &contracts.Author{ID: reviewer1.ID, Username: reviewer1.Username}, res.Items[0].User)
+		require.Equal(t, &contracts.Author{ID: reviewer2.ID, Username: reviewer2.Username}, res.Items[1].User)
+	})
+
+	t.Run("movies.GetMovieByID: include reviews", func(t *testing.T) {
+		movie, err := c.GetMovieWithRelations(&contracts.GetMovieByIDRequest{
+			ID:      starWars.ID,
+			Include: contracts.Ptr("reviews"),
+		})
+		require.NoError(t, err)
+		require.Len(t, movie.Reviews, 2)
+		require.Equal(t, review1.ID, movie.Reviews[0].ID)
+		require.Equal(t, review2.ID, movie.Reviews[1].ID)
+	})
+
+	t.Run("reviews.GetReviews: sorted by rating", func(t *testing.T) {
+		res, err := c.GetReviews(&contracts.GetReviewsRequest{
+			UserID: contracts.Ptr(reviewer1.ID),
+			Sort:   contracts.Ptr("rating"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Review{review3, review1}, res.Items)
+	})
+
+	t.Run("reviews.GetReviews: unknown sort field", func(t *testing.T) {
+		_, err := c.GetReviews(&contracts.GetReviewsRequest{
+			UserID: contracts.Ptr(reviewer1.ID),
+			Sort:   contracts.Ptr("-title"),
+		})
+		requireBadRequestError(t, err, "sort field must be one of created_at, rating")
+	})
+
 	t.Run("reviews.GetReviews: no movieID or userID specified", func(t *testing.T) {
 		_, err := c.GetReviews(&contracts.GetReviewsRequest{})
 		requireBadRequestError(t, err, "either movie_id or user_id must be provided")
@@ -158,6 +220,24 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 		}
 	})
 
+	t.Run("movies.GetMovies: sortByRating is an alias of sort", func(t *testing.T) {
+		aliased, err := c.GetMovies(&contracts.GetMoviesRequest{
+			SortByRating: contracts.Ptr("desc"),
+		})
+		require.NoError(t, err)
+
+		sorted, err := c.GetMovies(&contracts.GetMoviesRequest{
+			Sort: contracts.Ptr("-rating"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, sorted.Items, aliased.Items)
+
+		_, err = c.GetMovies(&contracts.GetMoviesRequest{
+			SortByRating: contracts.Ptr("rating"),
+		})
+		requireBadRequestError(t, err, "SortByRating")
+	})
+
 	t.Run("reviews.UpdateReview: success", func(t *testing.T) {
 		req := &contracts.UpdateReviewRequest{
 			ReviewID: review3.ID,
@@ -176,6 +256,26 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, req.Content, review3.Content)
 	})
 
+	t.Run("reviews.UpdateReview: stale version", func(t *testing.T) {
+		req := &contracts.UpdateReviewRequest{
+			ReviewID: review3.ID,
+			UserID:   review3.UserID,
+			Rating:   review3.Rating,
+			Title:    review3.Title,
+			Content:  review3.Content,
+			Version:  contracts.Ptr(review3.Version - 1),
+		}
+		err := c.UpdateReview(contracts.NewAuthenticated(req, reviewer1Token))
+		requireVersionMismatch(t, err, "review", "id", review3.ID, *req.Version)
+
+		req.Version = contracts.Ptr(review3.Version)
+		err = c.UpdateReview(contracts.NewAuthenticated(req, reviewer1Token))
+		require.NoError(t, err)
+
+		review3 = getReview(t, c, review3.ID)
+		require.Equal(t, *req.Version+1, review3.Version)
+	})
+
 	t.Run("reviews.DeleteReview: not found", func(t *testing.T) {
 		nonExistingID := 10000
 		req := &contracts.DeleteReviewRequest{
@@ -215,6 +315,35 @@ func reviewsAPIChecks(t *testing.T, c *client.Client) {
 		review3 = getReview(t, c, review3.ID)
 		require.Nil(t, review3)
 	})
+
+	t.Run("reviews.GetReview: included movie modified", func(t *testing.T) {
+		path := fmt.Sprintf("/api/reviews/%d?include=movie", review1.ID)
+		tag, err := c.ETag(path)
+		require.NoError(t, err)
+
+		notModified, err := c.NotModifiedETag(path, tag)
+		require.NoError(t, err)
+		require.True(t, notModified)
+
+		// the rating of the movie changes, the review itself doesn't
+		req := &contracts.UpdateReviewRequest{
+			ReviewID: review2.ID,
+			UserID:   review2.UserID,
+			Rating:   review2.Rating - 4,
+			Title:    review2.Title,
+			Content:  review2.Content,
+		}
+		err = c.UpdateReview(contracts.NewAuthenticated(req, reviewer2Token))
+		require.NoError(t, err)
+
+		notModified, err = c.NotModifiedETag(path, tag)
+		require.NoError(t, err)
+		require.False(t, notModified)
+
+		req.Rating = review2.Rating
+		err = c.UpdateReview(contracts.NewAuthenticated(req, reviewer2Token))
+		require.NoError(t, err)
+	})
 }
 
 func getReview(t *testing.T, c *client.Client, reviewID int) *contracts.Review {
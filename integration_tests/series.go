@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+func seriesAPIChecks(t *testing.T, c *client.Client) {
+	var series, season, episode1, episode2 *contracts.MovieDetails
+	t.Run("movies.CreateMovie: series, season and episodes", func(t *testing.T) {
+		var err error
+		series, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "series",
+			Title:       "The Mandalorian",
+			ReleaseDate: time.Date(2019, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Action.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, "series", series.Kind)
+
+		season, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "season",
+			ParentID:    &series.ID,
+			Number:      contracts.Ptr(1),
+			Title:       "Season 1",
+			ReleaseDate: time.Date(2019, time.November, 12, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		require.Equal(t, series.ID, *season.ParentID)
+
+		episode1, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "episode",
+			ParentID:    &season.ID,
+			Number:      contracts.Ptr(1),
+			Title:       "Chapter 1: The Mandalorian",
+			ReleaseDate: time.Date(2019, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Cast: []*contracts.MovieCreditInfo{
+				{StarID: hamill.ID, Role: "actor", Characters: []string{"Luke Skywalker"}},
+			},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		episode2, err = c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "episode",
+			ParentID:    &season.ID,
+			Number:      contracts.Ptr(2),
+			Title:       "Chapter 2: The Child",
+			ReleaseDate: time.Date(2019, time.November, 15, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		require.NoError(t, err)
+	})
+
+	t.Run("movies.CreateMovie: episode number already exists", func(t *testing.T) {
+		_, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "episode",
+			ParentID:    &season.ID,
+			Number:      contracts.Ptr(2),
+			Title:       "Chapter 2: The Child",
+			ReleaseDate: time.Date(2019, time.November, 15, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		requireAlreadyExistsError(t, err, "episode", "number", 2)
+	})
+
+	t.Run("movies.CreateMovie: episode of a series", func(t *testing.T) {
+		_, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "episode",
+			ParentID:    &series.ID,
+			Number:      contracts.Ptr(3),
+			Title:       "Chapter 3: The Sin",
+			ReleaseDate: time.Date(2019, time.November, 22, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "episode must belong to a season")
+	})
+
+	t.Run("movies.CreateMovie: season without parent", func(t *testing.T) {
+		_, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Kind:        "season",
+			Title:       "Season 2",
+			ReleaseDate: time.Date(2020, time.October, 30, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		requireBadRequestError(t, err, "season must have a parent and a number")
+	})
+
+	t.Run("movies.GetMovies: episodes of a season", func(t *testing.T) {
+		res, err := c.GetMovies(&contracts.GetMoviesRequest{ParentID: &season.ID})
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Movie{&episode1.Movie, &episode2.Movie}, res.Items)
+	})
+
+	t.Run("movies.GetMovies: by kind", func(t *testing.T) {
+		res, err := c.GetMovies(&contracts.GetMoviesRequest{Kind: contracts.Ptr("series")})
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Movie{&series.Movie}, res.Items)
+	})
+
+	t.Run("movies.GetMovies: search finds episodes", func(t *testing.T) {
+		res, err := c.GetMovies(&contracts.GetMoviesRequest{SearchTerm: contracts.Ptr("child")})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		require.Equal(t, episode2.ID, res.Items[0].ID)
+	})
+
+	t.Run("reviews.CreateReview: episode ratings roll up", func(t *testing.T) {
+		reviewer := registerRandomUser(t, c)
+		reviewerToken := login(t, c, reviewer.Email, standardPassword)
+		for _, review := range []struct {
+			movieID int
+			rating  int
+		}{
+			{episode1.ID, 8},
+			{episode2.ID, 10},
+		} {
+			_, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+				MovieID: review.movieID,
+				UserID:  reviewer.ID,
+				Rating:  review.rating,
+				Title:   "This is the way",
+				Content: "Great episode",
+			}, reviewerToken))
+			require.NoError(t, err)
+		}
+
+		for _, id := range []int{season.ID, series.ID} {
+			movie, err := c.GetMovieByID(id)
+			require.NoError(t, err)
+			requireRatingEqual(t, 9, *movie.AvgRating)
+		}
+	})
+
+	t.Run("movies.DeleteMovie: episode", func(t *testing.T) {
+		err := c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: episode2.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		movie, err := c.GetMovieByID(series.ID)
+		require.NoError(t, err)
+		requireRatingEqual(t, 8, *movie.AvgRating)
+	})
+
+	t.Run("movies.DeleteMovie: series with seasons and episodes", func(t *testing.T) {
+		err := c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: series.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		_, err = c.GetMovieByID(episode1.ID)
+		requireNotFoundError(t, err, "movie", "id", episode1.ID)
+	})
+}
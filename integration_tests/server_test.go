@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hashicorp/consul/sdk/testutil/retry"
@@ -17,8 +18,13 @@ func TestServer(t *testing.T) {
 	prepareInfrastructure(t, runServer)
 }
 
-func runServer(t *testing.T, pgConnString string) {
-	cfg := getConfig(pgConnString)
+func runServer(t *testing.T, infra *infrastructure) {
+	cfg := getConfig(infra)
+
+	sink := newEventSink()
+	sinkServer := httptest.NewServer(sink)
+	defer sinkServer.Close()
+	cfg.Events.SinkURL = sinkServer.URL
 
 	srv, err := server.New(context.Background(), cfg)
 	require.NoError(t, err)
@@ -38,13 +44,13 @@ func runServer(t *testing.T, pgConnString string) {
 		}
 	})
 
-	tests(t, port, cfg)
+	tests(t, port, cfg, sink)
 
 	err = srv.Shutdown(context.Background())
 	require.NoError(t, err)
 }
 
-func tests(t *testing.T, port int, cfg *config.Config) {
+func tests(t *testing.T, port int, cfg *config.Config, sink *eventSink) {
 	addr := fmt.Sprintf("http://localhost:%d", port)
 	c := client.New(addr)
 
@@ -59,4 +65,18 @@ func tests(t *testing.T, port int, cfg *config.Config) {
 	starsAPIChecks(t, c)
 	moviesAPIChecks(t, c)
 	reviewsAPIChecks(t, c)
+	collectionsAPIChecks(t, c)
+	awardsAPIChecks(t, c)
+	genreHierarchyAPIChecks(t, c)
+	mergesAPIChecks(t, c)
+	seriesAPIChecks(t, c)
+	imagesAPIChecks(t, c, cfg)
+	trashAPIChecks(t, c, cfg)
+	privacyAPIChecks(t, c, cfg)
+	bulkAPIChecks(t, c)
+	jobsAPIChecks(t, c, cfg)
+	eventsAPIChecks(t, c, sink)
+	webhooksAPIChecks(t, c, cfg)
+	streamsAPIChecks(t, c)
+	graphqlAPIChecks(t, c)
 }
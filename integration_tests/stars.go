@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"encoding/base64"
+	"fmt"
 	"testing"
 	"time"
 
@@ -110,6 +112,95 @@ func starsAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, []*contracts.Star{&mcgregor.Star}, res.Items)
 	})
 
+	t.Run("stars.GetAll: cursor", func(t *testing.T) {
+		req := contracts.GetStarsRequest{}
+		res, err := c.GetStars(&req)
+		require.NoError(t, err)
+		require.NotNil(t, res.Next)
+		require.Nil(t, res.Prev)
+
+		req.SetAfter(*res.Next)
+		res, err = c.GetStars(&req)
+		require.NoError(t, err)
+		require.True(t, res.Uncounted)
+		require.Nil(t, res.Next)
+		require.NotNil(t, res.Prev)
+		require.Equal(t, []*contracts.Star{&mcgregor.Star}, res.Items)
+
+		req = contracts.GetStarsRequest{}
+		req.Before = *res.Prev
+		req.WithTotal = contracts.Ptr(true)
+		res, err = c.GetStars(&req)
+		require.NoError(t, err)
+		require.Equal(t, 3, res.Total)
+		require.Nil(t, res.Prev)
+		require.Equal(t, []*contracts.Star{&lucas.Star, &hamill.Star}, res.Items)
+	})
+
+	t.Run("stars.GetAll: sorted by name", func(t *testing.T) {
+		req := contracts.GetStarsRequest{
+			Sort: contracts.Ptr("name"),
+		}
+		stars, err := client.Paginate(&req, c.GetStars)
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Star{&hamill.Star, &lucas.Star, &mcgregor.Star}, stars)
+	})
+
+	t.Run("stars.GetAll: sorted by birth date descending", func(t *testing.T) {
+		req := contracts.GetStarsRequest{
+			Sort: contracts.Ptr("-birth_date"),
+		}
+		stars, err := client.Paginate(&req, c.GetStars)
+		require.NoError(t, err)
+		require.Equal(t, []*contracts.Star{&mcgregor.Star, &hamill.Star, &lucas.Star}, stars)
+	})
+
+	t.Run("stars.GetAll: invalid cursor", func(t *testing.T) {
+		req := contracts.GetStarsRequest{}
+		req.After = "garbage"
+		_, err := c.GetStars(&req)
+		requireBadRequestError(t, err, "invalid cursor")
+	})
+
+	t.Run("stars.GetAll: tampered cursor", func(t *testing.T) {
+		cases := []struct {
+			sort *string
+			key  string
+		}{
+			{key: `["1; DROP TABLE stars"]`},
+			{key: `["99999999999"]`},
+			{sort: contracts.Ptr("birth_date"), key: `["1977-13-45", "1"]`},
+			{sort: contracts.Ptr("created_at"), key: `["yesterday", "1"]`},
+		}
+		for _, cc := range cases {
+			req := contracts.GetStarsRequest{Sort: cc.sort}
+			req.After = base64.RawURLEncoding.EncodeToString([]byte(cc.key))
+			_, err := c.GetStars(&req)
+			requireBadRequestError(t, err, "invalid cursor")
+		}
+	})
+
+	t.Run("stars.GetStarSuggestions: typo", func(t *testing.T) {
+		req := &contracts.GetStarSuggestionsRequest{
+			Query: "Mark Hamil",
+			Limit: 1,
+		}
+		res, err := c.GetStarSuggestions(req)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, hamill.ID, res[0].ID)
+	})
+
+	t.Run("stars.GetStarSuggestions: no matches", func(t *testing.T) {
+		req := &contracts.GetStarSuggestionsRequest{
+			Query: "Qwxz",
+		}
+		res, err := c.GetStarSuggestions(req)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		require.Empty(t, res)
+	})
+
 	t.Run("stars.Update: success", func(t *testing.T) {
 		req := &contracts.UpdateStarRequest{
 			ID:         mcgregor.ID,
@@ -128,6 +219,85 @@ func starsAPIChecks(t *testing.T, c *client.Client) {
 		require.Equal(t, *req.Bio, *res.Bio)
 	})
 
+	t.Run("stars.Update: stale version", func(t *testing.T) {
+		star, err := c.GetStarByID(mcgregor.ID)
+		require.NoError(t, err)
+
+		req := &contracts.UpdateStarRequest{
+			ID:         mcgregor.ID,
+			FirstName:  mcgregor.FirstName,
+			MiddleName: mcgregor.MiddleName,
+			LastName:   mcgregor.LastName,
+			BirthDate:  mcgregor.BirthDate,
+			BirthPlace: mcgregor.BirthPlace,
+			Bio:        contracts.Ptr("Updated bio"),
+			Version:    contracts.Ptr(star.Version),
+		}
+		err = c.UpdateStar(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		err = c.UpdateStar(contracts.NewAuthenticated(req, johnDoeToken))
+		requireVersionMismatch(t, err, "star", "id", mcgregor.ID, star.Version)
+
+		err = c.DeleteStar(contracts.NewAuthenticated(&contracts.DeleteStarRequest{
+			ID:      mcgregor.ID,
+			Version: contracts.Ptr(star.Version),
+		}, johnDoeToken))
+		requireVersionMismatch(t, err, "star", "id", mcgregor.ID, star.Version)
+	})
+
+	t.Run("stars.DeleteStar: stale entity tag from GET", func(t *testing.T) {
+		path := fmt.Sprintf("/api/stars/%d", mcgregor.ID)
+		tag, err := c.ETag(path)
+		require.NoError(t, err)
+		star, err := c.GetStarByID(mcgregor.ID)
+		require.NoError(t, err)
+
+		req := &contracts.UpdateStarRequest{
+			ID:         mcgregor.ID,
+			FirstName:  mcgregor.FirstName,
+			MiddleName: mcgregor.MiddleName,
+			LastName:   mcgregor.LastName,
+			BirthDate:  mcgregor.BirthDate,
+			BirthPlace: mcgregor.BirthPlace,
+			Bio:        star.Bio,
+		}
+		err = c.UpdateStar(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		// the tag of GET is understood by If-Match, the version it carries is stale
+		err = c.DeleteETag(path, tag, johnDoeToken)
+		requireVersionMismatch(t, err, "star", "id", mcgregor.ID, star.Version)
+	})
+
+	t.Run("stars.PatchStar: success", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID: mcgregor.ID,
+			Patch: map[string]any{
+				"bio":         "Patched bio",
+				"birth_place": nil,
+			},
+		}
+		err := c.PatchStar(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		res, err := c.GetStarByID(mcgregor.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Patched bio", *res.Bio)
+		require.Nil(t, res.BirthPlace)
+		require.Equal(t, mcgregor.FirstName, res.FirstName)
+		require.Equal(t, mcgregor.MiddleName, res.MiddleName)
+	})
+
+	t.Run("stars.PatchStar: invalid patch", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    mcgregor.ID,
+			Patch: map[string]any{"first_name": nil},
+		}
+		err := c.PatchStar(contracts.NewAuthenticated(req, johnDoeToken))
+		requireBadRequestError(t, err, "FirstName")
+	})
+
 	t.Run("stars.Update: unathorized", func(t *testing.T) {
 		req := &contracts.UpdateStarRequest{
 			ID:         mcgregor.ID,
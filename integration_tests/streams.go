@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/stretchr/testify/require"
+)
+
+// requireNextUpdate reads the next update of the stream and checks its type and movie.
+func requireNextUpdate(t *testing.T, stream *client.UpdateStream, updateType string, movieID int) *contracts.LiveUpdate {
+	type result struct {
+		update *contracts.LiveUpdate
+		err    error
+	}
+	next := make(chan result, 1)
+	go func() {
+		update, err := stream.Next()
+		next <- result{update, err}
+	}()
+
+	select {
+	case res := <-next:
+		require.NoError(t, res.err)
+		require.Equal(t, updateType, res.update.Type)
+		require.Equal(t, movieID, res.update.MovieID)
+		return res.update
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "no live update", "%s of movie %d is expected", updateType, movieID)
+		return nil
+	}
+}
+
+func streamsAPIChecks(t *testing.T, c *client.Client) {
+	movie, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Halloween",
+		ReleaseDate: time.Date(1978, time.October, 25, 0, 0, 0, 0, time.UTC),
+		Genres:      []int{Drama.ID},
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	reviewer := registerRandomUser(t, c)
+	reviewerToken := login(t, c, reviewer.Email, standardPassword)
+
+	t.Run("streams.StreamMovieUpdates: not found", func(t *testing.T) {
+		_, err := c.StreamMovieUpdates(fakeID)
+		requireNotFoundError(t, err, "movie", "id", fakeID)
+	})
+
+	t.Run("streams.StreamUpdates: insufficient permissions", func(t *testing.T) {
+		_, err := c.StreamUpdates(contracts.NewAuthenticated(&contracts.StreamUpdatesRequest{}, reviewerToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("streams.StreamMovieUpdates: reviews and rating", func(t *testing.T) {
+		stream, err := c.StreamMovieUpdates(movie.ID)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		all, err := c.StreamUpdates(contracts.NewAuthenticated(&contracts.StreamUpdatesRequest{}, johnDoeToken))
+		require.NoError(t, err)
+		defer all.Close()
+
+		review, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+			MovieID: movie.ID,
+			UserID:  reviewer.ID,
+			Rating:  8,
+			Title:   "The night he came home",
+			Content: "A simple premise turned into the blueprint of the slasher genre.",
+		}, reviewerToken))
+		require.NoError(t, err)
+
+		update := requireNextUpdate(t, stream, "review.created", movie.ID)
+		var data struct {
+			ID     int `json:"id"`
+			Rating int `json:"rating"`
+		}
+		require.NoError(t, json.Unmarshal(update.Data, &data))
+		require.Equal(t, review.ID, data.ID)
+		require.Equal(t, 8, data.Rating)
+
+		update = requireNextUpdate(t, stream, "rating.changed", movie.ID)
+		var rating struct {
+			AvgRating *float64 `json:"avg_rating"`
+		}
+		require.NoError(t, json.Unmarshal(update.Data, &rating))
+		require.Equal(t, 8.0, *rating.AvgRating)
+
+		requireNextUpdate(t, all, "review.created", movie.ID)
+		requireNextUpdate(t, all, "rating.changed", movie.ID)
+
+		err = c.UpdateReview(contracts.NewAuthenticated(&contracts.UpdateReviewRequest{
+			ReviewID: review.ID,
+			UserID:   reviewer.ID,
+			Rating:   6,
+			Title:    "The night he came home",
+			Content:  "Still the blueprint of the slasher genre, but it has aged a bit.",
+		}, reviewerToken))
+		require.NoError(t, err)
+
+		requireNextUpdate(t, stream, "review.updated", movie.ID)
+		update = requireNextUpdate(t, stream, "rating.changed", movie.ID)
+		require.NoError(t, json.Unmarshal(update.Data, &rating))
+		require.Equal(t, 6.0, *rating.AvgRating)
+
+		err = c.DeleteReview(contracts.NewAuthenticated(&contracts.DeleteReviewRequest{
+			ReviewID: review.ID,
+			UserID:   reviewer.ID,
+		}, reviewerToken))
+		require.NoError(t, err)
+
+		requireNextUpdate(t, stream, "review.deleted", movie.ID)
+		update = requireNextUpdate(t, stream, "rating.changed", movie.ID)
+		require.NoError(t, json.Unmarshal(update.Data, &rating))
+		require.Nil(t, rating.AvgRating)
+	})
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func trashAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
+	weaver, err := c.CreateStar(contracts.NewAuthenticated(&contracts.CreateStarRequest{
+		FirstName: "Sigourney",
+		LastName:  "Weaver",
+		BirthDate: time.Date(1949, time.October, 8, 0, 0, 0, 0, time.UTC),
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	alien, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+		Title:       "Alien",
+		ReleaseDate: time.Date(1979, time.May, 25, 0, 0, 0, 0, time.UTC),
+		Genres:      []int{Drama.ID},
+		Cast: []*contracts.MovieCreditInfo{
+			{StarID: weaver.ID, Role: "actor", Characters: []string{"Ellen Ripley"}},
+		},
+	}, johnDoeToken))
+	require.NoError(t, err)
+
+	reviewer := registerRandomUser(t, c)
+	reviewerToken := login(t, c, reviewer.Email, standardPassword)
+	review, err := c.CreateReview(contracts.NewAuthenticated(&contracts.CreateReviewRequest{
+		MovieID: alien.ID,
+		UserID:  reviewer.ID,
+		Rating:  9,
+		Title:   "In space no one can hear you scream",
+		Content: "A haunted house movie set aboard a spaceship, and still the best of them.",
+	}, reviewerToken))
+	require.NoError(t, err)
+
+	t.Run("trash.GetTrash: insufficient permissions", func(t *testing.T) {
+		_, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{Kind: "movies"}, johnDoeToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("trash.GetTrash: unknown kind", func(t *testing.T) {
+		_, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{Kind: "genres"}, adminToken))
+		requireBadRequestError(t, err, "Kind")
+	})
+
+	t.Run("trash.RestoreFromTrash: not deleted", func(t *testing.T) {
+		err := c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "stars", ID: weaver.ID}, adminToken))
+		requireBadRequestError(t, err, "is not deleted")
+	})
+
+	t.Run("trash.RestoreFromTrash: not found", func(t *testing.T) {
+		err := c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "stars", ID: fakeID}, adminToken))
+		requireNotFoundError(t, err, "star", "id", fakeID)
+	})
+
+	t.Run("trash.RestoreFromTrash: review", func(t *testing.T) {
+		err := c.DeleteReview(contracts.NewAuthenticated(&contracts.DeleteReviewRequest{
+			ReviewID: review.ID,
+			UserID:   reviewer.ID,
+		}, reviewerToken))
+		require.NoError(t, err)
+		movie, err := c.GetMovieByID(alien.ID)
+		require.NoError(t, err)
+		require.Nil(t, movie.AvgRating)
+
+		trash, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{Kind: "reviews"}, adminToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, trash.Items)
+		require.Equal(t, review.ID, trash.Items[0].ID)
+		require.Equal(t, review.Title, trash.Items[0].Name)
+
+		err = c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "reviews", ID: review.ID}, adminToken))
+		require.NoError(t, err)
+
+		restored := getReview(t, c, review.ID)
+		require.Equal(t, review.Version+1, restored.Version)
+		movie, err = c.GetMovieByID(alien.ID)
+		require.NoError(t, err)
+		requireRatingEqual(t, 9, *movie.AvgRating)
+	})
+
+	t.Run("trash.RestoreFromTrash: movie with its genres and cast", func(t *testing.T) {
+		err := c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: alien.ID}, johnDoeToken))
+		require.NoError(t, err)
+		_, err = c.GetMovieByID(alien.ID)
+		requireNotFoundError(t, err, "movie", "id", alien.ID)
+
+		trash, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{Kind: "movies"}, adminToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, trash.Items)
+		item := trash.Items[0]
+		require.Equal(t, alien.ID, item.ID)
+		require.Equal(t, "Alien", item.Name)
+		require.Equal(t, item.DeletedAt.Add(720*time.Hour), item.PurgeAt)
+
+		err = c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "movies", ID: alien.ID}, adminToken))
+		require.NoError(t, err)
+
+		movie, err := c.GetMovieByID(alien.ID)
+		require.NoError(t, err)
+		require.Equal(t, []int{Drama.ID}, genreIDs(movie.Genres))
+		require.Len(t, movie.Cast, 1)
+		require.Equal(t, weaver.ID, movie.Cast[0].Star.ID)
+		requireRatingEqual(t, 9, *movie.AvgRating)
+	})
+
+	t.Run("trash.RestoreFromTrash: star", func(t *testing.T) {
+		err := c.DeleteStar(contracts.NewAuthenticated(&contracts.DeleteStarRequest{ID: weaver.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		trash, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{Kind: "stars"}, adminToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, trash.Items)
+		require.Equal(t, weaver.ID, trash.Items[0].ID)
+		require.Equal(t, "Sigourney Weaver", trash.Items[0].Name)
+
+		err = c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "stars", ID: weaver.ID}, adminToken))
+		require.NoError(t, err)
+
+		star, err := c.GetStarByID(weaver.ID)
+		require.NoError(t, err)
+		require.Equal(t, weaver.Version+1, star.Version)
+	})
+
+	t.Run("trash.RestoreFromTrash: merged star", func(t *testing.T) {
+		trash, err := c.GetTrash(contracts.NewAuthenticated(&contracts.GetTrashRequest{
+			PaginatedRequest: contracts.PaginatedRequest{Size: 50},
+			Kind:             "stars",
+		}, adminToken))
+		require.NoError(t, err)
+		for _, item := range trash.Items {
+			if item.MergedInto == nil {
+				continue
+			}
+			err = c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "stars", ID: item.ID}, adminToken))
+			requireBadRequestError(t, err, "can't be restored")
+			return
+		}
+		t.Fatal("no merged star in the trash")
+	})
+
+	t.Run("trash.Purge: expired movie with its image", func(t *testing.T) {
+		ctx := context.Background()
+		prometheus, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "Prometheus",
+			ReleaseDate: time.Date(2012, time.June, 8, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Drama.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+		poster, err := c.UploadMovieImage(contracts.NewAuthenticated(&contracts.UploadImageRequest{
+			ID:       prometheus.ID,
+			Kind:     "poster",
+			FileName: "poster.png",
+			Content:  testImage(t, 300, 450),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		err = c.DeleteMovie(contracts.NewAuthenticated(&contracts.DeleteMovieRequest{ID: prometheus.ID}, johnDoeToken))
+		require.NoError(t, err)
+
+		st, err := storage.New(ctx, cfg.Storage)
+		require.NoError(t, err)
+		key := fmt.Sprintf("images/%d/original.png", poster.ID)
+		content, err := st.Get(ctx, key)
+		require.NoError(t, err)
+		require.NoError(t, content.Close())
+
+		conn, err := pgx.Connect(ctx, cfg.DbURL)
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		_, err = conn.Exec(ctx, "UPDATE movies SET deleted_at = NOW() - make_interval(secs => $2) WHERE id = $1",
+			prometheus.ID, (cfg.Trash.Retention + time.Hour).Seconds())
+		require.NoError(t, err)
+
+		runJob(t, c, "trash.purge")
+
+		err = c.RestoreFromTrash(contracts.NewAuthenticated(&contracts.RestoreRequest{Kind: "movies", ID: prometheus.ID}, adminToken))
+		requireNotFoundError(t, err, "movie", "id", prometheus.ID)
+		_, err = st.Get(ctx, key)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -52,6 +53,18 @@ func usersAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
 		require.Equal(t, bio, *johnDoe.Bio)
 	})
 
+	t.Run("users.UpdateUser: absent bio is kept", func(t *testing.T) {
+		bio := *johnDoe.Bio
+		req := &contracts.UpdateUserRequest{
+			UserID: johnDoe.ID,
+		}
+		err := c.UpdateUser(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		johnDoe = getUser(t, c, johnDoe.ID)
+		require.Equal(t, bio, *johnDoe.Bio)
+	})
+
 	t.Run("users.UpdateUser: non-authenticated", func(t *testing.T) {
 		bio := "I'm John Doe"
 		req := &contracts.UpdateUserRequest{
@@ -86,6 +99,42 @@ func usersAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
 		require.Equal(t, bio, *johnDoe.Bio)
 	})
 
+	t.Run("users.UpdateUser: stale version", func(t *testing.T) {
+		bio := "I'm John Doe"
+		req := &contracts.UpdateUserRequest{
+			UserID:  johnDoe.ID,
+			Bio:     &bio,
+			Version: contracts.Ptr(johnDoe.Version - 1),
+		}
+		err := c.UpdateUser(contracts.NewAuthenticated(req, johnDoeToken))
+		requireVersionMismatch(t, err, "user", "id", johnDoe.ID, *req.Version)
+
+		notModified, err := c.NotModified(fmt.Sprintf("/api/users/%d", johnDoe.ID), johnDoe.Version)
+		require.NoError(t, err)
+		require.True(t, notModified)
+	})
+
+	t.Run("users.PatchUser: success", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    johnDoe.ID,
+			Patch: map[string]any{"bio": "Patched by John Doe"},
+		}
+		err := c.PatchUser(contracts.NewAuthenticated(req, johnDoeToken))
+		require.NoError(t, err)
+
+		johnDoe = getUser(t, c, johnDoe.ID)
+		require.Equal(t, "Patched by John Doe", *johnDoe.Bio)
+	})
+
+	t.Run("users.PatchUser: another user", func(t *testing.T) {
+		req := &contracts.MergePatchRequest{
+			ID:    johnDoe.ID + 1,
+			Patch: map[string]any{"bio": "Patched by John Doe"},
+		}
+		err := c.PatchUser(contracts.NewAuthenticated(req, johnDoeToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
 	t.Run("users.SetUserRole: John Doe to editor", func(t *testing.T) {
 		req := &contracts.SetUserRoleRequest{
 			UserID: johnDoe.ID,
@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mkuptsov/movie-reviews/client"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSecret = "0123456789abcdef0123456789abcdef"
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// event decodes the body of the webhook, it is the domain event.
+func (w *receivedWebhook) event(t *testing.T) *sinkEvent {
+	var event sinkEvent
+	require.NoError(t, json.Unmarshal(w.body, &event))
+	return &event
+}
+
+// webhookReceiver stands in for a partner site, it fails the number of requests set by failNext.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failNext int
+	received []*receivedWebhook
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failNext > 0 {
+		r.failNext--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	r.received = append(r.received, &receivedWebhook{header: req.Header, body: body})
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *webhookReceiver) fail(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failNext = n
+}
+
+func (r *webhookReceiver) requireReceived(t *testing.T, match func(w *receivedWebhook) bool) *receivedWebhook {
+	var found *receivedWebhook
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, w := range r.received {
+			if match(w) {
+				found = w
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 100*time.Millisecond)
+	return found
+}
+
+func webhooksAPIChecks(t *testing.T, c *client.Client, cfg *config.Config) {
+	receiver := &webhookReceiver{}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+
+	var webhook *contracts.Webhook
+	var original, replay *contracts.WebhookDelivery
+
+	t.Run("webhooks.CreateWebhook: insufficient permissions", func(t *testing.T) {
+		_, err := c.CreateWebhook(contracts.NewAuthenticated(&contracts.CreateWebhookRequest{
+			URL:        receiverServer.URL,
+			EventTypes: []string{"movie.created"},
+			Secret:     webhookSecret,
+		}, johnDoeToken))
+		requireForbiddenError(t, err, "insufficient permissions")
+	})
+
+	t.Run("webhooks.CreateWebhook: invalid url", func(t *testing.T) {
+		_, err := c.CreateWebhook(contracts.NewAuthenticated(&contracts.CreateWebhookRequest{
+			URL:        "ftp://example.com",
+			EventTypes: []string{"movie.created"},
+			Secret:     webhookSecret,
+		}, adminToken))
+		requireBadRequestError(t, err, "invalid url")
+	})
+
+	t.Run("webhooks.CreateWebhook: unknown event type", func(t *testing.T) {
+		_, err := c.CreateWebhook(contracts.NewAuthenticated(&contracts.CreateWebhookRequest{
+			URL:        receiverServer.URL,
+			EventTypes: []string{"movie.watched"},
+			Secret:     webhookSecret,
+		}, adminToken))
+		requireBadRequestError(t, err, "unknown event type")
+	})
+
+	t.Run("webhooks.CreateWebhook: success", func(t *testing.T) {
+		var err error
+		webhook, err = c.CreateWebhook(contracts.NewAuthenticated(&contracts.CreateWebhookRequest{
+			URL:        receiverServer.URL,
+			EventTypes: []string{"movie.created", "movie.updated", "review.posted"},
+			Secret:     webhookSecret,
+		}, adminToken))
+		require.NoError(t, err)
+		require.True(t, webhook.Active)
+
+		webhooks, err := c.GetWebhooks(contracts.NewAuthenticated(&contracts.GetWebhooksRequest{}, adminToken))
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.Equal(t, webhook.ID, webhooks[0].ID)
+	})
+
+	t.Run("webhooks.CreateMovie: signed delivery", func(t *testing.T) {
+		movie, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "The Thing",
+			ReleaseDate: time.Date(1982, time.June, 25, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Drama.ID},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		delivered := receiver.requireReceived(t, func(w *receivedWebhook) bool {
+			return w.header.Get("X-Event-Type") == "movie.created" && w.event(t).SubjectID == movie.ID
+		})
+		event := delivered.event(t)
+		require.Equal(t, strconv.FormatInt(event.ID, 10), delivered.header.Get("X-Event-ID"))
+		require.Equal(t, strconv.Itoa(webhook.ID), delivered.header.Get("X-Webhook-ID"))
+
+		var payload struct {
+			Title string `json:"title"`
+		}
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		require.Equal(t, "The Thing", payload.Title)
+
+		mac := hmac.New(sha256.New, []byte(webhookSecret))
+		mac.Write([]byte(delivered.header.Get("X-Webhook-Timestamp") + "."))
+		mac.Write(delivered.body)
+		require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), delivered.header.Get("X-Webhook-Signature"))
+	})
+
+	t.Run("webhooks.GetWebhookDeliveries: retried after failure", func(t *testing.T) {
+		receiver.fail(1)
+		star, err := c.CreateStar(contracts.NewAuthenticated(&contracts.CreateStarRequest{
+			FirstName: "Kurt",
+			LastName:  "Russell",
+			BirthDate: time.Date(1951, time.March, 17, 0, 0, 0, 0, time.UTC),
+		}, johnDoeToken))
+		require.NoError(t, err)
+		movie, err := c.CreateMovie(contracts.NewAuthenticated(&contracts.CreateMovieRequest{
+			Title:       "Escape from New York",
+			ReleaseDate: time.Date(1981, time.July, 10, 0, 0, 0, 0, time.UTC),
+			Genres:      []int{Action.ID},
+			Cast: []*contracts.MovieCreditInfo{
+				{StarID: star.ID, Role: "actor", Characters: []string{"Snake Plissken"}},
+			},
+		}, johnDoeToken))
+		require.NoError(t, err)
+
+		received := receiver.requireReceived(t, func(w *receivedWebhook) bool {
+			return w.header.Get("X-Event-Type") == "movie.created" && w.event(t).SubjectID == movie.ID
+		})
+
+		var delivery *contracts.WebhookDelivery
+		require.Eventually(t, func() bool {
+			res, err := c.GetWebhookDeliveries(contracts.NewAuthenticated(&contracts.GetWebhookDeliveriesRequest{
+				WebhookID: webhook.ID,
+			}, adminToken))
+			require.NoError(t, err)
+			for _, d := range res.Items {
+				require.NotEqual(t, "star.created", d.EventType)
+				if strconv.FormatInt(d.ID, 10) == received.header.Get("X-Webhook-Delivery") {
+					delivery = d
+					return d.Status == "succeeded"
+				}
+			}
+			return false
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, 2, delivery.Attempts)
+		require.Equal(t, http.StatusOK, *delivery.ResponseStatus)
+		require.NotNil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("webhooks.ReplayWebhookDelivery: not found", func(t *testing.T) {
+		_, err := c.ReplayWebhookDelivery(contracts.NewAuthenticated(&contracts.ReplayWebhookDeliveryRequest{
+			WebhookID:  webhook.ID,
+			DeliveryID: fakeID,
+		}, adminToken))
+		requireNotFoundError(t, err, "delivery", "id", fakeID)
+	})
+
+	t.Run("webhooks.ReplayWebhookDelivery: success", func(t *testing.T) {
+		res, err := c.GetWebhookDeliveries(contracts.NewAuthenticated(&contracts.GetWebhookDeliveriesRequest{
+			WebhookID: webhook.ID,
+			Status:    contracts.Ptr("succeeded"),
+		}, adminToken))
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Items)
+		original = res.Items[len(res.Items)-1]
+
+		replay, err = c.ReplayWebhookDelivery(contracts.NewAuthenticated(&contracts.ReplayWebhookDeliveryRequest{
+			WebhookID:  webhook.ID,
+			DeliveryID: original.ID,
+		}, adminToken))
+		require.NoError(t, err)
+		require.Equal(t, original.ID, *replay.ReplayOf)
+		require.Equal(t, original.EventID, replay.EventID)
+
+		receiver.requireReceived(t, func(w *receivedWebhook) bool {
+			return w.header.Get("X-Webhook-Delivery") == strconv.FormatInt(replay.ID, 10) &&
+				w.event(t).ID == original.EventID
+		})
+	})
+
+	t.Run("webhooks.CleanupJob: expired deliveries removed with their replays", func(t *testing.T) {
+		deliveryIDs := func(t *testing.T) []int64 {
+			res, err := c.GetWebhookDeliveries(contracts.NewAuthenticated(&contracts.GetWebhookDeliveriesRequest{
+				WebhookID:        webhook.ID,
+				PaginatedRequest: contracts.PaginatedRequest{Size: 50},
+			}, adminToken))
+			require.NoError(t, err)
+			ids := make([]int64, len(res.Items))
+			for i, delivery := range res.Items {
+				ids[i] = delivery.ID
+			}
+			return ids
+		}
+		require.Eventually(t, func() bool {
+			res, err := c.GetWebhookDeliveries(contracts.NewAuthenticated(&contracts.GetWebhookDeliveriesRequest{
+				WebhookID: webhook.ID,
+				Status:    contracts.Ptr("pending"),
+			}, adminToken))
+			require.NoError(t, err)
+			return len(res.Items) == 0
+		}, 10*time.Second, 100*time.Millisecond)
+		before := deliveryIDs(t)
+
+		ctx := context.Background()
+		conn, err := pgx.Connect(ctx, cfg.DbURL)
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		_, err = conn.Exec(ctx, "UPDATE webhook_deliveries SET created_at = NOW() - make_interval(secs => $2) WHERE id = $1",
+			original.ID, (cfg.Webhooks.Retention + time.Hour).Seconds())
+		require.NoError(t, err)
+
+		// the recent replay keeps the expired delivery it replays
+		runJob(t, c, "webhooks.cleanup")
+		require.Equal(t, before, deliveryIDs(t))
+
+		_, err = conn.Exec(ctx, "UPDATE webhook_deliveries SET created_at = NOW() - make_interval(secs => $2) WHERE id = $1",
+			replay.ID, (cfg.Webhooks.Retention + time.Hour).Seconds())
+		require.NoError(t, err)
+
+		runJob(t, c, "webhooks.cleanup")
+		after := deliveryIDs(t)
+		require.Len(t, after, len(before)-2)
+		require.NotContains(t, after, original.ID)
+		require.NotContains(t, after, replay.ID)
+	})
+
+	t.Run("webhooks.DeleteWebhook: success", func(t *testing.T) {
+		err := c.DeleteWebhook(contracts.NewAuthenticated(&contracts.DeleteWebhookRequest{ID: webhook.ID}, adminToken))
+		require.NoError(t, err)
+
+		_, err = c.GetWebhook(contracts.NewAuthenticated(&contracts.GetWebhookRequest{ID: webhook.ID}, adminToken))
+		requireNotFoundError(t, err, "webhook", "id", webhook.ID)
+	})
+}
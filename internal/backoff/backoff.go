@@ -0,0 +1,15 @@
+package backoff
+
+import (
+	"math"
+	"time"
+)
+
+// Exponential doubles the delay with every failed attempt starting from the base one, up to the maximum.
+func Exponential(base, maxDelay time.Duration, attempts int) time.Duration {
+	delay := float64(base) * math.Pow(2, float64(attempts-1))
+	if delay > float64(maxDelay) {
+		return maxDelay
+	}
+	return time.Duration(delay)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/caarlos0/env/v8"
@@ -13,6 +14,15 @@ type Config struct {
 	Jwt        JwtConfig        `envPrefix:"JWT_"`
 	Admin      AdminConfig      `envPrefix:"ADMIN_"`
 	Pagination PaginationConfig `envPrefix:"PAGINATION_"`
+	Storage    StorageConfig    `envPrefix:"STORAGE_"`
+	Images     ImagesConfig     `envPrefix:"IMAGES_"`
+	Trash      TrashConfig      `envPrefix:"TRASH_"`
+	Privacy    PrivacyConfig    `envPrefix:"PRIVACY_"`
+	Jobs       JobsConfig       `envPrefix:"JOBS_"`
+	Events     EventsConfig     `envPrefix:"EVENTS_"`
+	Webhooks   WebhooksConfig   `envPrefix:"WEBHOOKS_"`
+	Live       LiveConfig       `envPrefix:"LIVE_"`
+	GraphQL    GraphQLConfig    `envPrefix:"GRAPHQL_"`
 	Local      bool             `env:"LOCAL" envDefault:"false"`
 	LogLevel   string           `env:"LOG_LEVEL" envDefault:"info"`
 }
@@ -33,15 +43,129 @@ type PaginationConfig struct {
 	MaxSize     int `env:"MAX_SIZE" envDefault:"100"`
 }
 
+type StorageConfig struct {
+	Driver   string   `env:"DRIVER" envDefault:"local"`
+	LocalDir string   `env:"LOCAL_DIR" envDefault:"data/media"`
+	S3       S3Config `envPrefix:"S3_"`
+}
+
+type S3Config struct {
+	Endpoint     string `env:"ENDPOINT" envDefault:"https://s3.amazonaws.com"`
+	Region       string `env:"REGION" envDefault:"us-east-1"`
+	Bucket       string `env:"BUCKET"`
+	AccessKey    string `env:"ACCESS_KEY"`
+	SecretKey    string `env:"SECRET_KEY"`
+	PathStyle    bool   `env:"PATH_STYLE" envDefault:"false"`
+	CreateBucket bool   `env:"CREATE_BUCKET" envDefault:"false"`
+}
+
+type ImagesConfig struct {
+	MaxSize int64 `env:"MAX_SIZE" envDefault:"10485760"`
+}
+
+// TrashConfig sets how long soft-deleted entities are kept before they are purged and the cron schedule
+// of the purge job, an empty schedule disables the purge.
+type TrashConfig struct {
+	Retention     time.Duration `env:"RETENTION" envDefault:"720h"`
+	PurgeSchedule string        `env:"PURGE_SCHEDULE" envDefault:"@hourly"`
+	// Deprecated: PurgeInterval is the former setting of the purge, it is converted to PurgeSchedule
+	// unless the latter is set. A non-positive interval disables the purge.
+	PurgeInterval *time.Duration `env:"PURGE_INTERVAL"`
+}
+
+// PrivacyConfig sets how long users can cancel the erasure of their accounts and the cron schedule
+// of the erasure job, an empty schedule disables the erasure.
+type PrivacyConfig struct {
+	ErasureGracePeriod time.Duration `env:"ERASURE_GRACE_PERIOD" envDefault:"168h"`
+	ErasureSchedule    string        `env:"ERASURE_SCHEDULE" envDefault:"@hourly"`
+}
+
+// JobsConfig sets up the background job queue. Zero workers disable running the jobs on this instance,
+// they can still be enqueued. Running jobs extend their lease, a job whose lease hasn't been extended
+// for the lock timeout is assumed to be abandoned.
+type JobsConfig struct {
+	Workers      int           `env:"WORKERS" envDefault:"2"`
+	PollInterval time.Duration `env:"POLL_INTERVAL" envDefault:"1s"`
+	LockTimeout  time.Duration `env:"LOCK_TIMEOUT" envDefault:"15m"`
+	MaxAttempts  int           `env:"MAX_ATTEMPTS" envDefault:"5"`
+	BaseBackoff  time.Duration `env:"BASE_BACKOFF" envDefault:"10s"`
+	MaxBackoff   time.Duration `env:"MAX_BACKOFF" envDefault:"1h"`
+}
+
+// EventsConfig sets up the dispatching of the domain events from the outbox. A claimed batch is leased
+// for the lease timeout, after which it is dispatched again. Events which fail the max attempts are set aside
+// as dead letters. The dispatched events are kept for the retention and cleaned up by the cleanup job,
+// an empty schedule disables the cleanup. Events are posted to the sink URL if it is set.
+type EventsConfig struct {
+	Dispatch        bool          `env:"DISPATCH" envDefault:"true"`
+	PollInterval    time.Duration `env:"POLL_INTERVAL" envDefault:"1s"`
+	BatchSize       int           `env:"BATCH_SIZE" envDefault:"100"`
+	LeaseTimeout    time.Duration `env:"LEASE_TIMEOUT" envDefault:"5m"`
+	MaxAttempts     int           `env:"MAX_ATTEMPTS" envDefault:"10"`
+	BaseBackoff     time.Duration `env:"BASE_BACKOFF" envDefault:"5s"`
+	MaxBackoff      time.Duration `env:"MAX_BACKOFF" envDefault:"1h"`
+	Retention       time.Duration `env:"RETENTION" envDefault:"168h"`
+	CleanupSchedule string        `env:"CLEANUP_SCHEDULE" envDefault:"@daily"`
+	SinkURL         string        `env:"SINK_URL"`
+	SinkTimeout     time.Duration `env:"SINK_TIMEOUT" envDefault:"10s"`
+}
+
+// WebhooksConfig sets up the webhook deliveries, they are retried as background jobs with the backoff
+// and the attempts of the job queue. Finished deliveries are kept for the retention and cleaned up
+// by the cleanup job, an empty schedule disables the cleanup.
+type WebhooksConfig struct {
+	Timeout         time.Duration `env:"TIMEOUT" envDefault:"10s"`
+	Retention       time.Duration `env:"RETENTION" envDefault:"720h"`
+	CleanupSchedule string        `env:"CLEANUP_SCHEDULE" envDefault:"@daily"`
+}
+
+// LiveConfig sets up the streams of live updates. A comment is sent to every stream after the heartbeat interval,
+// so that idle connections aren't closed by proxies.
+type LiveConfig struct {
+	Heartbeat      time.Duration `env:"HEARTBEAT" envDefault:"15s"`
+	BufferSize     int           `env:"BUFFER_SIZE" envDefault:"32"`
+	ReconnectDelay time.Duration `env:"RECONNECT_DELAY" envDefault:"1s"`
+}
+
+// GraphQLConfig limits the queries of the GraphQL API. MaxParallelism bounds the resolvers run at once
+// for a single request, MaxNodes bounds the items of all the lists a single query returns.
+type GraphQLConfig struct {
+	MaxDepth       int `env:"MAX_DEPTH" envDefault:"10"`
+	MaxParallelism int `env:"MAX_PARALLELISM" envDefault:"20"`
+	MaxNodes       int `env:"MAX_NODES" envDefault:"1000"`
+}
+
 func NewConfig() (*Config, error) {
 	var c Config
 	err := env.Parse(&c)
 	if err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
+
+	if _, ok := os.LookupEnv("TRASH_PURGE_SCHEDULE"); !ok && c.Trash.PurgeInterval != nil {
+		c.Trash.PurgeSchedule, err = IntervalSchedule(*c.Trash.PurgeInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse config: TRASH_PURGE_INTERVAL: %w", err)
+		}
+	}
 	return &c, nil
 }
 
+// IntervalSchedule converts the interval to the cron schedule running at the same rate. Only the intervals
+// evenly dividing an hour or a day can be converted, a non-positive one gives the empty schedule.
+func IntervalSchedule(d time.Duration) (string, error) {
+	switch {
+	case d <= 0:
+		return "", nil
+	case d%time.Hour == 0 && 24*time.Hour%d == 0:
+		return fmt.Sprintf("0 */%d * * *", d/time.Hour), nil
+	case d%time.Minute == 0 && time.Hour%d == 0:
+		return fmt.Sprintf("*/%d * * * *", d/time.Minute), nil
+	default:
+		return "", fmt.Errorf("interval %s has no cron equivalent, set TRASH_PURGE_SCHEDULE instead", d)
+	}
+}
+
 func (cfg *AdminConfig) IsSet() bool {
 	return cfg.Email != "" && cfg.Password != "" && cfg.Username != ""
 }
@@ -0,0 +1,151 @@
+package dbx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+)
+
+// KeyType is the SQL type of the key values of a column. Cursors come from the clients, so their values are
+// checked against it before they reach the database, where a malformed one would fail the whole query.
+type KeyType int
+
+const (
+	KeyInteger KeyType = iota
+	KeyBigInt
+	KeyReal
+	KeyNumeric
+	KeyDate
+	KeyTimestamp
+	KeyText
+)
+
+// OrderColumn is one column of a keyset ordering. Expr must never evaluate to NULL.
+// Type defaults to integer as most of the columns are ids.
+type OrderColumn struct {
+	Expr string
+	Args []any
+	Type KeyType
+	Desc bool
+}
+
+// Keyset is an ordering usable for keyset pagination. The last column must be unique, e.g. the primary key.
+type Keyset []OrderColumn
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Apply orders the query by the keyset and appends the textual key values of every row to the selected columns,
+// so that they can be scanned with ScanDest. With a non-empty cursor only rows after it are selected, or rows
+// before it when backward is set; in the latter case the order is reversed and the rows must be reversed back.
+func (k Keyset) Apply(q squirrel.SelectBuilder, cursor []string, backward bool) (squirrel.SelectBuilder, error) {
+	if len(cursor) > 0 && len(cursor) != len(k) {
+		return q, apperrors.BadRequest(ErrInvalidCursor)
+	}
+	for i, value := range cursor {
+		if !k[i].Type.valid(value) {
+			return q, apperrors.BadRequest(ErrInvalidCursor)
+		}
+	}
+
+	for _, col := range k {
+		q = q.Column(squirrel.Expr("("+col.Expr+")::text", col.Args...))
+
+		dir := " ASC"
+		if col.Desc != backward {
+			dir = " DESC"
+		}
+		q = q.OrderByClause("("+col.Expr+")"+dir, col.Args...)
+	}
+
+	if len(cursor) > 0 {
+		q = q.Where(k.after(cursor, backward))
+	}
+
+	return q, nil
+}
+
+// Number appends the textual key values of every row like Apply does, followed by the position of the row
+// in the keyset order within its partition. Filtering on the position selects the first rows of many partitions
+// at once, e.g. the first pages of the reviews of several movies.
+func (k Keyset) Number(q squirrel.SelectBuilder, partition string) squirrel.SelectBuilder {
+	order := make([]string, 0, len(k))
+	var args []any
+	for _, col := range k {
+		q = q.Column(squirrel.Expr("("+col.Expr+")::text", col.Args...))
+
+		dir := " ASC"
+		if col.Desc {
+			dir = " DESC"
+		}
+		order = append(order, "("+col.Expr+")"+dir)
+		args = append(args, col.Args...)
+	}
+	return q.Column(squirrel.Expr("row_number() OVER (PARTITION BY "+partition+" ORDER BY "+strings.Join(order, ", ")+")", args...))
+}
+
+// ScanDest returns scan destinations for the key values appended by Apply or Number.
+func (k Keyset) ScanDest(key []string) []any {
+	dest := make([]any, len(k))
+	for i := range k {
+		dest[i] = &key[i]
+	}
+	return dest
+}
+
+// NewKey allocates storage for the key values of a row.
+func (k Keyset) NewKey() []string {
+	return make([]string, len(k))
+}
+
+// after builds "(c1, c2, ...) > (v1, v2, ...)" in the expanded form which honours per-column directions:
+// c1 > v1 OR (c1 = v1 AND c2 > v2) OR ...
+func (k Keyset) after(cursor []string, backward bool) squirrel.Sqlizer {
+	or := squirrel.Or{}
+	for i, col := range k {
+		and := squirrel.And{}
+		for j := 0; j < i; j++ {
+			and = append(and, squirrel.Expr("("+k[j].Expr+") = ?", withArg(k[j].Args, cursor[j])...))
+		}
+
+		op := " > ?"
+		if col.Desc != backward {
+			op = " < ?"
+		}
+		and = append(and, squirrel.Expr("("+col.Expr+")"+op, withArg(col.Args, cursor[i])...))
+
+		or = append(or, and)
+	}
+	return or
+}
+
+// valid tells whether the value is the textual form of the type, as produced by Postgres.
+func (t KeyType) valid(value string) bool {
+	var err error
+	switch t {
+	case KeyInteger:
+		_, err = strconv.ParseInt(value, 10, 32)
+	case KeyBigInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case KeyReal:
+		_, err = strconv.ParseFloat(value, 32)
+	case KeyNumeric:
+		_, err = strconv.ParseFloat(value, 64)
+	case KeyDate:
+		_, err = time.Parse(time.DateOnly, value)
+	case KeyTimestamp:
+		_, err = time.Parse("2006-01-02 15:04:05.999999", value)
+	case KeyText:
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+	return err == nil
+}
+
+func withArg(args []any, arg any) []any {
+	result := make([]any, 0, len(args)+1)
+	return append(append(result, args...), arg)
+}
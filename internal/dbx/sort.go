@@ -0,0 +1,42 @@
+package dbx
+
+import "strings"
+
+// SortFields maps the public names of sortable fields to the non-NULL SQL expressions to order by, their
+// directions are set by the sort parameter.
+type SortFields map[string][]OrderColumn
+
+// SortKeyset turns a validated sort parameter, e.g. "-rating,name", into a keyset. Fields are comma separated,
+// a leading "-" means descending order. The tiebreaker columns make the order total and are appended as is.
+func SortKeyset(sort string, fields SortFields, tiebreaker ...OrderColumn) Keyset {
+	var keyset Keyset
+	for _, field := range ParseSort(sort) {
+		for _, col := range fields[field.Name] {
+			col.Desc = field.Desc
+			keyset = append(keyset, col)
+		}
+	}
+	return append(keyset, tiebreaker...)
+}
+
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+func ParseSort(sort string) []SortField {
+	var fields []SortField
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Name: strings.TrimPrefix(part, "+")}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			field = SortField{Name: name, Desc: true}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
@@ -9,21 +9,21 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var contextKey = struct{}{}
+type contextKey struct{}
 
 func WithTransaction(ctx context.Context, tx pgx.Tx) context.Context {
-	return context.WithValue(ctx, contextKey, tx)
+	return context.WithValue(ctx, contextKey{}, tx)
 }
 
 func FromContext(ctx context.Context, def Queryable) Queryable {
-	if tx, ok := ctx.Value(contextKey).(pgx.Tx); ok {
+	if tx, ok := ctx.Value(contextKey{}).(pgx.Tx); ok {
 		return tx
 	}
 
 	return def
 }
 
-func InTransaction(ctx context.Context, db *pgxpool.Pool, fn func(context.Context, pgx.Tx) error) error {
+func InTransaction(ctx context.Context, db *pgxpool.Pool, fn func(context.Context, pgx.Tx) error) (err error) {
 	tx, err := db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
@@ -33,12 +33,11 @@ func InTransaction(ctx context.Context, db *pgxpool.Pool, fn func(context.Contex
 	defer func() {
 		if err != nil {
 			if txErr := tx.Rollback(ctx); txErr != nil {
-				err = errors.Join(err, fmt.Errorf("rollback transaction: %w", err))
+				err = errors.Join(err, fmt.Errorf("rollback transaction: %w", txErr))
 			}
 		} else {
-			cerr := tx.Commit(ctx)
-			if cerr != nil {
-				err = fmt.Errorf("commit transaction: %w", err)
+			if cerr := tx.Commit(ctx); cerr != nil {
+				err = fmt.Errorf("commit transaction: %w", cerr)
 			}
 		}
 	}()
@@ -0,0 +1,110 @@
+package echox
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// ETag formats the version of an entity as a strong entity tag, e.g. "3".
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+func SetETag(c echo.Context, version int) {
+	c.Response().Header().Set(ETagHeader, ETag(version))
+}
+
+// RepresentationETag formats the version of an entity along with the hash of its rendered body as a strong
+// entity tag, e.g. "3-q2yYyi8ubvQ5Rc0pKzlyJw". IfMatch reads the version back from it.
+func RepresentationETag(version int, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strconv.Quote(strconv.Itoa(version) + "-" + base64.RawURLEncoding.EncodeToString(sum[:16]))
+}
+
+// SetRepresentationETag sets the ETag of the entity as JSONOrNotModified would respond with it, for the responses
+// that carry no body, e.g. the one of an update.
+func SetRepresentationETag(c echo.Context, version int, entity any) error {
+	body, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(ETagHeader, RepresentationETag(version, body))
+	return nil
+}
+
+// IfMatch returns the version the If-Match header requires, or nil when any version will do. Both the tags
+// of ETag and RepresentationETag are accepted, only the version of the latter is compared.
+func IfMatch(c echo.Context) (*int, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(IfMatchHeader))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, apperrors.BadRequest(fmt.Errorf("invalid %s header, a single strong entity tag is expected", IfMatchHeader))
+	}
+	unquoted, _, _ = strings.Cut(unquoted, "-")
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 0 {
+		return nil, apperrors.BadRequest(fmt.Errorf("invalid %s header, unknown entity tag %s", IfMatchHeader, header))
+	}
+
+	return &version, nil
+}
+
+// NotModified sets the ETag of the entity and reports whether the If-None-Match header matches it,
+// in which case the handler should respond with 304 Not Modified instead of the entity.
+func NotModified(c echo.Context, version int) bool {
+	SetETag(c, version)
+	return noneMatch(c, ETag(version))
+}
+
+// JSONOrNotModified responds with the entity and its RepresentationETag, or with 304 Not Modified when
+// the If-None-Match header matches it. It suits the entities whose representation depends on more than their
+// version, e.g. on the related entities, the locale or the included relations.
+func JSONOrNotModified(c echo.Context, version int, entity any) error {
+	body, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+
+	etag := RepresentationETag(version, body)
+	c.Response().Header().Set(ETagHeader, etag)
+	if noneMatch(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// noneMatch reports whether the If-None-Match header matches the entity tag, using the weak comparison.
+func noneMatch(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get(IfNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
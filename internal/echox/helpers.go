@@ -1,8 +1,16 @@
 package echox
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/mergepatch"
 	"gopkg.in/validator.v2"
 )
 
@@ -17,3 +25,78 @@ func BindAndValidate[T any](c echo.Context) (*T, error) {
 	}
 	return req, nil
 }
+
+// RedirectMoved permanently redirects to the same route with the id parameter replaced, keeping the query,
+// e.g. from a merged duplicate to its canonical entity.
+func RedirectMoved(c echo.Context, id int) error {
+	location := strings.Replace(c.Path(), ":id", strconv.Itoa(id), 1)
+	if query := c.Request().URL.RawQuery; query != "" {
+		location += "?" + query
+	}
+	return c.Redirect(http.StatusMovedPermanently, location)
+}
+
+const MIMEApplicationMergePatch = "application/merge-patch+json"
+
+// BindMergePatch binds an RFC 7396 merge patch of an update request. The path parameters are bound first,
+// then load fills in the current state, which the patch is applied to, and the result is validated.
+// The path parameters can't be patched.
+func BindMergePatch[T any](c echo.Context, load func(req *T) error) (*T, error) {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, MIMEApplicationMergePatch) && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return nil, apperrors.BadRequest(fmt.Errorf("unsupported content type %q, %s is expected", contentType, MIMEApplicationMergePatch))
+	}
+
+	binder := &echo.DefaultBinder{}
+	current := new(T)
+	if err := binder.BindPathParams(c, current); err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed request")
+	}
+	if err := load(current); err != nil {
+		return nil, err
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed request")
+	}
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	merged, err := mergepatch.Apply(doc, patch)
+	if err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed merge patch")
+	}
+
+	req := new(T)
+	if err = json.Unmarshal(merged, req); err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed merge patch")
+	}
+	if err = binder.BindPathParams(c, req); err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed request")
+	}
+
+	if err = validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+	return req, nil
+}
+
+// BindAndValidateParams binds only the path and query parameters, leaving the body to be read by the handler,
+// e.g. when it isn't JSON.
+func BindAndValidateParams[T any](c echo.Context) (*T, error) {
+	binder := &echo.DefaultBinder{}
+	req := new(T)
+	if err := binder.BindPathParams(c, req); err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed request")
+	}
+	if err := binder.BindQueryParams(c, req); err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid or malformed request")
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+	return req, nil
+}
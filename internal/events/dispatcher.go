@@ -0,0 +1,297 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/backoff"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+// AllTypes subscribes to the events of every type.
+const AllTypes = "*"
+
+// CleanupJobKind is the kind of the job removing the dispatched events older than the retention.
+const CleanupJobKind = "events.cleanup"
+
+// releaseTimeout bounds giving the undelivered events back to the outbox once the deliveries are cancelled.
+const releaseTimeout = 5 * time.Second
+
+// Handler reacts to an event in-process. An error makes the dispatcher deliver the event again later,
+// to every subscriber and sink.
+type Handler func(ctx context.Context, event *Event) error
+
+// Sink delivers events outside the process, e.g. to a message broker.
+type Sink interface {
+	Deliver(ctx context.Context, event *Event) error
+}
+
+// Dispatcher delivers the events of the outbox to the subscribers and sinks, the events of a batch
+// are leased so that several instances can dispatch at once. Delivery is retried with a growing backoff
+// until the events run out of attempts, so events of a subject aren't guaranteed to arrive in order
+// when some of them fail.
+type Dispatcher struct {
+	db          *pgxpool.Pool
+	cfg         config.EventsConfig
+	subscribers map[string][]Handler
+	sinks       []Sink
+
+	stop    context.CancelFunc
+	abort   context.CancelFunc
+	done    chan struct{}
+	stopped sync.Once
+}
+
+func NewDispatcher(db *pgxpool.Pool, cfg config.EventsConfig) *Dispatcher {
+	return &Dispatcher{
+		db:          db,
+		cfg:         cfg,
+		subscribers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe adds a handler of the events of the type, or of all of them with AllTypes.
+// All the subscribers must be added before the dispatcher is started.
+func (d *Dispatcher) Subscribe(eventType string, fn Handler) {
+	d.subscribers[eventType] = append(d.subscribers[eventType], fn)
+}
+
+func (d *Dispatcher) AddSink(sink Sink) {
+	d.sinks = append(d.sinks, sink)
+}
+
+func (d *Dispatcher) Start() {
+	pollCtx, stop := context.WithCancel(context.Background())
+	deliveryCtx, abort := context.WithCancel(context.Background())
+	d.stop, d.abort = stop, abort
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+		d.run(pollCtx, deliveryCtx)
+	}()
+}
+
+// Shutdown stops polling the outbox and waits for the current batch to be delivered. If the context is done first,
+// the deliveries are cancelled, the events stay in the outbox.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	if d.stop == nil {
+		return nil
+	}
+
+	d.stopped.Do(d.stop)
+	select {
+	case <-d.done:
+		d.abort()
+		return nil
+	case <-ctx.Done():
+		d.abort()
+		<-d.done
+		return ctx.Err()
+	}
+}
+
+// Cleanup removes the dispatched events older than the retention, it runs as a background job.
+// Dead letters are kept.
+func (d *Dispatcher) Cleanup(ctx context.Context, _ json.RawMessage) error {
+	tag, err := d.db.Exec(ctx,
+		"DELETE FROM outbox_events WHERE dispatched_at < NOW() - make_interval(secs => $1)",
+		d.cfg.Retention.Seconds())
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	log.FromContext(ctx).Info("dispatched events cleaned up", "count", tag.RowsAffected())
+	return nil
+}
+
+func (d *Dispatcher) run(pollCtx, deliveryCtx context.Context) {
+	for {
+		n, err := d.dispatchBatch(deliveryCtx)
+		if err != nil && deliveryCtx.Err() == nil {
+			log.FromContext(deliveryCtx).Error("dispatch events", "err", err)
+		}
+
+		if n == d.cfg.BatchSize && err == nil {
+			select {
+			case <-pollCtx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-pollCtx.Done():
+			return
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// dispatchBatch claims the next batch of pending events and delivers them, it returns the number of events in it.
+// The batch is claimed in a transaction of its own, so that no rows are locked while the events are delivered.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	batch, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, p := range batch {
+		err = d.deliver(ctx, &p.Event)
+		if ctx.Err() != nil {
+			// the rest of the batch is released right away rather than once the lease expires
+			return len(batch), d.release(batch[i:])
+		}
+
+		if err != nil {
+			err = d.fail(ctx, p, err)
+		} else {
+			err = d.complete(ctx, p)
+		}
+		if err != nil {
+			return len(batch), err
+		}
+	}
+	return len(batch), nil
+}
+
+// claimed is an event leased for a delivery attempt, the attempt number identifies the claim.
+type claimed struct {
+	Event
+	attempt int
+}
+
+// claim leases the next batch of pending events to this dispatcher. Events locked by other dispatchers
+// are skipped, and a leased event is claimed again only after the lease expires.
+func (d *Dispatcher) claim(ctx context.Context) ([]*claimed, error) {
+	queryString := `
+	WITH claimed AS (
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE dispatched_at IS NULL and failed_at IS NULL and next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, type, subject_id, payload, created_at, attempts
+	)
+	SELECT * FROM claimed ORDER BY id`
+
+	rows, err := d.db.Query(ctx, queryString, d.cfg.BatchSize, d.cfg.LeaseTimeout.Seconds())
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*claimed, error) {
+		var c claimed
+		err := row.Scan(&c.ID, &c.Type, &c.SubjectID, &c.Payload, &c.CreatedAt, &c.attempt)
+		return &c, err
+	})
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return batch, nil
+}
+
+// complete marks the event as dispatched, unless its lease was lost and it has been claimed again.
+func (d *Dispatcher) complete(ctx context.Context, c *claimed) error {
+	tag, err := d.db.Exec(ctx, `
+	UPDATE outbox_events SET dispatched_at = NOW(), last_error = NULL
+	WHERE id = $1 and attempts = $2 and dispatched_at IS NULL`,
+		c.ID, c.attempt)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if tag.RowsAffected() == 0 {
+		log.FromContext(ctx).Warn("event delivered after its lease was lost", "id", c.ID, "type", c.Type)
+	}
+	return nil
+}
+
+// fail schedules the next delivery of the event after the backoff, or sets it aside as a dead letter
+// once it has run out of attempts.
+func (d *Dispatcher) fail(ctx context.Context, c *claimed, deliveryErr error) error {
+	var deadLetter bool
+	err := d.db.QueryRow(ctx, `
+	UPDATE outbox_events
+	SET next_attempt_at = NOW() + make_interval(secs => $3),
+		last_error = $4,
+		failed_at = CASE WHEN attempts >= $5 THEN NOW() END
+	WHERE id = $1 and attempts = $2 and dispatched_at IS NULL
+	RETURNING failed_at IS NOT NULL`,
+		c.ID, c.attempt, d.backoff(c.attempt).Seconds(), deliveryErr.Error(), d.cfg.MaxAttempts).
+		Scan(&deadLetter)
+	if dbx.IsNoRows(err) {
+		log.FromContext(ctx).Warn("event delivery failed after its lease was lost", "id", c.ID, "type", c.Type, "err", deliveryErr)
+		return nil
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	logger := log.FromContext(ctx).With("id", c.ID, "type", c.Type, "attempt", c.attempt, "err", deliveryErr)
+	if deadLetter {
+		logger.Error("event delivery failed, no attempts left")
+	} else {
+		logger.Warn("event delivery failed")
+	}
+	return nil
+}
+
+// release gives the events back to the outbox without using up their attempts, e.g. when the dispatcher is stopped.
+func (d *Dispatcher) release(batch []*claimed) error {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	ids := slices.Map(batch, func(c *claimed) int64 { return c.ID })
+	attempts := slices.Map(batch, func(c *claimed) int { return c.attempt })
+	_, err := d.db.Exec(ctx, `
+	UPDATE outbox_events e
+	SET attempts = e.attempts - 1, next_attempt_at = NOW()
+	FROM unnest($1::bigint[], $2::int[]) AS c(id, attempts)
+	WHERE e.id = c.id and e.attempts = c.attempts and e.dispatched_at IS NULL`,
+		ids, attempts)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event *Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panicked: %v", r)
+		}
+	}()
+
+	var errs []error
+	for _, eventType := range []string{event.Type, AllTypes} {
+		for _, fn := range d.subscribers[eventType] {
+			if err = fn(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, sink := range d.sinks {
+		if err = sink.Deliver(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	return backoff.Exponential(d.cfg.BaseBackoff, d.cfg.MaxBackoff, attempts)
+}
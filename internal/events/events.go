@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+)
+
+// Types of domain events, named as subject.action. Deleted entities can be restored from the trash
+// until they are purged for good.
+const (
+	MovieCreated    = "movie.created"
+	MovieUpdated    = "movie.updated"
+	MovieDeleted    = "movie.deleted"
+	MovieRestored   = "movie.restored"
+	MoviePurged     = "movie.purged"
+	StarCreated     = "star.created"
+	StarUpdated     = "star.updated"
+	StarDeleted     = "star.deleted"
+	StarRestored    = "star.restored"
+	StarPurged      = "star.purged"
+	ReviewPosted    = "review.posted"
+	ReviewUpdated   = "review.updated"
+	ReviewDeleted   = "review.deleted"
+	ReviewRestored  = "review.restored"
+	ReviewPurged    = "review.purged"
+	UserRegistered  = "user.registered"
+	UserRoleChanged = "user.role_changed"
+	UserDeleted     = "user.deleted"
+	UserRestored    = "user.restored"
+	UserPurged      = "user.purged"
+)
+
+// Types are all the types of domain events.
+var Types = []string{
+	MovieCreated, MovieUpdated, MovieDeleted, MovieRestored, MoviePurged,
+	StarCreated, StarUpdated, StarDeleted, StarRestored, StarPurged,
+	ReviewPosted, ReviewUpdated, ReviewDeleted, ReviewRestored, ReviewPurged,
+	UserRegistered, UserRoleChanged, UserDeleted, UserRestored, UserPurged,
+}
+
+// Event is a change that happened to a subject, e.g. a movie. Events are delivered at least once,
+// so consumers should deduplicate them by ID.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	SubjectID int             `json:"subject_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Payloads of the events, fields that don't apply to an event are left out.
+
+type Movie struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	ParentID   *int   `json:"parent_id,omitempty"`
+	MergedInto *int   `json:"merged_into,omitempty"`
+}
+
+type Star struct {
+	ID         int    `json:"id"`
+	FirstName  string `json:"first_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+	MergedInto *int   `json:"merged_into,omitempty"`
+}
+
+type Review struct {
+	ID      int `json:"id"`
+	MovieID int `json:"movie_id"`
+	UserID  int `json:"user_id,omitempty"`
+	Rating  int `json:"rating,omitempty"`
+}
+
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+}
+
+// Record writes the event to the outbox. It must be called with the transaction making the change,
+// so that the event is dispatched only if the change is committed.
+func Record(ctx context.Context, q dbx.Queryable, eventType string, subjectID int, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	_, err = q.Exec(ctx, "INSERT INTO outbox_events (type, subject_id, payload) VALUES ($1, $2, $3)", eventType, subjectID, data)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
+
+// Subscription receives the updates of a movie, or of all of them. Its channel is closed when the broker
+// shuts down or when the subscriber falls behind by more than the buffer size.
+type Subscription struct {
+	C       <-chan *Update
+	c       chan *Update
+	movieID *int
+}
+
+// Broker listens to the updates notified by any instance and fans them out to the subscriptions of this one.
+// It uses a connection of its own, outside the pool, as the connection keeps listening as long as it is open.
+type Broker struct {
+	db  *pgxpool.Pool
+	cfg config.LiveConfig
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+
+	stop    context.CancelFunc
+	done    chan struct{}
+	stopped sync.Once
+}
+
+func NewBroker(db *pgxpool.Pool, cfg config.LiveConfig) *Broker {
+	return &Broker{
+		db:   db,
+		cfg:  cfg,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to the updates of the movie, or to all the updates if movieID is nil.
+func (b *Broker) Subscribe(movieID *int) *Subscription {
+	c := make(chan *Update, b.cfg.BufferSize)
+	sub := &Subscription{C: c, c: c, movieID: movieID}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(c)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
+
+func (b *Broker) Start() {
+	ctx, stop := context.WithCancel(context.Background())
+	b.stop = stop
+	b.done = make(chan struct{})
+
+	go func() {
+		defer close(b.done)
+		b.run(ctx)
+	}()
+}
+
+// Shutdown stops listening and closes all the subscriptions, so that the streams end.
+func (b *Broker) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+	b.mu.Unlock()
+
+	if b.stop == nil {
+		return nil
+	}
+
+	b.stopped.Do(b.stop)
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run listens until the context is done, reconnecting after the delay whenever the connection is lost.
+func (b *Broker) run(ctx context.Context) {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.FromContext(ctx).Error("listen to live updates", "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.cfg.ReconnectDelay):
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, b.db.Config().ConnConfig.Copy())
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		var update Update
+		if err = json.Unmarshal([]byte(notification.Payload), &update); err != nil {
+			log.FromContext(ctx).Warn("invalid live update", "err", err)
+			continue
+		}
+		b.publish(ctx, &update)
+	}
+}
+
+// publish sends the update to the matching subscriptions without blocking,
+// the subscriptions whose buffers are full are closed.
+func (b *Broker) publish(ctx context.Context, update *Update) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		if sub.movieID != nil && *sub.movieID != update.MovieID {
+			continue
+		}
+
+		select {
+		case sub.c <- update:
+		default:
+			log.FromContext(ctx).Warn("live subscriber fell behind, closing its stream")
+			delete(b.subs, sub)
+			close(sub.c)
+		}
+	}
+}
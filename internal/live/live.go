@@ -0,0 +1,58 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+)
+
+// channel is the Postgres channel the updates are notified on, every instance listens to it.
+const channel = "live_updates"
+
+// Types of live updates.
+const (
+	ReviewCreated = "review.created"
+	ReviewUpdated = "review.updated"
+	ReviewDeleted = "review.deleted"
+	RatingChanged = "rating.changed"
+)
+
+// Update is a change of a movie pushed to the clients as it happens. Unlike domain events, updates aren't stored,
+// a client missing some of them, e.g. while reconnecting, should refetch the movie.
+type Update struct {
+	Type    string          `json:"type"`
+	MovieID int             `json:"movie_id"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Data of the updates.
+
+type Review struct {
+	ID     int `json:"id"`
+	UserID int `json:"user_id"`
+	Rating int `json:"rating,omitempty"`
+}
+
+type Rating struct {
+	AvgRating *float64 `json:"avg_rating"`
+}
+
+// Notify sends the update to the listening instances. Called with a transaction, the update is sent
+// only when it is committed.
+func Notify(ctx context.Context, q dbx.Queryable, updateType string, movieID int, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	payload, err := json.Marshal(&Update{Type: updateType, MovieID: movieID, Data: raw})
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = q.Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(payload)); err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
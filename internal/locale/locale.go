@@ -0,0 +1,104 @@
+package locale
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	AcceptLanguageHeader  = "Accept-Language"
+	ContentLanguageHeader = "Content-Language"
+)
+
+// FromRequest returns locales from the Accept-Language header of the request ordered by preference.
+func FromRequest(r *http.Request) []string {
+	return ParseAcceptLanguage(r.Header.Get(AcceptLanguageHeader))
+}
+
+// ParseAcceptLanguage parses an Accept-Language header value, e.g. "de-AT,de;q=0.9,en;q=0.5",
+// into normalized locales ordered by their quality values. Wildcards and malformed entries are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		items = append(items, weighted{locale: Normalize(tag), quality: quality})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].quality > items[j].quality
+	})
+
+	locales := make([]string, 0, len(items))
+	for _, item := range items {
+		locales = append(locales, item.locale)
+	}
+	return locales
+}
+
+// Normalize converts a locale to the canonical "ll" or "ll-RR" form: "PT_br" becomes "pt-BR".
+func Normalize(locale string) string {
+	lang, region, ok := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// Match returns the available locale that fits the preferred locales best. Preferences are tried in order;
+// for each of them an exact match wins over its base language, which wins over any regional variant of that language.
+func Match(preferred, available []string) (string, bool) {
+	for _, p := range preferred {
+		base := Language(p)
+		var baseMatch, variantMatch string
+
+		for _, a := range available {
+			switch {
+			case a == p:
+				return a, true
+			case a == base:
+				baseMatch = a
+			case variantMatch == "" && Language(a) == base:
+				variantMatch = a
+			}
+		}
+
+		if baseMatch != "" {
+			return baseMatch, true
+		}
+		if variantMatch != "" {
+			return variantMatch, true
+		}
+	}
+
+	return "", false
+}
+
+// Language returns the language part of a locale: "pt" for "pt-BR".
+func Language(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
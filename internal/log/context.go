@@ -26,14 +26,14 @@ func SetupLogger(isLocal bool, level string) (*slog.Logger, error) {
 	return slog.New(handler), nil
 }
 
-var contextKey = struct{}{}
+type contextKey struct{}
 
 func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
-	return context.WithValue(ctx, contextKey, logger)
+	return context.WithValue(ctx, contextKey{}, logger)
 }
 
 func FromContext(ctx context.Context) *slog.Logger {
-	if logger, ok := ctx.Value(contextKey).(*slog.Logger); ok {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
 		return logger
 	}
 	return slog.Default()
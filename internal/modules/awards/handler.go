@@ -0,0 +1,181 @@
+package awards
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+)
+
+type Handler struct {
+	Service  *Service
+	reqGroup singleflight.Group
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		Service: service,
+	}
+}
+
+func (h *Handler) GetCeremonies(c echo.Context) error {
+	res, err, _ := h.reqGroup.Do(c.Request().RequestURI, func() (any, error) {
+		return h.Service.GetCeremonies(c.Request().Context())
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetCeremonyByID(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetCeremonyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	ceremony, err := h.Service.GetCeremonyByID(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ceremony)
+}
+
+func (h *Handler) CreateCeremony(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateCeremonyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	ceremony := &Ceremony{
+		Name:   req.Name,
+		Year:   req.Year,
+		HeldOn: req.HeldOn,
+	}
+	err = h.Service.CreateCeremony(c.Request().Context(), ceremony)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, ceremony)
+}
+
+func (h *Handler) UpdateCeremony(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UpdateCeremonyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.UpdateCeremony(c.Request().Context(), &Ceremony{
+		ID:     req.ID,
+		Name:   req.Name,
+		Year:   req.Year,
+		HeldOn: req.HeldOn,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteCeremony(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteCeremonyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.DeleteCeremony(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) GetCategories(c echo.Context) error {
+	categories, err := h.Service.GetCategories(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+func (h *Handler) CreateCategory(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateAwardCategoryRequest](c)
+	if err != nil {
+		return err
+	}
+
+	category := &Category{Name: req.Name}
+	err = h.Service.CreateCategory(c.Request().Context(), category)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, category)
+}
+
+func (h *Handler) CreateNomination(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateNominationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	nomination, err := h.Service.CreateNomination(c.Request().Context(),
+		newNomination(req.CeremonyID, 0, req.CategoryID, req.MovieID, req.StarID, req.Winner))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, nomination)
+}
+
+func (h *Handler) UpdateNomination(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UpdateNominationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.UpdateNomination(c.Request().Context(),
+		newNomination(req.CeremonyID, req.NominationID, req.CategoryID, req.MovieID, req.StarID, req.Winner))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteNomination(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteNominationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.DeleteNomination(c.Request().Context(), req.CeremonyID, req.NominationID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func newNomination(ceremonyID, id, categoryID, movieID int, starID *int, winner bool) *Nomination {
+	nomination := &Nomination{
+		ID:       id,
+		Ceremony: &Ceremony{ID: ceremonyID},
+		Category: &Category{ID: categoryID},
+		Movie:    &NominatedMovie{ID: movieID},
+		Winner:   winner,
+	}
+	if starID != nil {
+		nomination.Star = &NominatedStar{ID: *starID}
+	}
+	return nomination
+}
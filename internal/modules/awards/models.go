@@ -0,0 +1,65 @@
+package awards
+
+import "time"
+
+// Ceremony is a single edition of an award, e.g. the Academy Awards of 1978.
+type Ceremony struct {
+	ID     int        `json:"id"`
+	Name   string     `json:"name"`
+	Year   int        `json:"year"`
+	HeldOn *time.Time `json:"held_on,omitempty"`
+}
+
+type CeremonyDetails struct {
+	Ceremony
+	Nominations []*Nomination `json:"nominations"`
+}
+
+type Category struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Nomination struct {
+	ID       int             `json:"id"`
+	Ceremony *Ceremony       `json:"ceremony"`
+	Category *Category       `json:"category"`
+	Movie    *NominatedMovie `json:"movie"`
+	Star     *NominatedStar  `json:"star,omitempty"`
+	Winner   bool            `json:"winner"`
+}
+
+type NominatedMovie struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type NominatedStar struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// Summary lists the nominations of a movie or a star, the latest ceremonies first.
+type Summary struct {
+	Wins        int           `json:"wins"`
+	Nominations int           `json:"nominations"`
+	Items       []*Nomination `json:"items"`
+}
+
+func newSummary(nominations []*Nomination) *Summary {
+	if len(nominations) == 0 {
+		return nil
+	}
+
+	summary := &Summary{
+		Nominations: len(nominations),
+		Items:       nominations,
+	}
+	for _, nomination := range nominations {
+		if nomination.Winner {
+			summary.Wins++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,345 @@
+package awards
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+)
+
+const selectNominations = `
+	SELECT n.id, c.id, c.name, c.year, c.held_on, cat.id, cat.name, m.id, m.title, s.id, s.first_name, s.last_name, n.winner
+	FROM award_nominations n
+	INNER JOIN award_ceremonies c on c.id = n.ceremony_id
+	INNER JOIN award_categories cat on cat.id = n.category_id
+	INNER JOIN movies m on m.id = n.movie_id
+	LEFT JOIN stars s on s.id = n.star_id`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetCeremonies(ctx context.Context) ([]*Ceremony, error) {
+	queryString := "SELECT id, name, year, held_on FROM award_ceremonies ORDER BY year DESC, name"
+	rows, err := r.db.Query(ctx, queryString)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	ceremonies := []*Ceremony{}
+	for rows.Next() {
+		var ceremony Ceremony
+		err = rows.Scan(&ceremony.ID, &ceremony.Name, &ceremony.Year, &ceremony.HeldOn)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		ceremonies = append(ceremonies, &ceremony)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return ceremonies, nil
+}
+
+func (r *Repository) GetCeremonyByID(ctx context.Context, id int) (*CeremonyDetails, error) {
+	queryString := "SELECT id, name, year, held_on FROM award_ceremonies WHERE id = $1"
+
+	var ceremony CeremonyDetails
+	err := r.db.QueryRow(ctx, queryString, id).Scan(&ceremony.ID, &ceremony.Name, &ceremony.Year, &ceremony.HeldOn)
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("ceremony", "id", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	ceremony.Nominations, err = r.getNominations(ctx,
+		selectNominations+" WHERE n.ceremony_id = $1 and m.deleted_at IS NULL ORDER BY cat.name, n.winner DESC, n.id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ceremony, nil
+}
+
+func (r *Repository) CreateCeremony(ctx context.Context, ceremony *Ceremony) error {
+	queryString := "INSERT INTO award_ceremonies (name, year, held_on) VALUES ($1, $2, $3) RETURNING id"
+	err := r.db.QueryRow(ctx, queryString, ceremony.Name, ceremony.Year, ceremony.HeldOn).Scan(&ceremony.ID)
+	if dbx.IsUniqueViolation(err, "name_year") {
+		return apperrors.AlreadyExists(ceremony.Name+" ceremony", "year", ceremony.Year)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateCeremony(ctx context.Context, ceremony *Ceremony) error {
+	queryString := "UPDATE award_ceremonies SET name = $2, year = $3, held_on = $4 WHERE id = $1"
+	cmdTag, err := r.db.Exec(ctx, queryString, ceremony.ID, ceremony.Name, ceremony.Year, ceremony.HeldOn)
+	if dbx.IsUniqueViolation(err, "name_year") {
+		return apperrors.AlreadyExists(ceremony.Name+" ceremony", "year", ceremony.Year)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("ceremony", "id", ceremony.ID)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteCeremony(ctx context.Context, id int) error {
+	cmdTag, err := r.db.Exec(ctx, "DELETE FROM award_ceremonies WHERE id = $1", id)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("ceremony", "id", id)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetCategories(ctx context.Context) ([]*Category, error) {
+	rows, err := r.db.Query(ctx, "SELECT id, name FROM award_categories ORDER BY name")
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	categories := []*Category{}
+	for rows.Next() {
+		var category Category
+		err = rows.Scan(&category.ID, &category.Name)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		categories = append(categories, &category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return categories, nil
+}
+
+func (r *Repository) CreateCategory(ctx context.Context, category *Category) error {
+	err := r.db.QueryRow(ctx, "INSERT INTO award_categories (name) VALUES ($1) RETURNING id", category.Name).
+		Scan(&category.ID)
+	if dbx.IsUniqueViolation(err, "name") {
+		return apperrors.AlreadyExists("award category", "name", category.Name)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetNominationByID(ctx context.Context, ceremonyID, id int) (*Nomination, error) {
+	nominations, err := r.getNominations(ctx, selectNominations+" WHERE n.ceremony_id = $1 and n.id = $2", ceremonyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(nominations) == 0 {
+		return nil, apperrors.NotFound("nomination", "id", id)
+	}
+
+	return nominations[0], nil
+}
+
+func (r *Repository) CreateNomination(ctx context.Context, nomination *Nomination) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.checkNominees(ctx, nomination)
+		if err != nil {
+			return err
+		}
+
+		queryString := `
+		INSERT INTO award_nominations (ceremony_id, category_id, movie_id, star_id, winner)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+		err = tx.QueryRow(ctx, queryString,
+			nomination.Ceremony.ID,
+			nomination.Category.ID,
+			nomination.Movie.ID,
+			starID(nomination),
+			nomination.Winner,
+		).Scan(&nomination.ID)
+
+		return nominationError(err, nomination)
+	})
+}
+
+func (r *Repository) UpdateNomination(ctx context.Context, nomination *Nomination) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.checkNominees(ctx, nomination)
+		if err != nil {
+			return err
+		}
+
+		queryString := `
+		UPDATE award_nominations
+		SET category_id = $3, movie_id = $4, star_id = $5, winner = $6
+		WHERE ceremony_id = $1 and id = $2`
+		cmdTag, err := tx.Exec(ctx, queryString,
+			nomination.Ceremony.ID,
+			nomination.ID,
+			nomination.Category.ID,
+			nomination.Movie.ID,
+			starID(nomination),
+			nomination.Winner,
+		)
+		if err != nil {
+			return nominationError(err, nomination)
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("nomination", "id", nomination.ID)
+		}
+
+		return nil
+	})
+}
+
+func (r *Repository) DeleteNomination(ctx context.Context, ceremonyID, id int) error {
+	cmdTag, err := r.db.Exec(ctx, "DELETE FROM award_nominations WHERE ceremony_id = $1 and id = $2", ceremonyID, id)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("nomination", "id", id)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetSummaryByMovieID(ctx context.Context, movieID int) (*Summary, error) {
+	nominations, err := r.getNominations(ctx,
+		selectNominations+" WHERE n.movie_id = $1 ORDER BY c.year DESC, c.name, cat.name, n.id", movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSummary(nominations), nil
+}
+
+func (r *Repository) GetSummaryByStarID(ctx context.Context, starID int) (*Summary, error) {
+	nominations, err := r.getNominations(ctx,
+		selectNominations+" WHERE n.star_id = $1 and m.deleted_at IS NULL ORDER BY c.year DESC, c.name, cat.name, n.id", starID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSummary(nominations), nil
+}
+
+// checkNominees makes sure the nominated movie and star exist, foreign keys alone let deleted ones through.
+func (r *Repository) checkNominees(ctx context.Context, nomination *Nomination) error {
+	queryString := `
+	SELECT
+		EXISTS (SELECT 1 FROM movies WHERE id = $1 and deleted_at IS NULL),
+		$2::integer IS NULL or EXISTS (SELECT 1 FROM stars WHERE id = $2 and deleted_at IS NULL)`
+
+	var movieExists, starExists bool
+	q := dbx.FromContext(ctx, r.db)
+	err := q.QueryRow(ctx, queryString, nomination.Movie.ID, starID(nomination)).Scan(&movieExists, &starExists)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if !movieExists {
+		return apperrors.NotFound("movie", "id", nomination.Movie.ID)
+	}
+	if !starExists {
+		return apperrors.NotFound("star", "id", nomination.Star.ID)
+	}
+
+	return nil
+}
+
+func (r *Repository) getNominations(ctx context.Context, queryString string, args ...any) ([]*Nomination, error) {
+	rows, err := r.db.Query(ctx, queryString, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	nominations := []*Nomination{}
+	for rows.Next() {
+		nomination := Nomination{
+			Ceremony: &Ceremony{},
+			Category: &Category{},
+			Movie:    &NominatedMovie{},
+		}
+		var star struct {
+			ID        *int
+			FirstName *string
+			LastName  *string
+		}
+		err = rows.Scan(
+			&nomination.ID,
+			&nomination.Ceremony.ID,
+			&nomination.Ceremony.Name,
+			&nomination.Ceremony.Year,
+			&nomination.Ceremony.HeldOn,
+			&nomination.Category.ID,
+			&nomination.Category.Name,
+			&nomination.Movie.ID,
+			&nomination.Movie.Title,
+			&star.ID,
+			&star.FirstName,
+			&star.LastName,
+			&nomination.Winner,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		if star.ID != nil {
+			nomination.Star = &NominatedStar{
+				ID:        *star.ID,
+				FirstName: *star.FirstName,
+				LastName:  *star.LastName,
+			}
+		}
+		nominations = append(nominations, &nomination)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return nominations, nil
+}
+
+func nominationError(err error, nomination *Nomination) error {
+	switch {
+	case err == nil:
+		return nil
+	case dbx.IsForeignKeyViolation(err, "ceremony_id"):
+		return apperrors.NotFound("ceremony", "id", nomination.Ceremony.ID)
+	case dbx.IsForeignKeyViolation(err, "category_id"):
+		return apperrors.NotFound("award category", "id", nomination.Category.ID)
+	case dbx.IsUniqueViolation(err, "nominee"):
+		return apperrors.AlreadyExists("nomination", "movie_id", nomination.Movie.ID)
+	default:
+		return apperrors.Internal(err)
+	}
+}
+
+func starID(nomination *Nomination) *int {
+	if nomination.Star == nil {
+		return nil
+	}
+	return &nomination.Star.ID
+}
@@ -0,0 +1,133 @@
+package awards
+
+import (
+	"context"
+
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+func (s *Service) GetCeremonies(ctx context.Context) ([]*Ceremony, error) {
+	return s.repo.GetCeremonies(ctx)
+}
+
+func (s *Service) GetCeremonyByID(ctx context.Context, id int) (*CeremonyDetails, error) {
+	return s.repo.GetCeremonyByID(ctx, id)
+}
+
+func (s *Service) CreateCeremony(ctx context.Context, ceremony *Ceremony) error {
+	err := s.repo.CreateCeremony(ctx, ceremony)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("award ceremony created",
+		"ceremony_id", ceremony.ID,
+		"name", ceremony.Name,
+		"year", ceremony.Year)
+
+	return nil
+}
+
+func (s *Service) UpdateCeremony(ctx context.Context, ceremony *Ceremony) error {
+	err := s.repo.UpdateCeremony(ctx, ceremony)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("award ceremony updated",
+		"ceremony_id", ceremony.ID)
+
+	return nil
+}
+
+func (s *Service) DeleteCeremony(ctx context.Context, id int) error {
+	err := s.repo.DeleteCeremony(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("award ceremony deleted",
+		"ceremony_id", id)
+
+	return nil
+}
+
+func (s *Service) GetCategories(ctx context.Context) ([]*Category, error) {
+	return s.repo.GetCategories(ctx)
+}
+
+func (s *Service) CreateCategory(ctx context.Context, category *Category) error {
+	err := s.repo.CreateCategory(ctx, category)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("award category created",
+		"name", category.Name)
+
+	return nil
+}
+
+func (s *Service) CreateNomination(ctx context.Context, nomination *Nomination) (*Nomination, error) {
+	err := s.repo.CreateNomination(ctx, nomination)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("nomination created",
+		"ceremony_id", nomination.Ceremony.ID,
+		"nomination_id", nomination.ID)
+
+	return s.repo.GetNominationByID(ctx, nomination.Ceremony.ID, nomination.ID)
+}
+
+func (s *Service) UpdateNomination(ctx context.Context, nomination *Nomination) error {
+	err := s.repo.UpdateNomination(ctx, nomination)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("nomination updated",
+		"ceremony_id", nomination.Ceremony.ID,
+		"nomination_id", nomination.ID)
+
+	return nil
+}
+
+func (s *Service) DeleteNomination(ctx context.Context, ceremonyID, id int) error {
+	err := s.repo.DeleteNomination(ctx, ceremonyID, id)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("nomination deleted",
+		"ceremony_id", ceremonyID,
+		"nomination_id", id)
+
+	return nil
+}
+
+func (s *Service) GetSummaryByMovieID(ctx context.Context, movieID int) (*Summary, error) {
+	return s.repo.GetSummaryByMovieID(ctx, movieID)
+}
+
+func (s *Service) GetSummaryByStarID(ctx context.Context, starID int) (*Summary, error) {
+	return s.repo.GetSummaryByStarID(ctx, starID)
+}
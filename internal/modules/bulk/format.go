@@ -0,0 +1,409 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+const (
+	csvListSeparator = "|"
+	dateLayout       = "2006-01-02"
+	maxLineSize      = 1 << 20
+)
+
+// record is a row of bulk data of one of the entities.
+type record interface {
+	fromCSV(row *csvRow)
+	toCSV() []string
+}
+
+var (
+	_ record = (*GenreRecord)(nil)
+	_ record = (*StarRecord)(nil)
+	_ record = (*MovieRecord)(nil)
+	_ record = (*CreditRecord)(nil)
+)
+
+// columns are the CSV columns of the entities, in the order they are exported.
+var columns = map[string][]string{
+	EntityGenres:  {"name", "parent"},
+	EntityStars:   {"external_ids", "first_name", "middle_name", "last_name", "birth_date", "birth_place", "death_date", "bio"},
+	EntityMovies:  {"external_ids", "kind", "title", "release_date", "description", "language", "tagline", "runtime", "countries", "spoken_languages", "genres"},
+	EntityCredits: {"movie", "star", "role", "characters", "job", "uncredited", "voice", "order_no"},
+}
+
+func newRecord(entity string) record {
+	switch entity {
+	case EntityGenres:
+		return &GenreRecord{}
+	case EntityStars:
+		return &StarRecord{}
+	case EntityMovies:
+		return &MovieRecord{}
+	default:
+		return &CreditRecord{}
+	}
+}
+
+// reader reads records one by one. A malformed row doesn't stop the reading, its error is returned
+// as the row error and the next row can be read.
+type reader interface {
+	Next() (rec record, rowErr error, err error)
+}
+
+func newReader(format, entity string, r io.Reader) (reader, error) {
+	if format == FormatCSV {
+		cr := csv.NewReader(r)
+		cr.ReuseRecord = true
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("read csv header: %w", err)
+		}
+		for _, column := range header {
+			if !slices.Contains(columns[entity], column) {
+				return nil, fmt.Errorf("unknown csv column %q", column)
+			}
+		}
+
+		return &csvReader{entity: entity, r: cr, header: append([]string{}, header...)}, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &ndjsonReader{entity: entity, scanner: scanner}, nil
+}
+
+type ndjsonReader struct {
+	entity  string
+	scanner *bufio.Scanner
+}
+
+func (r *ndjsonReader) Next() (record, error, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		rec := newRecord(r.entity)
+		decoder := json.NewDecoder(strings.NewReader(string(line)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(rec); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err), nil
+		}
+		return rec, nil, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, io.EOF
+}
+
+type csvReader struct {
+	entity string
+	r      *csv.Reader
+	header []string
+}
+
+func (r *csvReader) Next() (record, error, error) {
+	values, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("invalid csv: %w", parseErr.Err), nil
+		}
+		return nil, nil, err
+	}
+
+	row := &csvRow{values: make(map[string]string, len(values))}
+	for i, value := range values {
+		row.values[r.header[i]] = value
+	}
+
+	rec := newRecord(r.entity)
+	rec.fromCSV(row)
+	if row.err != nil {
+		return nil, row.err, nil
+	}
+	return rec, nil, nil
+}
+
+// writer writes records of an export.
+type writer interface {
+	Write(rec record) error
+	Flush() error
+}
+
+func newWriter(format, entity string, w io.Writer) (writer, error) {
+	if format == FormatCSV {
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(columns[entity]); err != nil {
+			return nil, err
+		}
+		return &csvRecordWriter{w: csvWriter}, nil
+	}
+
+	return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(rec record) error {
+	return w.encoder.Encode(rec)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+type csvRecordWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvRecordWriter) Write(rec record) error {
+	return w.w.Write(rec.toCSV())
+}
+
+func (w *csvRecordWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// csvRow converts the cells of a CSV row, the first conversion error is kept in err.
+type csvRow struct {
+	values map[string]string
+	err    error
+}
+
+func (r *csvRow) str(column string) string {
+	return r.values[column]
+}
+
+func (r *csvRow) optStr(column string) *string {
+	if value := r.values[column]; value != "" {
+		return &value
+	}
+	return nil
+}
+
+func (r *csvRow) date(column string) time.Time {
+	value := r.values[column]
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range []string{dateLayout, time.RFC3339} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	r.fail(column, fmt.Errorf("invalid date %q", value))
+	return time.Time{}
+}
+
+func (r *csvRow) optDate(column string) *time.Time {
+	if r.values[column] == "" {
+		return nil
+	}
+	date := r.date(column)
+	return &date
+}
+
+func (r *csvRow) integer(column string) int {
+	value := r.values[column]
+	if value == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail(column, fmt.Errorf("invalid number %q", value))
+	}
+	return n
+}
+
+func (r *csvRow) optInt(column string) *int {
+	if r.values[column] == "" {
+		return nil
+	}
+	n := r.integer(column)
+	return &n
+}
+
+func (r *csvRow) boolean(column string) bool {
+	value := r.values[column]
+	if value == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		r.fail(column, fmt.Errorf("invalid boolean %q", value))
+	}
+	return b
+}
+
+func (r *csvRow) list(column string) []string {
+	if value := r.values[column]; value != "" {
+		return strings.Split(value, csvListSeparator)
+	}
+	return nil
+}
+
+func (r *csvRow) externalIDs(column string) map[string]string {
+	ids := make(map[string]string)
+	for _, ref := range r.list(column) {
+		source, id, err := parseRef(ref)
+		if err != nil {
+			r.fail(column, err)
+			return nil
+		}
+		ids[source] = id
+	}
+	return ids
+}
+
+func (r *csvRow) fail(column string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%s: %w", column, err)
+	}
+}
+
+// parseRef parses a reference to an entity by one of its external ids, e.g. "imdb:tt0076759".
+func parseRef(ref string) (string, string, error) {
+	source, id, ok := strings.Cut(ref, ":")
+	if !ok || source == "" || id == "" {
+		return "", "", fmt.Errorf("invalid external id %q, source:id is expected", ref)
+	}
+	return source, id, nil
+}
+
+func formatExternalIDs(ids map[string]string) string {
+	refs := make([]string, 0, len(ids))
+	for source, id := range ids {
+		refs = append(refs, source+":"+id)
+	}
+	sort.Strings(refs)
+	return strings.Join(refs, csvListSeparator)
+}
+
+func formatOptStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatOptDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+func formatOptInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func (g *GenreRecord) fromCSV(row *csvRow) {
+	g.Name = row.str("name")
+	g.Parent = row.optStr("parent")
+}
+
+func (g *GenreRecord) toCSV() []string {
+	return []string{g.Name, formatOptStr(g.Parent)}
+}
+
+func (s *StarRecord) fromCSV(row *csvRow) {
+	s.ExternalIDs = row.externalIDs("external_ids")
+	s.FirstName = row.str("first_name")
+	s.MiddleName = row.optStr("middle_name")
+	s.LastName = row.str("last_name")
+	s.BirthDate = row.date("birth_date")
+	s.BirthPlace = row.optStr("birth_place")
+	s.DeathDate = row.optDate("death_date")
+	s.Bio = row.optStr("bio")
+}
+
+func (s *StarRecord) toCSV() []string {
+	return []string{
+		formatExternalIDs(s.ExternalIDs),
+		s.FirstName,
+		formatOptStr(s.MiddleName),
+		s.LastName,
+		s.BirthDate.Format(dateLayout),
+		formatOptStr(s.BirthPlace),
+		formatOptDate(s.DeathDate),
+		formatOptStr(s.Bio),
+	}
+}
+
+func (m *MovieRecord) fromCSV(row *csvRow) {
+	m.ExternalIDs = row.externalIDs("external_ids")
+	m.Kind = row.str("kind")
+	m.Title = row.str("title")
+	m.ReleaseDate = row.date("release_date")
+	m.Description = row.str("description")
+	m.Language = row.str("language")
+	m.Tagline = row.optStr("tagline")
+	m.Runtime = row.optInt("runtime")
+	m.Countries = row.list("countries")
+	m.SpokenLanguages = row.list("spoken_languages")
+	m.Genres = row.list("genres")
+}
+
+func (m *MovieRecord) toCSV() []string {
+	return []string{
+		formatExternalIDs(m.ExternalIDs),
+		m.Kind,
+		m.Title,
+		m.ReleaseDate.Format(dateLayout),
+		m.Description,
+		m.Language,
+		formatOptStr(m.Tagline),
+		formatOptInt(m.Runtime),
+		strings.Join(m.Countries, csvListSeparator),
+		strings.Join(m.SpokenLanguages, csvListSeparator),
+		strings.Join(m.Genres, csvListSeparator),
+	}
+}
+
+func (c *CreditRecord) fromCSV(row *csvRow) {
+	c.Movie = row.str("movie")
+	c.Star = row.str("star")
+	c.Role = row.str("role")
+	c.Characters = row.list("characters")
+	c.Job = row.optStr("job")
+	c.Uncredited = row.boolean("uncredited")
+	c.Voice = row.boolean("voice")
+	c.OrderNo = row.integer("order_no")
+}
+
+func (c *CreditRecord) toCSV() []string {
+	return []string{
+		c.Movie,
+		c.Star,
+		c.Role,
+		strings.Join(c.Characters, csvListSeparator),
+		formatOptStr(c.Job),
+		strconv.FormatBool(c.Uncredited),
+		strconv.FormatBool(c.Voice),
+		strconv.Itoa(c.OrderNo),
+	}
+}
@@ -0,0 +1,73 @@
+package bulk
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+)
+
+// Content types of the formats, the format of an import is told by the content type of its body.
+const (
+	MIMEApplicationNDJSON = "application/x-ndjson"
+	MIMETextCSV           = "text/csv"
+)
+
+type Handler struct {
+	Service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		Service: service,
+	}
+}
+
+func (h *Handler) Import(c echo.Context) error {
+	req, err := echox.BindAndValidateParams[contracts.BulkImportRequest](c)
+	if err != nil {
+		return err
+	}
+
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var format string
+	switch mediaType {
+	case MIMEApplicationNDJSON:
+		format = FormatNDJSON
+	case MIMETextCSV:
+		format = FormatCSV
+	default:
+		return apperrors.BadRequest(fmt.Errorf("unsupported content type %q, %s or %s is expected", contentType, MIMEApplicationNDJSON, MIMETextCSV))
+	}
+
+	report, err := h.Service.Import(c.Request().Context(), req.Entity, format, c.Request().Body, req.ChunkSize)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) Export(c echo.Context) error {
+	req, err := echox.BindAndValidateParams[contracts.BulkExportRequest](c)
+	if err != nil {
+		return err
+	}
+
+	format, contentType := FormatNDJSON, MIMEApplicationNDJSON
+	if req.Format == FormatCSV {
+		format, contentType = FormatCSV, MIMETextCSV
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", req.Entity+"."+format))
+	res.WriteHeader(http.StatusOK)
+
+	return h.Service.Export(c.Request().Context(), req.Entity, format, res)
+}
@@ -0,0 +1,84 @@
+package bulk
+
+import (
+	"time"
+)
+
+// Entities which can be imported and exported in bulk.
+const (
+	EntityGenres  = "genres"
+	EntityStars   = "stars"
+	EntityMovies  = "movies"
+	EntityCredits = "credits"
+)
+
+// Formats of bulk data. Lists are separated by "|" in CSV and external ids are written as source:id,
+// e.g. "imdb:tt0076759|tmdb:11".
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// GenreRecord is keyed on the name, which is unique. The parent must be imported before its children.
+type GenreRecord struct {
+	Name   string  `json:"name" validate:"min=1,max=50"`
+	Parent *string `json:"parent,omitempty" validate:"max=50"`
+}
+
+// StarRecord is keyed on its external ids, any of them matching an existing star makes it an update.
+type StarRecord struct {
+	ExternalIDs map[string]string `json:"external_ids" validate:"nonzero,externalids"`
+	FirstName   string            `json:"first_name" validate:"min=1,max=50"`
+	MiddleName  *string           `json:"middle_name,omitempty" validate:"max=50"`
+	LastName    string            `json:"last_name" validate:"max=50"`
+	BirthDate   time.Time         `json:"birth_date" validate:"nonzero"`
+	BirthPlace  *string           `json:"birth_place,omitempty" validate:"max=100"`
+	DeathDate   *time.Time        `json:"death_date,omitempty"`
+	Bio         *string           `json:"bio,omitempty"`
+}
+
+// MovieRecord is keyed on its external ids like StarRecord. Genres are referenced by their names or aliases
+// and replace the current ones. Only top level titles are supported, seasons and episodes are skipped on export.
+type MovieRecord struct {
+	ExternalIDs     map[string]string `json:"external_ids" validate:"nonzero,externalids"`
+	Kind            string            `json:"kind,omitempty" validate:"regexp=^(movie|series)?$"`
+	Title           string            `json:"title" validate:"min=1,max=255"`
+	ReleaseDate     time.Time         `json:"release_date" validate:"nonzero"`
+	Description     string            `json:"description"`
+	Language        string            `json:"language,omitempty" validate:"regexp=^([a-z]{2})?$"`
+	Tagline         *string           `json:"tagline,omitempty" validate:"max=255"`
+	Runtime         *int              `json:"runtime,omitempty" validate:"min=1"`
+	Countries       []string          `json:"countries,omitempty" validate:"codes"`
+	SpokenLanguages []string          `json:"spoken_languages,omitempty" validate:"codes"`
+	Genres          []string          `json:"genres,omitempty"`
+}
+
+// CreditRecord links a movie and a star referenced by one of their external ids, e.g. "imdb:nm0000434".
+// It is keyed on the movie, the star and the role.
+type CreditRecord struct {
+	Movie      string   `json:"movie" validate:"min=1"`
+	Star       string   `json:"star" validate:"min=1"`
+	Role       string   `json:"role" validate:"min=1,max=50"`
+	Characters []string `json:"characters,omitempty"`
+	Job        *string  `json:"job,omitempty" validate:"max=100"`
+	Uncredited bool     `json:"uncredited,omitempty"`
+	Voice      bool     `json:"voice,omitempty"`
+	OrderNo    int      `json:"order_no" validate:"min=0"`
+}
+
+// Report tells how an import went row by row. When it runs in a single transaction,
+// any failed row rolls the whole import back.
+type Report struct {
+	Total      int         `json:"total"`
+	Created    int         `json:"created"`
+	Updated    int         `json:"updated"`
+	Failed     int         `json:"failed"`
+	RolledBack bool        `json:"rolled_back,omitempty"`
+	Errors     []*RowError `json:"errors"`
+}
+
+// RowError is the error of a row, rows are numbered from 1 not counting the CSV header.
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,468 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// InTransaction runs fn in a transaction, the upserts called with its context become part of it.
+func (r *Repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, _ pgx.Tx) error {
+		return fn(ctx)
+	})
+}
+
+// UpsertGenre creates the genre or changes the parent of an existing one, and reports whether it was created.
+func (r *Repository) UpsertGenre(ctx context.Context, rec *GenreRecord) (created bool, err error) {
+	err = r.savepoint(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		var exists bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM genre_aliases WHERE lower(name) = lower($1))", rec.Name).Scan(&exists)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if exists {
+			return apperrors.AlreadyExists("genre alias", "name", rec.Name)
+		}
+
+		var parentID *int
+		if rec.Parent != nil {
+			parentID = new(int)
+			err = tx.QueryRow(ctx, "SELECT id FROM genres WHERE name = $1", *rec.Parent).Scan(parentID)
+			if dbx.IsNoRows(err) {
+				return apperrors.NotFound("genre", "name", *rec.Parent)
+			}
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+
+			queryString := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM genres WHERE id = $1
+				UNION ALL
+				SELECT g.id, g.parent_id FROM genres g INNER JOIN ancestors a on g.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors a INNER JOIN genres g on g.id = a.id WHERE g.name = $2)`
+			var nested bool
+			if err = tx.QueryRow(ctx, queryString, *parentID, rec.Name).Scan(&nested); err != nil {
+				return apperrors.Internal(err)
+			}
+			if nested {
+				return apperrors.BadRequest(errors.New("genre can't be nested into itself"))
+			}
+		}
+
+		queryString := `
+		INSERT INTO genres (name, parent_id) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET parent_id = EXCLUDED.parent_id, version = genres.version + 1
+		RETURNING xmax = 0`
+		err = tx.QueryRow(ctx, queryString, rec.Name, parentID).Scan(&created)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
+	return created, err
+}
+
+// UpsertStar creates the star or updates the one matching any of the external ids, which are added to it.
+func (r *Repository) UpsertStar(ctx context.Context, rec *StarRecord) (created bool, err error) {
+	err = r.savepoint(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		id, err := r.matchExternalIDs(ctx, tx, "star", rec.ExternalIDs)
+		if err != nil {
+			return err
+		}
+
+		if id == nil {
+			created = true
+			id = new(int)
+			queryString := `
+			INSERT INTO stars (first_name, middle_name, last_name, birth_date, birth_place, death_date, bio)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id`
+			err = tx.QueryRow(ctx, queryString,
+				rec.FirstName, rec.MiddleName, rec.LastName, rec.BirthDate, rec.BirthPlace, rec.DeathDate, rec.Bio,
+			).Scan(id)
+		} else {
+			queryString := `
+			UPDATE stars
+			SET first_name = $2, middle_name = $3, last_name = $4, birth_date = $5, birth_place = $6, death_date = $7, bio = $8,
+				version = version + 1
+			WHERE id = $1`
+			_, err = tx.Exec(ctx, queryString,
+				*id, rec.FirstName, rec.MiddleName, rec.LastName, rec.BirthDate, rec.BirthPlace, rec.DeathDate, rec.Bio)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = r.upsertExternalIDs(ctx, tx, "star", *id, rec.ExternalIDs); err != nil {
+			return err
+		}
+
+		eventType := events.StarUpdated
+		if created {
+			eventType = events.StarCreated
+		}
+		return events.Record(ctx, tx, eventType, *id, &events.Star{ID: *id, FirstName: rec.FirstName, LastName: rec.LastName})
+	})
+	return created, err
+}
+
+// UpsertMovie creates the title or updates the one matching any of the external ids, which are added to it.
+// The genres of the title are replaced.
+func (r *Repository) UpsertMovie(ctx context.Context, rec *MovieRecord) (created bool, err error) {
+	err = r.savepoint(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		id, err := r.matchExternalIDs(ctx, tx, "movie", rec.ExternalIDs)
+		if err != nil {
+			return err
+		}
+
+		var kind string
+		if id == nil {
+			created = true
+			id = new(int)
+			queryString := `
+			INSERT INTO movies
+			(title, release_date, description, language, tagline, runtime, countries, spoken_languages, kind)
+			VALUES
+			($1, $2, $3, coalesce(nullif($4, ''), 'en'), $5, $6, $7, $8, coalesce(nullif($9, ''), 'movie'))
+			RETURNING id, kind`
+			err = tx.QueryRow(ctx, queryString,
+				rec.Title, rec.ReleaseDate, rec.Description, rec.Language, rec.Tagline, rec.Runtime,
+				nonNil(rec.Countries), nonNil(rec.SpokenLanguages), rec.Kind,
+			).Scan(id, &kind)
+		} else {
+			queryString := `
+			UPDATE movies
+			SET title = $2, release_date = $3, description = $4, language = coalesce(nullif($5, ''), language),
+				tagline = $6, runtime = $7, countries = $8, spoken_languages = $9, kind = coalesce(nullif($10, ''), kind),
+				version = version + 1
+			WHERE id = $1 and parent_id IS NULL`
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, queryString,
+				*id, rec.Title, rec.ReleaseDate, rec.Description, rec.Language, rec.Tagline, rec.Runtime,
+				nonNil(rec.Countries), nonNil(rec.SpokenLanguages), rec.Kind)
+			if err == nil && tag.RowsAffected() == 0 {
+				return apperrors.BadRequest(fmt.Errorf("title with id %d is a season or an episode and can't be imported", *id))
+			}
+		}
+		if dbx.IsForeignKeyViolation(err, "language") {
+			return apperrors.BadRequest(fmt.Errorf("unsupported language %q", rec.Language))
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = r.replaceGenres(ctx, tx, *id, rec.Genres); err != nil {
+			return err
+		}
+		if err = r.upsertExternalIDs(ctx, tx, "movie", *id, rec.ExternalIDs); err != nil {
+			return err
+		}
+
+		if created {
+			return events.Record(ctx, tx, events.MovieCreated, *id, &events.Movie{ID: *id, Title: rec.Title, Kind: kind})
+		}
+		return events.Record(ctx, tx, events.MovieUpdated, *id, &events.Movie{ID: *id, Title: rec.Title})
+	})
+	return created, err
+}
+
+// UpsertCredit creates the credit or updates the one of the star in the same role in the title.
+func (r *Repository) UpsertCredit(ctx context.Context, rec *CreditRecord) (created bool, err error) {
+	err = r.savepoint(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		movieID, err := r.resolveRef(ctx, tx, "movie", rec.Movie)
+		if err != nil {
+			return err
+		}
+		starID, err := r.resolveRef(ctx, tx, "star", rec.Star)
+		if err != nil {
+			return err
+		}
+
+		queryString := `
+		INSERT INTO movie_stars (movie_id, star_id, role, characters, job, uncredited, voice, order_no)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (movie_id, star_id, role) DO UPDATE
+		SET characters = EXCLUDED.characters, job = EXCLUDED.job, uncredited = EXCLUDED.uncredited,
+			voice = EXCLUDED.voice, order_no = EXCLUDED.order_no
+		RETURNING xmax = 0`
+		err = tx.QueryRow(ctx, queryString,
+			movieID, starID, rec.Role, nonNil(rec.Characters), rec.Job, rec.Uncredited, rec.Voice, rec.OrderNo,
+		).Scan(&created)
+		if dbx.IsForeignKeyViolation(err, "role") {
+			return apperrors.BadRequest(fmt.Errorf("unknown credit role %q", rec.Role))
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		// The cast is a part of the movie, as it is when the movie is updated
+		return events.Record(ctx, tx, events.MovieUpdated, movieID, &events.Movie{ID: movieID})
+	})
+	return created, err
+}
+
+// ExportGenres calls fn for every genre, parents before their children.
+func (r *Repository) ExportGenres(ctx context.Context, fn func(*GenreRecord) error) error {
+	queryString := `
+	WITH RECURSIVE tree AS (
+		SELECT id, name::text, NULL::text AS parent, 0 AS depth FROM genres WHERE parent_id IS NULL
+		UNION ALL
+		SELECT g.id, g.name::text, t.name, t.depth + 1 FROM genres g INNER JOIN tree t on g.parent_id = t.id
+	)
+	SELECT name, parent FROM tree ORDER BY depth, name`
+
+	return export(ctx, r.db, queryString, fn, func(rec *GenreRecord) []any {
+		return []any{&rec.Name, &rec.Parent}
+	})
+}
+
+// ExportStars calls fn for every star with external ids.
+func (r *Repository) ExportStars(ctx context.Context, fn func(*StarRecord) error) error {
+	queryString := `
+	SELECT ids.external_ids, s.first_name, s.middle_name, s.last_name, s.birth_date, s.birth_place, s.death_date, s.bio
+	FROM stars s
+	INNER JOIN LATERAL (
+		SELECT jsonb_object_agg(source, external_id) AS external_ids FROM star_external_ids WHERE star_id = s.id
+	) ids ON ids.external_ids IS NOT NULL
+	WHERE s.deleted_at IS NULL
+	ORDER BY s.id`
+
+	return export(ctx, r.db, queryString, fn, func(rec *StarRecord) []any {
+		return []any{
+			&rec.ExternalIDs, &rec.FirstName, &rec.MiddleName, &rec.LastName,
+			&rec.BirthDate, &rec.BirthPlace, &rec.DeathDate, &rec.Bio,
+		}
+	})
+}
+
+// ExportMovies calls fn for every top level title with external ids.
+func (r *Repository) ExportMovies(ctx context.Context, fn func(*MovieRecord) error) error {
+	queryString := `
+	SELECT ids.external_ids, m.kind, m.title, m.release_date, m.description, m.language, m.tagline, m.runtime,
+		m.countries, m.spoken_languages,
+		ARRAY(SELECT g.name FROM movie_genres mg INNER JOIN genres g on g.id = mg.genre_id WHERE mg.movie_id = m.id ORDER BY mg.order_no)
+	FROM movies m
+	INNER JOIN LATERAL (
+		SELECT jsonb_object_agg(source, external_id) AS external_ids FROM movie_external_ids WHERE movie_id = m.id
+	) ids ON ids.external_ids IS NOT NULL
+	WHERE m.deleted_at IS NULL and m.parent_id IS NULL
+	ORDER BY m.id`
+
+	return export(ctx, r.db, queryString, fn, func(rec *MovieRecord) []any {
+		return []any{
+			&rec.ExternalIDs, &rec.Kind, &rec.Title, &rec.ReleaseDate, &rec.Description, &rec.Language, &rec.Tagline, &rec.Runtime,
+			&rec.Countries, &rec.SpokenLanguages, &rec.Genres,
+		}
+	})
+}
+
+// ExportCredits calls fn for every credit of the exported stars in the exported titles.
+func (r *Repository) ExportCredits(ctx context.Context, fn func(*CreditRecord) error) error {
+	queryString := `
+	SELECT mr.ref, sr.ref, ms.role, ms.characters, ms.job, ms.uncredited, ms.voice, ms.order_no
+	FROM movie_stars ms
+	INNER JOIN movies m on m.id = ms.movie_id
+	INNER JOIN stars s on s.id = ms.star_id
+	INNER JOIN LATERAL (
+		SELECT source || ':' || external_id AS ref FROM movie_external_ids WHERE movie_id = m.id ORDER BY source LIMIT 1
+	) mr ON TRUE
+	INNER JOIN LATERAL (
+		SELECT source || ':' || external_id AS ref FROM star_external_ids WHERE star_id = s.id ORDER BY source LIMIT 1
+	) sr ON TRUE
+	WHERE m.deleted_at IS NULL and m.parent_id IS NULL and s.deleted_at IS NULL
+	ORDER BY ms.movie_id, ms.order_no, ms.star_id, ms.role`
+
+	return export(ctx, r.db, queryString, fn, func(rec *CreditRecord) []any {
+		return []any{&rec.Movie, &rec.Star, &rec.Role, &rec.Characters, &rec.Job, &rec.Uncredited, &rec.Voice, &rec.OrderNo}
+	})
+}
+
+func export[T any](ctx context.Context, db *pgxpool.Pool, queryString string, fn func(*T) error, dest func(*T) []any) error {
+	rows, err := db.Query(ctx, queryString)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rec := new(T)
+		if err = rows.Scan(dest(rec)...); err != nil {
+			return apperrors.Internal(err)
+		}
+		if err = fn(rec); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// savepoint runs fn in a savepoint of the transaction of the context, so that a failed row
+// is rolled back on its own.
+func (r *Repository) savepoint(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	parent, ok := dbx.FromContext(ctx, r.db).(pgx.Tx)
+	if !ok {
+		return apperrors.Internal(errors.New("bulk upsert outside of a transaction"))
+	}
+
+	tx, err := parent.Begin(ctx)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if err = fn(dbx.WithTransaction(ctx, tx), tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return apperrors.Internal(errors.Join(err, rbErr))
+		}
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// matchExternalIDs returns the id of the live entity having any of the external ids, or nil when there is none.
+func (r *Repository) matchExternalIDs(ctx context.Context, tx pgx.Tx, subject string, externalIDs map[string]string) (*int, error) {
+	queryString := fmt.Sprintf(`
+	SELECT DISTINCT e.%[1]s_id, t.deleted_at IS NOT NULL
+	FROM %[1]s_external_ids e
+	INNER JOIN %[1]ss t on t.id = e.%[1]s_id
+	WHERE (e.source, e.external_id) IN (SELECT * FROM unnest($1::varchar[], $2::varchar[]))`, subject)
+
+	sources, ids := make([]string, 0, len(externalIDs)), make([]string, 0, len(externalIDs))
+	for source, id := range externalIDs {
+		sources = append(sources, source)
+		ids = append(ids, id)
+	}
+
+	rows, err := tx.Query(ctx, queryString, sources, ids)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	type match struct {
+		ID      int
+		Deleted bool
+	}
+	matches, err := pgx.CollectRows(rows, pgx.RowToStructByPos[match])
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, nil
+	case len(matches) > 1:
+		return nil, apperrors.BadRequest(fmt.Errorf("external ids match %d different %ss", len(matches), subject))
+	case matches[0].Deleted:
+		return nil, apperrors.BadRequest(fmt.Errorf("%s with id %d matching the external ids is deleted", subject, matches[0].ID))
+	}
+	return &matches[0].ID, nil
+}
+
+func (r *Repository) upsertExternalIDs(ctx context.Context, tx pgx.Tx, subject string, id int, externalIDs map[string]string) error {
+	queryString := fmt.Sprintf(`
+	INSERT INTO %[1]s_external_ids (%[1]s_id, source, external_id) VALUES ($1, $2, $3)
+	ON CONFLICT (%[1]s_id, source) DO UPDATE SET external_id = EXCLUDED.external_id`, subject)
+
+	for source, externalID := range externalIDs {
+		_, err := tx.Exec(ctx, queryString, id, source, externalID)
+		if dbx.IsUniqueViolation(err, "source_external_id") {
+			return apperrors.AlreadyExists(subject, source, externalID)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+	}
+	return nil
+}
+
+// resolveRef returns the id of the live entity referenced by one of its external ids as source:id.
+func (r *Repository) resolveRef(ctx context.Context, tx pgx.Tx, subject, ref string) (int, error) {
+	source, externalID, err := parseRef(ref)
+	if err != nil {
+		return 0, apperrors.BadRequest(err)
+	}
+
+	queryString := fmt.Sprintf(`
+	SELECT e.%[1]s_id
+	FROM %[1]s_external_ids e
+	INNER JOIN %[1]ss t on t.id = e.%[1]s_id
+	WHERE e.source = $1 and e.external_id = $2 and t.deleted_at IS NULL`, subject)
+
+	var id int
+	err = tx.QueryRow(ctx, queryString, source, externalID).Scan(&id)
+	if dbx.IsNoRows(err) {
+		return 0, apperrors.NotFound(subject, source, externalID)
+	}
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+	return id, nil
+}
+
+// replaceGenres sets the genres of the title, referenced by their names or aliases, in the given order.
+func (r *Repository) replaceGenres(ctx context.Context, tx pgx.Tx, movieID int, names []string) error {
+	_, err := tx.Exec(ctx, "DELETE FROM movie_genres WHERE movie_id = $1", movieID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	queryString := `
+	SELECT id FROM genres WHERE name = $1
+	UNION ALL
+	SELECT genre_id FROM genre_aliases WHERE lower(name) = lower($1)
+	LIMIT 1`
+
+	seen := make(map[int]bool, len(names))
+	for _, name := range names {
+		var genreID int
+		err = tx.QueryRow(ctx, queryString, name).Scan(&genreID)
+		if dbx.IsNoRows(err) {
+			return apperrors.NotFound("genre", "name", name)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if seen[genreID] {
+			continue
+		}
+
+		_, err = tx.Exec(ctx, "INSERT INTO movie_genres (movie_id, genre_id, order_no) VALUES ($1, $2, $3)", movieID, genreID, len(seen))
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		seen[genreID] = true
+	}
+	return nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"gopkg.in/validator.v2"
+)
+
+// flushEvery is the number of exported records after which the response is flushed to the client.
+const flushEvery = 100
+
+var errRollback = errors.New("import rolled back")
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// Import upserts the records read from r. With chunkSize of zero the import runs in a single transaction and
+// is rolled back as a whole if any row fails, otherwise every chunkSize rows are committed in their own transaction
+// and the failed rows are skipped.
+func (s *Service) Import(ctx context.Context, entity, format string, r io.Reader, chunkSize int) (*Report, error) {
+	rd, err := newReader(format, entity, r)
+	if err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	report := &Report{Errors: []*RowError{}}
+	for done := false; !done; {
+		var created, updated int
+		err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+			for n := 0; chunkSize == 0 || n < chunkSize; n++ {
+				rec, rowErr, err := rd.Next()
+				if errors.Is(err, io.EOF) {
+					done = true
+					break
+				}
+				if err != nil {
+					return apperrors.BadRequestHidden(err, "invalid or malformed request")
+				}
+
+				report.Total++
+				var isNew bool
+				if rowErr == nil {
+					isNew, rowErr = s.upsert(ctx, rec)
+				}
+				switch {
+				case rowErr == nil && isNew:
+					created++
+				case rowErr == nil:
+					updated++
+				case apperrors.Is(rowErr, apperrors.InternalCode):
+					return rowErr
+				default:
+					report.Failed++
+					report.Errors = append(report.Errors, &RowError{Row: report.Total, Error: safeError(rowErr)})
+				}
+			}
+
+			if chunkSize == 0 && report.Failed > 0 {
+				return errRollback
+			}
+			return nil
+		})
+		if errors.Is(err, errRollback) {
+			report.RolledBack = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		report.Created += created
+		report.Updated += updated
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("bulk import finished",
+		"entity", entity,
+		"total", report.Total,
+		"created", report.Created,
+		"updated", report.Updated,
+		"failed", report.Failed,
+		"rolledBack", report.RolledBack)
+
+	return report, nil
+}
+
+// Export writes all the records of the entity to w, flushing it regularly if it is an http.Flusher.
+func (s *Service) Export(ctx context.Context, entity, format string, w io.Writer) error {
+	wr, err := newWriter(format, entity, w)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	var count int
+	write := func(rec record) error {
+		if err := wr.Write(rec); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		count++
+		if count%flushEvery == 0 {
+			return flush(wr, w)
+		}
+		return nil
+	}
+
+	switch entity {
+	case EntityGenres:
+		err = s.repo.ExportGenres(ctx, func(rec *GenreRecord) error { return write(rec) })
+	case EntityStars:
+		err = s.repo.ExportStars(ctx, func(rec *StarRecord) error { return write(rec) })
+	case EntityMovies:
+		err = s.repo.ExportMovies(ctx, func(rec *MovieRecord) error { return write(rec) })
+	case EntityCredits:
+		err = s.repo.ExportCredits(ctx, func(rec *CreditRecord) error { return write(rec) })
+	}
+	if err != nil {
+		return err
+	}
+
+	return flush(wr, w)
+}
+
+func (s *Service) upsert(ctx context.Context, rec record) (bool, error) {
+	if err := validator.Validate(rec); err != nil {
+		return false, apperrors.BadRequest(err)
+	}
+
+	switch rec := rec.(type) {
+	case *GenreRecord:
+		return s.repo.UpsertGenre(ctx, rec)
+	case *StarRecord:
+		return s.repo.UpsertStar(ctx, rec)
+	case *MovieRecord:
+		return s.repo.UpsertMovie(ctx, rec)
+	case *CreditRecord:
+		return s.repo.UpsertCredit(ctx, rec)
+	}
+	return false, apperrors.Internal(errors.New("unknown bulk record"))
+}
+
+func flush(wr writer, w io.Writer) error {
+	if err := wr.Flush(); err != nil {
+		return apperrors.Internal(err)
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// safeError is the message of a row error, the decoding errors of the readers aren't apperrors.
+func safeError(err error) string {
+	var appError *apperrors.Error
+	if errors.As(err, &appError) {
+		return appError.SafeError()
+	}
+	return err.Error()
+}
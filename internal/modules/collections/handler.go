@@ -0,0 +1,127 @@
+package collections
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+type Handler struct {
+	Service          *Service
+	PaginationConfig config.PaginationConfig
+	reqGroup         singleflight.Group
+}
+
+func NewHandler(service *Service, cfg config.PaginationConfig) *Handler {
+	return &Handler{
+		Service:          service,
+		PaginationConfig: cfg,
+	}
+}
+
+func (h *Handler) GetAll(c echo.Context) error {
+	res, err, _ := h.reqGroup.Do(c.Request().RequestURI, func() (any, error) {
+		req, err := echox.BindAndValidate[contracts.GetCollectionsRequest](c)
+		if err != nil {
+			return nil, err
+		}
+
+		params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := h.Service.GetAllPaginated(c.Request().Context(), req.Sort, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return pagination.Response(&req.PaginatedRequest, params, page), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetByID(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetCollectionByIDRequest](c)
+	if err != nil {
+		return err
+	}
+
+	collection, err := h.Service.GetByID(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+func (h *Handler) Create(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateCollectionRequest](c)
+	if err != nil {
+		return err
+	}
+
+	collection, err := h.Service.Create(c.Request().Context(), &CollectionDetails{
+		Collection: Collection{
+			Name:        req.Name,
+			Description: req.Description,
+		},
+		Movies: toCollectionMovies(req.MovieIDs),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, collection)
+}
+
+func (h *Handler) Update(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UpdateCollectionRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Update(c.Request().Context(), req.ID, &CollectionDetails{
+		Collection: Collection{
+			Name:        req.Name,
+			Description: req.Description,
+		},
+		Movies: toCollectionMovies(req.MovieIDs),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Delete(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteCollectionRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Delete(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toCollectionMovies(ids []int) []*CollectionMovie {
+	return slices.Map(ids, func(id int) *CollectionMovie {
+		return &CollectionMovie{ID: id}
+	})
+}
@@ -0,0 +1,56 @@
+package collections
+
+import (
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+)
+
+type Collection struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	AvgRating   *float64  `json:"avg_rating,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CollectionDetails struct {
+	Collection
+	Movies []*CollectionMovie `json:"movies"`
+}
+
+// CollectionMovie is a short reference to a movie of a collection.
+type CollectionMovie struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+	AvgRating   *float64  `json:"avg_rating,omitempty"`
+}
+
+// MovieCollection is a collection seen from one of its movies, with links to the neighbouring movies.
+type MovieCollection struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Position int              `json:"position"`
+	Previous *CollectionMovie `json:"previous,omitempty"`
+	Next     *CollectionMovie `json:"next,omitempty"`
+}
+
+var _ dbx.Keyer = CollectionMovieRelation{}
+
+type CollectionMovieRelation struct {
+	CollectionID int
+	MovieID      int
+	OrderNo      int
+}
+
+func (c CollectionMovieRelation) Key() any {
+	type CollectionMovieRelationKey struct {
+		CollectionID, MovieID int
+	}
+
+	return CollectionMovieRelationKey{
+		CollectionID: c.CollectionID,
+		MovieID:      c.MovieID,
+	}
+}
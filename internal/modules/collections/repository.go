@@ -0,0 +1,362 @@
+package collections
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+// avgRatingExpr is the average over all the reviews of the collection movies, including seasons and episodes of series.
+const avgRatingExpr = `(
+	SELECT avg(r.rating)
+	FROM reviews r
+	WHERE r.deleted_at IS NULL and r.movie_id IN (
+		SELECT title_subtree(cm.movie_id)
+		FROM collection_movies cm
+		INNER JOIN movies m on m.id = cm.movie_id
+		WHERE cm.collection_id = collections.id and m.deleted_at IS NULL))`
+
+var sortFields = dbx.SortFields{
+	"name":       {{Expr: "name", Type: dbx.KeyText}},
+	"rating":     {{Expr: "coalesce(" + avgRatingExpr + ", 0)", Type: dbx.KeyNumeric}},
+	"created_at": {{Expr: "created_at", Type: dbx.KeyTimestamp}},
+}
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetAllPaginated(ctx context.Context, sort *string, params *pagination.Params) (*pagination.Page[Collection], error) {
+	queryPage := dbx.StatementBuilder.
+		Select("id, name, description, " + avgRatingExpr + ", created_at").
+		From("collections").
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
+
+	keyset := dbx.Keyset{{Expr: "id"}}
+	if sort != nil {
+		keyset = dbx.SortKeyset(*sort, sortFields, keyset...)
+	}
+
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &pgx.Batch{}
+
+	err = dbx.QueueBatchSelect(b, queryPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, dbx.StatementBuilder.Select("count(*)").From("collections"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	br := r.db.SendBatch(ctx, b)
+	defer br.Close()
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var collections []*Collection
+	var keys [][]string
+	for rows.Next() {
+		var collection Collection
+		key := keyset.NewKey()
+		err = rows.Scan(append([]any{
+			&collection.ID,
+			&collection.Name,
+			&collection.Description,
+			&collection.AvgRating,
+			&collection.CreatedAt,
+		}, keyset.ScanDest(key)...)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		collections = append(collections, &collection)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, collections, keys, total), nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*CollectionDetails, error) {
+	queryString := "SELECT id, name, description, " + avgRatingExpr + ", created_at FROM collections WHERE id = $1"
+	q := dbx.FromContext(ctx, r.db)
+
+	var collection CollectionDetails
+	err := q.QueryRow(ctx, queryString, id).Scan(
+		&collection.ID,
+		&collection.Name,
+		&collection.Description,
+		&collection.AvgRating,
+		&collection.CreatedAt,
+	)
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("collection", "id", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	queryString = `
+	SELECT m.id, m.title, m.release_date, m.avg_rating
+	FROM movies m
+	INNER JOIN collection_movies cm on cm.movie_id = m.id
+	WHERE cm.collection_id = $1 and m.deleted_at IS NULL
+	ORDER BY cm.order_no, m.id`
+
+	rows, err := q.Query(ctx, queryString, id)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	collection.Movies = []*CollectionMovie{}
+	for rows.Next() {
+		var movie CollectionMovie
+		err = rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.ReleaseDate,
+			&movie.AvgRating,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		collection.Movies = append(collection.Movies, &movie)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return &collection, nil
+}
+
+// GetByMovieID returns the collections of the movie with the movies just before and after it in each of them.
+func (r *Repository) GetByMovieID(ctx context.Context, movieID int) ([]*MovieCollection, error) {
+	queryString := `
+	SELECT c.id, c.name, x.position,
+		x.prev_id, x.prev_title, x.prev_release_date, x.prev_avg_rating,
+		x.next_id, x.next_title, x.next_release_date, x.next_avg_rating
+	FROM (
+		SELECT cm.collection_id, cm.movie_id,
+			row_number() OVER w AS position,
+			lag(m.id) OVER w AS prev_id,
+			lag(m.title) OVER w AS prev_title,
+			lag(m.release_date) OVER w AS prev_release_date,
+			lag(m.avg_rating) OVER w AS prev_avg_rating,
+			lead(m.id) OVER w AS next_id,
+			lead(m.title) OVER w AS next_title,
+			lead(m.release_date) OVER w AS next_release_date,
+			lead(m.avg_rating) OVER w AS next_avg_rating
+		FROM collection_movies cm
+		INNER JOIN movies m on m.id = cm.movie_id
+		WHERE m.deleted_at IS NULL and cm.collection_id IN (SELECT collection_id FROM collection_movies WHERE movie_id = $1)
+		WINDOW w AS (PARTITION BY cm.collection_id ORDER BY cm.order_no, cm.movie_id)
+	) x
+	INNER JOIN collections c on c.id = x.collection_id
+	WHERE x.movie_id = $1
+	ORDER BY c.name`
+
+	rows, err := r.db.Query(ctx, queryString, movieID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var collections []*MovieCollection
+	for rows.Next() {
+		var collection MovieCollection
+		var prev, next nullableMovie
+		err = rows.Scan(
+			&collection.ID,
+			&collection.Name,
+			&collection.Position,
+			&prev.ID, &prev.Title, &prev.ReleaseDate, &prev.AvgRating,
+			&next.ID, &next.Title, &next.ReleaseDate, &next.AvgRating,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		collection.Previous = prev.movie()
+		collection.Next = next.movie()
+		collections = append(collections, &collection)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return collections, nil
+}
+
+func (r *Repository) Create(ctx context.Context, collection *CollectionDetails) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "INSERT INTO collections (name, description) VALUES ($1, $2) RETURNING id, created_at"
+		err := tx.QueryRow(ctx, queryString, collection.Name, collection.Description).
+			Scan(&collection.ID, &collection.CreatedAt)
+		if dbx.IsUniqueViolation(err, "name") {
+			return apperrors.AlreadyExists("collection", "name", collection.Name)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return r.updateMovies(ctx, nil, relations(collection.ID, collection.Movies))
+	})
+}
+
+func (r *Repository) Update(ctx context.Context, id int, collection *CollectionDetails) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "UPDATE collections SET name = $2, description = $3 WHERE id = $1"
+		cmdTag, err := tx.Exec(ctx, queryString, id, collection.Name, collection.Description)
+		if dbx.IsUniqueViolation(err, "name") {
+			return apperrors.AlreadyExists("collection", "name", collection.Name)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("collection", "id", id)
+		}
+
+		current, err := r.getRelations(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return r.updateMovies(ctx, current, relations(id, collection.Movies))
+	})
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	cmdTag, err := r.db.Exec(ctx, "DELETE FROM collections WHERE id = $1", id)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("collection", "id", id)
+	}
+
+	return nil
+}
+
+func (r *Repository) getRelations(ctx context.Context, id int) ([]*CollectionMovieRelation, error) {
+	queryString := "SELECT collection_id, movie_id, order_no FROM collection_movies WHERE collection_id = $1"
+	q := dbx.FromContext(ctx, r.db)
+	rows, err := q.Query(ctx, queryString, id)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var relations []*CollectionMovieRelation
+	for rows.Next() {
+		var relation CollectionMovieRelation
+		err = rows.Scan(
+			&relation.CollectionID,
+			&relation.MovieID,
+			&relation.OrderNo,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		relations = append(relations, &relation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return relations, nil
+}
+
+func (r *Repository) updateMovies(ctx context.Context, current, next []*CollectionMovieRelation) error {
+	q := dbx.FromContext(ctx, r.db)
+	addFunc := func(cm *CollectionMovieRelation) error {
+		cmdTag, err := q.Exec(ctx, `
+		INSERT INTO collection_movies (collection_id, movie_id, order_no)
+		SELECT $1, id, $3 FROM movies WHERE id = $2 and deleted_at IS NULL`,
+			cm.CollectionID, cm.MovieID, cm.OrderNo)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("movie", "id", cm.MovieID)
+		}
+		return nil
+	}
+
+	removeFunc := func(cm *CollectionMovieRelation) error {
+		_, err := q.Exec(ctx,
+			"DELETE FROM collection_movies WHERE collection_id = $1 and movie_id = $2",
+			cm.CollectionID, cm.MovieID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	}
+
+	return dbx.AdjustRelations(current, next, addFunc, removeFunc)
+}
+
+func relations(collectionID int, movies []*CollectionMovie) []*CollectionMovieRelation {
+	return slices.MapIndex(movies, func(i int, movie *CollectionMovie) *CollectionMovieRelation {
+		return &CollectionMovieRelation{
+			CollectionID: collectionID,
+			MovieID:      movie.ID,
+			OrderNo:      i,
+		}
+	})
+}
+
+// nullableMovie receives the columns of a neighbouring movie, which are NULL at the ends of a collection.
+type nullableMovie struct {
+	ID          *int
+	Title       *string
+	ReleaseDate *time.Time
+	AvgRating   *float64
+}
+
+func (m nullableMovie) movie() *CollectionMovie {
+	if m.ID == nil {
+		return nil
+	}
+	return &CollectionMovie{
+		ID:          *m.ID,
+		Title:       *m.Title,
+		ReleaseDate: *m.ReleaseDate,
+		AvgRating:   m.AvgRating,
+	}
+}
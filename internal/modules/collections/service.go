@@ -0,0 +1,71 @@
+package collections
+
+import (
+	"context"
+
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+func (s *Service) GetAllPaginated(ctx context.Context, sort *string, params *pagination.Params) (*pagination.Page[Collection], error) {
+	return s.repo.GetAllPaginated(ctx, sort, params)
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*CollectionDetails, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) GetByMovieID(ctx context.Context, movieID int) ([]*MovieCollection, error) {
+	return s.repo.GetByMovieID(ctx, movieID)
+}
+
+func (s *Service) Create(ctx context.Context, collection *CollectionDetails) (*CollectionDetails, error) {
+	err := s.repo.Create(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("collection created",
+		"collection_id", collection.ID,
+		"name", collection.Name)
+
+	return s.repo.GetByID(ctx, collection.ID)
+}
+
+func (s *Service) Update(ctx context.Context, id int, collection *CollectionDetails) error {
+	err := s.repo.Update(ctx, id, collection)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("collection updated",
+		"collection_id", id,
+		"name", collection.Name)
+
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("collection deleted",
+		"collection_id", id)
+
+	return nil
+}
@@ -46,6 +46,9 @@ func (h *Handler) GetGenreByID(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	if echox.NotModified(c, genre.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 
 	return c.JSON(http.StatusOK, genre)
 }
@@ -56,7 +59,7 @@ func (h *Handler) CreateGenre(c echo.Context) error {
 		return err
 	}
 
-	genre, err := h.Service.CreateGenre(c.Request().Context(), req.Name)
+	genre, err := h.Service.CreateGenre(c.Request().Context(), req.Name, req.ParentID)
 	if err != nil {
 		return err
 	}
@@ -69,8 +72,51 @@ func (h *Handler) UpdateGenre(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	return h.updateGenre(c, req, version)
+}
+
+// PatchGenre applies a merge patch to the genre. Unless If-Match says otherwise, the patch is conditional
+// on the version it was applied to.
+func (h *Handler) PatchGenre(c echo.Context) error {
+	var current *Genre
+	req, err := echox.BindMergePatch(c, func(req *contracts.UpdateGenreRequest) error {
+		genre, err := h.Service.GetGenreByID(c.Request().Context(), req.ID)
+		if err != nil {
+			return err
+		}
+
+		current = genre
+		req.Name = genre.Name
+		req.ParentID = genre.ParentID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		version = &current.Version
+	}
+
+	return h.updateGenre(c, req, version)
+}
+
+func (h *Handler) updateGenre(c echo.Context, req *contracts.UpdateGenreRequest, version *int) error {
+	newVersion, err := h.Service.UpdateGenre(c.Request().Context(), req.ID, req.Name, req.ParentID, version)
+	if err != nil {
+		return err
+	}
 
-	return h.Service.UpdateGenre(c.Request().Context(), req.ID, req.Name)
+	echox.SetETag(c, newVersion)
+	return c.NoContent(http.StatusOK)
 }
 
 func (h *Handler) DeleteGenre(c echo.Context) error {
@@ -78,6 +124,47 @@ func (h *Handler) DeleteGenre(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	return h.Service.DeleteGenre(c.Request().Context(), req.ID)
+	return h.Service.DeleteGenre(c.Request().Context(), req.ID, version)
+}
+
+func (h *Handler) CreateAlias(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateGenreAliasRequest](c)
+	if err != nil {
+		return err
+	}
+
+	genre, err := h.Service.CreateAlias(c.Request().Context(), req.ID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, genre)
+}
+
+func (h *Handler) DeleteAlias(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteGenreAliasRequest](c)
+	if err != nil {
+		return err
+	}
+
+	return h.Service.DeleteAlias(c.Request().Context(), req.ID, req.Name)
+}
+
+func (h *Handler) MergeGenre(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.MergeGenreRequest](c)
+	if err != nil {
+		return err
+	}
+
+	genre, err := h.Service.Merge(c.Request().Context(), req.ID, req.TargetID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, genre)
 }
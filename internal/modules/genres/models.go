@@ -3,8 +3,11 @@ package genres
 import "github.com/mkuptsov/movie-reviews/internal/dbx"
 
 type Genre struct {
-	ID   int    `param:"id"`
-	Name string `json:"name"`
+	ID       int      `param:"id"`
+	Name     string   `json:"name"`
+	ParentID *int     `json:"parent_id,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Version  int      `json:"version"`
 }
 
 var _ dbx.Keyer = MovieGenreRelation{}
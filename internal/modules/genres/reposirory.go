@@ -2,11 +2,13 @@ package genres
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
 )
 
 type Repository struct {
@@ -19,8 +21,14 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	}
 }
 
+// selectGenres selects the genres with their aliases, the alias names are sorted.
+const selectGenres = `
+	SELECT g.id, g.name, g.parent_id, g.version,
+		coalesce((SELECT array_agg(a.name ORDER BY a.name) FROM genre_aliases a WHERE a.genre_id = g.id), '{}')
+	FROM genres g`
+
 func (r *Repository) GetGenres(ctx context.Context) ([]*Genre, error) {
-	queryString := "SELECT id, name FROM genres;"
+	queryString := selectGenres + " ORDER BY g.id;"
 	rows, err := r.db.Query(ctx, queryString)
 	if err != nil {
 		return nil, apperrors.Internal(err)
@@ -30,7 +38,7 @@ func (r *Repository) GetGenres(ctx context.Context) ([]*Genre, error) {
 	var allGenres []*Genre
 	for rows.Next() {
 		var genre Genre
-		err = rows.Scan(&genre.ID, &genre.Name)
+		err = rows.Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.Version, &genre.Aliases)
 		if err != nil {
 			return nil, apperrors.Internal(err)
 		}
@@ -42,11 +50,12 @@ func (r *Repository) GetGenres(ctx context.Context) ([]*Genre, error) {
 }
 
 func (r *Repository) GetGenreByID(ctx context.Context, id int) (*Genre, error) {
-	queryString := "SELECT id, name FROM genres WHERE id = $1;"
-	row := r.db.QueryRow(ctx, queryString, id)
+	queryString := selectGenres + " WHERE g.id = $1;"
+	q := dbx.FromContext(ctx, r.db)
+	row := q.QueryRow(ctx, queryString, id)
 
 	var genre Genre
-	err := row.Scan(&genre.ID, &genre.Name)
+	err := row.Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.Version, &genre.Aliases)
 	if dbx.IsNoRows(err) {
 		return nil, apperrors.NotFound("genre", "id", id)
 	}
@@ -57,51 +66,250 @@ func (r *Repository) GetGenreByID(ctx context.Context, id int) (*Genre, error) {
 	return &genre, nil
 }
 
-func (r *Repository) CreateGenre(ctx context.Context, name string) (*Genre, error) {
-	queryString := "INSERT INTO genres (name) VALUES ($1) returning id, name;"
-	row := r.db.QueryRow(ctx, queryString, name)
-
+func (r *Repository) CreateGenre(ctx context.Context, name string, parentID *int) (*Genre, error) {
 	var genre Genre
-	err := row.Scan(&genre.ID, &genre.Name)
-	if dbx.IsUniqueViolation(err, "name") {
-		return nil, apperrors.AlreadyExists("genre", "name", name)
-	}
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.checkNotAlias(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		queryString := "INSERT INTO genres (name, parent_id) VALUES ($1, $2) returning id, name, parent_id, version;"
+		row := tx.QueryRow(ctx, queryString, name, parentID)
+
+		err = row.Scan(&genre.ID, &genre.Name, &genre.ParentID, &genre.Version)
+		if dbx.IsUniqueViolation(err, "name") {
+			return apperrors.AlreadyExists("genre", "name", name)
+		}
+		if dbx.IsForeignKeyViolation(err, "parent_id") {
+			return apperrors.NotFound("genre", "id", *parentID)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, apperrors.Internal(err)
+		return nil, err
 	}
 
 	return &genre, nil
 }
 
-func (r *Repository) UpdateGenre(ctx context.Context, id int, name string) error {
-	queryString := "UPDATE genres SET name = $2 WHERE id = $1;"
-	cmdTag, err := r.db.Exec(ctx, queryString, id, name)
+// UpdateGenre updates the genre if it still has the given version, any version will do when it is nil,
+// and returns the new version.
+func (r *Repository) UpdateGenre(ctx context.Context, id int, name string, parentID *int, version *int) (int, error) {
+	var newVersion int
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		err := r.checkNotAlias(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if parentID != nil {
+			nested, err := r.isDescendant(ctx, *parentID, id)
+			if err != nil {
+				return err
+			}
+			if nested {
+				return apperrors.BadRequest(errors.New("genre can't be nested into itself"))
+			}
+		}
+
+		queryString := `
+		UPDATE genres SET name = $2, parent_id = $3, version = version + 1
+		WHERE id = $1 and ($4::int IS NULL or version = $4)
+		RETURNING version;`
+		err = tx.QueryRow(ctx, queryString, id, name, parentID, version).Scan(&newVersion)
+		if dbx.IsUniqueViolation(err, "name") {
+			return apperrors.AlreadyExists("genre", "name", name)
+		}
+		if dbx.IsForeignKeyViolation(err, "parent_id") {
+			return apperrors.NotFound("genre", "id", *parentID)
+		}
+		if dbx.IsNoRows(err) {
+			return r.modificationError(ctx, id, version)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newVersion, nil
+}
+
+func (r *Repository) DeleteGenre(ctx context.Context, id int, version *int) error {
+	queryString := "DELETE FROM genres WHERE id = $1 and ($2::int IS NULL or version = $2);"
+	cmdTag, err := r.db.Exec(ctx, queryString, id, version)
 	if err != nil {
 		return apperrors.Internal(err)
 	}
 	if cmdTag.RowsAffected() == 0 {
+		return r.modificationError(ctx, id, version)
+	}
+
+	return nil
+}
+
+// modificationError tells whether the genre wasn't modified because it doesn't exist or because of a stale version.
+func (r *Repository) modificationError(ctx context.Context, id int, version *int) error {
+	_, err := r.GetGenreByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return apperrors.VersionMismatch("genre", "id", id, *version)
+}
+
+func (r *Repository) CreateAlias(ctx context.Context, id int, alias string) error {
+	queryString := `
+	WITH alias AS (
+		INSERT INTO genre_aliases (name, genre_id)
+		SELECT $2, $1
+		WHERE NOT EXISTS (SELECT 1 FROM genres WHERE lower(name) = lower($2))
+		RETURNING genre_id
+	)
+	UPDATE genres SET version = version + 1 WHERE id IN (SELECT genre_id FROM alias)`
+
+	cmdTag, err := r.db.Exec(ctx, queryString, id, alias)
+	if dbx.IsUniqueViolation(err, "name") {
+		return apperrors.AlreadyExists("genre alias", "name", alias)
+	}
+	if dbx.IsForeignKeyViolation(err, "genre_id") {
 		return apperrors.NotFound("genre", "id", id)
 	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.AlreadyExists("genre", "name", alias)
+	}
 
 	return nil
 }
 
-func (r *Repository) DeleteGenre(ctx context.Context, id int) error {
-	queryString := "DELETE FROM genres WHERE id = $1;"
-	cmdTag, err := r.db.Exec(ctx, queryString, id)
+func (r *Repository) DeleteAlias(ctx context.Context, id int, alias string) error {
+	queryString := `
+	WITH alias AS (
+		DELETE FROM genre_aliases WHERE genre_id = $1 and lower(name) = lower($2)
+		RETURNING genre_id
+	)
+	UPDATE genres SET version = version + 1 WHERE id IN (SELECT genre_id FROM alias)`
+	cmdTag, err := r.db.Exec(ctx, queryString, id, alias)
 	if err != nil {
 		return apperrors.Internal(err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("genre", "id", id)
+		return apperrors.NotFound("genre alias", "name", alias)
+	}
+
+	return nil
+}
+
+// Merge moves the movies, aliases and subgenres of the source genre to the target one and deletes the source,
+// whose name becomes an alias of the target. Movies keep the positions of their genres; when a movie has both,
+// the target takes the earlier position of the two.
+func (r *Repository) Merge(ctx context.Context, sourceID, targetID int) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, "SELECT id FROM genres WHERE id = ANY($1) FOR UPDATE", []int{sourceID, targetID})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		locked, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		for _, id := range []int{sourceID, targetID} {
+			if !slices.Contains(locked, id) {
+				return apperrors.NotFound("genre", "id", id)
+			}
+		}
+
+		// A subgenre of the source can't stay under it, otherwise it would become its own ancestor below
+		nested, err := r.isDescendant(ctx, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+		if nested {
+			_, err = tx.Exec(ctx, "UPDATE genres SET parent_id = (SELECT parent_id FROM genres WHERE id = $1), version = version + 1 WHERE id = $2", sourceID, targetID)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		statements := []string{
+			`UPDATE movie_genres t SET order_no = least(t.order_no, s.order_no)
+			FROM movie_genres s
+			WHERE s.movie_id = t.movie_id and s.genre_id = $1 and t.genre_id = $2`,
+			`DELETE FROM movie_genres s
+			WHERE s.genre_id = $1 and EXISTS (SELECT 1 FROM movie_genres t WHERE t.movie_id = s.movie_id and t.genre_id = $2)`,
+			"UPDATE movie_genres SET genre_id = $2 WHERE genre_id = $1",
+			"UPDATE genre_aliases SET genre_id = $2 WHERE genre_id = $1",
+			"UPDATE genres SET parent_id = $2, version = version + 1 WHERE parent_id = $1",
+			"INSERT INTO genre_aliases (name, genre_id) SELECT name, $2 FROM genres WHERE id = $1",
+		}
+		for _, statement := range statements {
+			_, err = tx.Exec(ctx, statement, sourceID, targetID)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM genres WHERE id = $1", sourceID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		_, err = tx.Exec(ctx, "UPDATE genres SET version = version + 1 WHERE id = $1", targetID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
+}
+
+// checkNotAlias makes sure a genre name doesn't clash with an alias of another genre.
+func (r *Repository) checkNotAlias(ctx context.Context, name string) error {
+	var exists bool
+	q := dbx.FromContext(ctx, r.db)
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM genre_aliases WHERE lower(name) = lower($1))", name).Scan(&exists)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if exists {
+		return apperrors.AlreadyExists("genre alias", "name", name)
 	}
 
 	return nil
 }
 
+// isDescendant reports whether the genre is nested into the ancestor at any depth or is the ancestor itself.
+func (r *Repository) isDescendant(ctx context.Context, id, ancestorID int) (bool, error) {
+	queryString := `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id FROM genres WHERE id = $1
+		UNION ALL
+		SELECT g.id, g.parent_id FROM genres g INNER JOIN ancestors a on g.id = a.parent_id
+	)
+	SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+
+	var nested bool
+	q := dbx.FromContext(ctx, r.db)
+	err := q.QueryRow(ctx, queryString, id, ancestorID).Scan(&nested)
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	return nested, nil
+}
+
 func (r *Repository) GetGenresByMovieID(ctx context.Context, id int) ([]*Genre, error) {
 	queryString := `
-	SELECT g.id, g.name
+	SELECT g.id, g.name, g.version
 	FROM genres g
 	INNER JOIN movie_genres mg on mg.genre_id = g.id	
 	WHERE mg.movie_id = $1
@@ -121,6 +329,43 @@ func (r *Repository) GetGenresByMovieID(ctx context.Context, id int) ([]*Genre,
 	return genres, nil
 }
 
+func (r *Repository) GetGenresByMovieIDs(ctx context.Context, ids []int) (map[int][]*Genre, error) {
+	queryString := `
+	SELECT mg.movie_id, g.id, g.name, g.version
+	FROM genres g
+	INNER JOIN movie_genres mg on mg.genre_id = g.id
+	WHERE mg.movie_id = ANY($1)
+	ORDER BY mg.movie_id, mg.order_no
+	`
+
+	rows, err := r.db.Query(ctx, queryString, ids)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	genres := make(map[int][]*Genre, len(ids))
+	for rows.Next() {
+		var movieID int
+		var genre Genre
+		err = rows.Scan(
+			&movieID,
+			&genre.ID,
+			&genre.Name,
+			&genre.Version,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		genres[movieID] = append(genres[movieID], &genre)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return genres, nil
+}
+
 func (r *Repository) GetRelationsByMovieID(ctx context.Context, id int) ([]*MovieGenreRelation, error) {
 	queryString := "SELECT movie_id, genre_id, order_no FROM movie_genres WHERE movie_id = $1"
 	q := dbx.FromContext(ctx, r.db)
@@ -153,6 +398,7 @@ func scanGenres(rows pgx.Rows) ([]*Genre, error) {
 		err := rows.Scan(
 			&genre.ID,
 			&genre.Name,
+			&genre.Version,
 		)
 		if err != nil {
 			return nil, apperrors.Internal(err)
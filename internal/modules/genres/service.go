@@ -1,6 +1,12 @@
 package genres
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
 
 type Service struct {
 	repo *Repository
@@ -20,16 +26,51 @@ func (s *Service) GetGenreByID(ctx context.Context, id int) (*Genre, error) {
 	return s.repo.GetGenreByID(ctx, id)
 }
 
-func (s *Service) CreateGenre(ctx context.Context, name string) (*Genre, error) {
-	return s.repo.CreateGenre(ctx, name)
+func (s *Service) CreateGenre(ctx context.Context, name string, parentID *int) (*Genre, error) {
+	return s.repo.CreateGenre(ctx, name, parentID)
+}
+
+func (s *Service) UpdateGenre(ctx context.Context, id int, name string, parentID *int, version *int) (int, error) {
+	return s.repo.UpdateGenre(ctx, id, name, parentID, version)
+}
+
+func (s *Service) DeleteGenre(ctx context.Context, id int, version *int) error {
+	return s.repo.DeleteGenre(ctx, id, version)
 }
 
-func (s *Service) UpdateGenre(ctx context.Context, id int, name string) error {
-	return s.repo.UpdateGenre(ctx, id, name)
+func (s *Service) CreateAlias(ctx context.Context, id int, alias string) (*Genre, error) {
+	err := s.repo.CreateAlias(ctx, id, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetGenreByID(ctx, id)
+}
+
+func (s *Service) DeleteAlias(ctx context.Context, id int, alias string) error {
+	return s.repo.DeleteAlias(ctx, id, alias)
+}
+
+func (s *Service) Merge(ctx context.Context, sourceID, targetID int) (*Genre, error) {
+	if sourceID == targetID {
+		return nil, apperrors.BadRequest(errors.New("genre can't be merged into itself"))
+	}
+
+	err := s.repo.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("genres merged",
+		"source_id", sourceID,
+		"target_id", targetID)
+
+	return s.repo.GetGenreByID(ctx, targetID)
 }
 
-func (s *Service) DeleteGenre(ctx context.Context, id int) error {
-	return s.repo.DeleteGenre(ctx, id)
+func (s *Service) GetGenresByMovieIDs(ctx context.Context, ids []int) (map[int][]*Genre, error) {
+	return s.repo.GetGenresByMovieIDs(ctx, ids)
 }
 
 func (s *Service) GetGenresByMovieID(ctx context.Context, id int) ([]*Genre, error) {
@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/jwt"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
+
+// errorCodes are put into the extensions of the errors, as GraphQL responses carry no HTTP status of their own.
+var errorCodes = map[apperrors.Code]string{
+	apperrors.InternalCode:        "INTERNAL",
+	apperrors.BadRequestCode:      "BAD_REQUEST",
+	apperrors.NotFoundCode:        "NOT_FOUND",
+	apperrors.AlreadyExistsCode:   "ALREADY_EXISTS",
+	apperrors.UnauthorizedCode:    "UNAUTHORIZED",
+	apperrors.ForbiddenCode:       "FORBIDDEN",
+	apperrors.VersionMismatchCode: "VERSION_MISMATCH",
+}
+
+type Handler struct {
+	schema   *gql.Schema
+	resolver *Resolver
+}
+
+func NewHandler(schema *gql.Schema, resolver *Resolver) *Handler {
+	return &Handler{
+		schema:   schema,
+		resolver: resolver,
+	}
+}
+
+// Query executes a query or a mutation. Like in the other GraphQL APIs, the response is OK as long as
+// the request itself is valid, the errors of the fields are returned along with the data.
+func (h *Handler) Query(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GraphQLRequest](c)
+	if err != nil {
+		return err
+	}
+
+	ctx := withClaims(c.Request().Context(), jwt.GetClaims(c))
+	ctx = withLoaders(ctx, h.resolver.newLoaders())
+
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	for _, queryErr := range res.Errors {
+		toSafeError(ctx, queryErr)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// toSafeError replaces the message of a resolver error with the safe one and logs it the way echox.ErrorHandler does.
+func toSafeError(ctx context.Context, queryErr *gqlerrors.QueryError) {
+	if queryErr.ResolverError == nil {
+		return
+	}
+
+	var appError *apperrors.Error
+	if !errors.As(queryErr.ResolverError, &appError) {
+		appError = apperrors.InternalWithoutStackTrace(queryErr.ResolverError)
+	}
+
+	logger := log.FromContext(ctx)
+	if appError.Code == apperrors.InternalCode {
+		logger.Error("server error",
+			"message", queryErr.ResolverError.Error(),
+			"incidentId", appError.IncidentID,
+			"stack trace", appError.StackTrace)
+	} else {
+		logger.Warn("client error",
+			"message", queryErr.ResolverError.Error())
+	}
+
+	queryErr.Message = appError.SafeError()
+	queryErr.Extensions = map[string]any{"code": errorCodes[appError.Code]}
+	if appError.IncidentID != "" {
+		queryErr.Extensions["incident_id"] = appError.IncidentID
+	}
+}
+
+// panicHandler turns panics of the resolvers into internal errors, so that they are logged and hidden like the others.
+type panicHandler struct{}
+
+func (panicHandler) MakePanicError(_ context.Context, value any) *gqlerrors.QueryError {
+	err := apperrors.Internal(fmt.Errorf("resolver panicked: %v", value))
+	return &gqlerrors.QueryError{
+		Message:       err.Error(),
+		ResolverError: err,
+	}
+}
@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader loads values by keys in batches within a single request. The keys of a list, e.g. the ids of the movies
+// of a page, are primed ahead, so the first Load fetches all of them at once instead of one query per item.
+// Keys loaded while a batch is being fetched are collected into the next one.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	// fetchMu serializes the batches, mu guards the keys and the values
+	fetchMu sync.Mutex
+	mu      sync.Mutex
+	pending map[K]struct{}
+	values  map[K]V
+}
+
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		values:  make(map[K]V),
+	}
+}
+
+// Prime adds the keys to the next batch, nothing is fetched until a value is loaded.
+func (l *Loader[K, V]) Prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := l.values[key]; !ok {
+			l.pending[key] = struct{}{}
+		}
+	}
+}
+
+// Load returns the value of the key, or the zero value if the fetch didn't return one.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	if v, ok := l.cached(key); ok {
+		return v, nil
+	}
+	l.Prime(key)
+
+	l.fetchMu.Lock()
+	defer l.fetchMu.Unlock()
+
+	l.mu.Lock()
+	if v, ok := l.values[key]; ok {
+		l.mu.Unlock()
+		return v, nil
+	}
+	// the key is added again in case the batch it was in has failed
+	l.pending[key] = struct{}{}
+	keys := make([]K, 0, len(l.pending))
+	for k := range l.pending {
+		keys = append(keys, k)
+	}
+	l.pending = make(map[K]struct{})
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		l.values[k] = values[k]
+	}
+	return l.values[key], nil
+}
+
+func (l *Loader[K, V]) cached(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	v, ok := l.values[key]
+	return v, ok
+}
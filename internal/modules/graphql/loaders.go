@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+type loadersKey struct{}
+
+// loaders are the batch loaders of a request. Nothing is fetched until a field selects a relation, then it's
+// fetched for all the siblings of the list the entity came from, so that a list takes one query per relation.
+type loaders struct {
+	movies      *Loader[int, *movies.Movie]
+	genres      *Loader[int, *genres.Genre]
+	movieGenres *Loader[int, []*genres.Genre]
+	cast        *Loader[int, []*stars.MovieCredit]
+	credits     *Loader[int, []*stars.StarCredit]
+	users       *Loader[int, *users.User]
+	// movieReviews and userReviews load the first pages of the reviews, the next ones are queried per entity
+	movieReviews *Loader[reviewsKey, *pagination.Page[reviews.Review]]
+	userReviews  *Loader[reviewsKey, *pagination.Page[reviews.Review]]
+
+	// resolver queries the pages of the reviews past the first ones
+	resolver *Resolver
+	// nodes is the number of the list items resolved so far, the query fails once it exceeds maxNodes
+	nodes    atomic.Int64
+	maxNodes int
+}
+
+func (r *Resolver) newLoaders() *loaders {
+	return &loaders{
+		movies: NewLoader(r.moviesRepo.GetMoviesByIDs),
+		genres: NewLoader(func(ctx context.Context, _ []int) (map[int]*genres.Genre, error) {
+			// there are few genres, all of them are loaded at once
+			gs, err := r.genresService.GetGenres(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return slices.ToMap(gs, func(g *genres.Genre) int { return g.ID }, func(g *genres.Genre) *genres.Genre { return g }), nil
+		}),
+		movieGenres:  NewLoader(r.genresService.GetGenresByMovieIDs),
+		cast:         NewLoader(r.starsService.GetCastByMovieIDs),
+		credits:      NewLoader(r.starsRepo.GetCreditsByStarIDs),
+		users:        NewLoader(r.usersService.GetUsersByIDs),
+		movieReviews: newFirstPagesLoader(r.reviewsService.GetFirstPagesByMovieIDs),
+		userReviews:  newFirstPagesLoader(r.reviewsService.GetFirstPagesByUserIDs),
+		resolver:     r,
+		maxNodes:     r.maxNodes,
+	}
+}
+
+// reviewsKey is the first page of the reviews of a movie or a user, paged the way the arguments of the field ask.
+type reviewsKey struct {
+	id   int
+	sort string
+	size int
+}
+
+func newFirstPagesLoader(
+	fetch func(ctx context.Context, ids []int, sort *string, limit int) (map[int]*pagination.Page[reviews.Review], error),
+) *Loader[reviewsKey, *pagination.Page[reviews.Review]] {
+	return NewLoader(func(ctx context.Context, keys []reviewsKey) (map[reviewsKey]*pagination.Page[reviews.Review], error) {
+		// the fields of the siblings may be paged differently under different aliases, each paging takes a query
+		ids := make(map[reviewsKey][]int)
+		for _, key := range keys {
+			paging := reviewsKey{sort: key.sort, size: key.size}
+			ids[paging] = append(ids[paging], key.id)
+		}
+
+		pages := make(map[reviewsKey]*pagination.Page[reviews.Review], len(keys))
+		for paging, pagingIDs := range ids {
+			var sort *string
+			if paging.sort != "" {
+				sort = &paging.sort
+			}
+			byID, err := fetch(ctx, pagingIDs, sort, paging.size)
+			if err != nil {
+				return nil, err
+			}
+			for id, page := range byID {
+				pages[reviewsKey{id: id, sort: paging.sort, size: paging.size}] = page
+			}
+		}
+		return pages, nil
+	})
+}
+
+// reviews resolves the reviews of a movie or a user. The first pages are loaded for all the siblings at once,
+// once a client passes after the next ones are queried one entity at a time like the reviews query.
+func (l *loaders) reviews(
+	ctx context.Context, loader *Loader[reviewsKey, *pagination.Page[reviews.Review]], args reviewsArgs, id int, siblingIDs []int,
+) (*pageResolver[*reviewResolver], error) {
+	if args.After != nil {
+		return l.resolver.Reviews(ctx, args)
+	}
+
+	req, params, err := l.resolver.resolveReviews(args)
+	if err != nil {
+		return nil, err
+	}
+	key := func(id int) reviewsKey {
+		k := reviewsKey{id: id, size: req.Size}
+		if req.Sort != nil {
+			k.sort = *req.Sort
+		}
+		return k
+	}
+
+	loader.Prime(slices.Map(siblingIDs, key)...)
+	page, err := loader.Load(ctx, key(id))
+	if err != nil {
+		return nil, err
+	}
+	return newReviewPage(l, req, params, page)
+}
+
+// count adds the items of a resolved list to the nodes of the query. Every nested list multiplies the size
+// of the result, the depth limit alone doesn't keep it bounded.
+func (l *loaders) count(n int) error {
+	if l.nodes.Add(int64(n)) > int64(l.maxNodes) {
+		return apperrors.BadRequest(fmt.Errorf("query returns more than %d objects, select fewer relations or smaller pages", l.maxNodes))
+	}
+	return nil
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
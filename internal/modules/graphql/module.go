@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	_ "embed"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+)
+
+//go:embed schema.graphql
+var schema string
+
+type Module struct {
+	Handler  *Handler
+	Resolver *Resolver
+}
+
+func NewModule(moviesModule *movies.Module, genresModule *genres.Module, starsModule *stars.Module, reviewsModule *reviews.Module, usersModule *users.Module, cfg config.GraphQLConfig, paginationConfig config.PaginationConfig) *Module {
+	resolver := &Resolver{
+		moviesService:    moviesModule.Service,
+		moviesRepo:       moviesModule.Repository,
+		genresService:    genresModule.Service,
+		starsService:     starsModule.Service,
+		starsRepo:        starsModule.Repository,
+		reviewsService:   reviewsModule.Service,
+		usersService:     usersModule.Service,
+		paginationConfig: paginationConfig,
+		maxNodes:         cfg.MaxNodes,
+	}
+	s := gql.MustParseSchema(schema, resolver,
+		gql.MaxDepth(cfg.MaxDepth),
+		gql.MaxParallelism(cfg.MaxParallelism),
+		gql.PanicHandler(panicHandler{}))
+
+	return &Module{
+		Handler:  NewHandler(s, resolver),
+		Resolver: resolver,
+	}
+}
@@ -0,0 +1,503 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/jwt"
+	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"gopkg.in/validator.v2"
+)
+
+type claimsKey struct{}
+
+// Resolver is the root of the schema, the relations of the returned entities are resolved by the loaders of the request.
+type Resolver struct {
+	moviesService    *movies.Service
+	moviesRepo       *movies.Repository
+	genresService    *genres.Service
+	starsService     *stars.Service
+	starsRepo        *stars.Repository
+	reviewsService   *reviews.Service
+	usersService     *users.Service
+	paginationConfig config.PaginationConfig
+	maxNodes         int
+}
+
+func (r *Resolver) Movie(ctx context.Context, args struct{ ID int32 }) (*movieResolver, error) {
+	id := int(args.ID)
+	movie, err := r.moviesRepo.GetMovieByID(ctx, id)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		// like the REST API redirects, a merged duplicate resolves to the movie it was merged into
+		targetID, mergedErr := r.moviesRepo.GetMergedInto(ctx, id)
+		if mergedErr != nil || targetID == nil {
+			return nil, mergedErr
+		}
+		movie, err = r.moviesRepo.GetMovieByID(ctx, *targetID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newMovieResolvers(loadersFromContext(ctx), []*movies.Movie{&movie.Movie})[0], nil
+}
+
+type moviesArgs struct {
+	StarID   *int32
+	Kind     *string
+	ParentID *int32
+	Search   *string
+	Sort     *string
+	Size     *int32
+	After    *string
+}
+
+func (r *Resolver) Movies(ctx context.Context, args moviesArgs) (*pageResolver[*movieResolver], error) {
+	req := &contracts.GetMoviesRequest{
+		PaginatedRequest: toPaginatedRequest(args.Size, args.After),
+		StarID:           toIntPtr(args.StarID),
+		Kind:             args.Kind,
+		ParentID:         toIntPtr(args.ParentID),
+		SearchTerm:       args.Search,
+		Sort:             args.Sort,
+	}
+	params, err := r.resolvePagination(req, &req.PaginatedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &movies.Filter{
+		StarID:     req.StarID,
+		Kind:       req.Kind,
+		ParentID:   req.ParentID,
+		SearchTerm: req.SearchTerm,
+	}
+	page, err := r.moviesService.GetAllPaginated(ctx, filter, req.Sort, nil, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res := pagination.Response(&req.PaginatedRequest, params, page)
+	l := loadersFromContext(ctx)
+	if err = l.count(len(res.Items)); err != nil {
+		return nil, err
+	}
+	return newPage(newMovieResolvers(l, res.Items), res), nil
+}
+
+func (r *Resolver) Star(ctx context.Context, args struct{ ID int32 }) (*starResolver, error) {
+	id := int(args.ID)
+	star, err := r.starsRepo.GetStarByID(ctx, id)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		targetID, mergedErr := r.starsRepo.GetMergedInto(ctx, id)
+		if mergedErr != nil || targetID == nil {
+			return nil, mergedErr
+		}
+		star, err = r.starsRepo.GetStarByID(ctx, *targetID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newStarResolvers(loadersFromContext(ctx), []*stars.Star{&star.Star})[0], nil
+}
+
+type starsArgs struct {
+	MovieID *int32
+	Sort    *string
+	Size    *int32
+	After   *string
+}
+
+func (r *Resolver) Stars(ctx context.Context, args starsArgs) (*pageResolver[*starResolver], error) {
+	req := &contracts.GetStarsRequest{
+		PaginatedRequest: toPaginatedRequest(args.Size, args.After),
+		MovieID:          toIntPtr(args.MovieID),
+		Sort:             args.Sort,
+	}
+	params, err := r.resolvePagination(req, &req.PaginatedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.starsService.GetAllPaginated(ctx, req.MovieID, req.Sort, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res := pagination.Response(&req.PaginatedRequest, params, page)
+	l := loadersFromContext(ctx)
+	if err = l.count(len(res.Items)); err != nil {
+		return nil, err
+	}
+	return newPage(newStarResolvers(l, res.Items), res), nil
+}
+
+func (r *Resolver) Genre(ctx context.Context, args struct{ ID int32 }) (*genreResolver, error) {
+	genre, err := r.genresService.GetGenreByID(ctx, int(args.ID))
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &genreResolver{g: genre, l: loadersFromContext(ctx)}, nil
+}
+
+func (r *Resolver) Genres(ctx context.Context) ([]*genreResolver, error) {
+	gs, err := r.genresService.GetGenres(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l := loadersFromContext(ctx)
+	if err = l.count(len(gs)); err != nil {
+		return nil, err
+	}
+	return newGenreResolvers(l, gs), nil
+}
+
+func (r *Resolver) Review(ctx context.Context, args struct{ ID int32 }) (*reviewResolver, error) {
+	review, err := r.reviewsService.GetByID(ctx, int(args.ID), nil)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newReviewResolvers(loadersFromContext(ctx), []*reviews.Review{review})[0], nil
+}
+
+type reviewsArgs struct {
+	MovieID *int32
+	UserID  *int32
+	Sort    *string
+	Size    *int32
+	After   *string
+}
+
+// reviewPageArgs are the arguments of the reviews of a movie or a user.
+type reviewPageArgs struct {
+	Sort  *string
+	Size  *int32
+	After *string
+}
+
+func (r *Resolver) Reviews(ctx context.Context, args reviewsArgs) (*pageResolver[*reviewResolver], error) {
+	if args.MovieID == nil && args.UserID == nil {
+		return nil, apperrors.BadRequest(errors.New("either movieId or userId must be provided"))
+	}
+
+	req, params, err := r.resolveReviews(args)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.reviewsService.GetPaginated(ctx, req.MovieID, req.UserID, req.Sort, nil, params)
+	if err != nil {
+		return nil, err
+	}
+	return newReviewPage(loadersFromContext(ctx), req, params, page)
+}
+
+func (r *Resolver) resolveReviews(args reviewsArgs) (*contracts.GetReviewsRequest, *pagination.Params, error) {
+	req := &contracts.GetReviewsRequest{
+		PaginatedRequest: toPaginatedRequest(args.Size, args.After),
+		MovieID:          toIntPtr(args.MovieID),
+		UserID:           toIntPtr(args.UserID),
+		Sort:             args.Sort,
+	}
+	params, err := r.resolvePagination(req, &req.PaginatedRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, params, nil
+}
+
+func newReviewPage(l *loaders, req *contracts.GetReviewsRequest, params *pagination.Params, page *pagination.Page[reviews.Review]) (*pageResolver[*reviewResolver], error) {
+	res := pagination.Response(&req.PaginatedRequest, params, page)
+	if err := l.count(len(res.Items)); err != nil {
+		return nil, err
+	}
+	return newPage(newReviewResolvers(l, res.Items), res), nil
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ ID int32 }) (*userResolver, error) {
+	user, err := r.usersService.GetUserByID(ctx, int(args.ID))
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{u: user, l: loadersFromContext(ctx)}, nil
+}
+
+type genreInput struct {
+	Name     string
+	ParentID *int32
+}
+
+func (r *Resolver) CreateGenre(ctx context.Context, args struct{ Input genreInput }) (*genreResolver, error) {
+	if err := requireEditor(ctx); err != nil {
+		return nil, err
+	}
+
+	req := &contracts.CreateGenreRequest{
+		Name:     args.Input.Name,
+		ParentID: toIntPtr(args.Input.ParentID),
+	}
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	genre, err := r.genresService.CreateGenre(ctx, req.Name, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return &genreResolver{g: genre, l: loadersFromContext(ctx)}, nil
+}
+
+func (r *Resolver) DeleteGenre(ctx context.Context, args struct{ ID int32 }) (bool, error) {
+	if err := requireEditor(ctx); err != nil {
+		return false, err
+	}
+
+	if err := r.genresService.DeleteGenre(ctx, int(args.ID), nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type starInput struct {
+	FirstName  string
+	MiddleName *string
+	LastName   string
+	BirthDate  gql.Time
+	BirthPlace *string
+	DeathDate  *gql.Time
+	Bio        *string
+}
+
+func (r *Resolver) CreateStar(ctx context.Context, args struct{ Input starInput }) (*starResolver, error) {
+	if err := requireEditor(ctx); err != nil {
+		return nil, err
+	}
+
+	req := &contracts.CreateStarRequest{
+		FirstName:  args.Input.FirstName,
+		MiddleName: args.Input.MiddleName,
+		LastName:   args.Input.LastName,
+		BirthDate:  args.Input.BirthDate.Time,
+		BirthPlace: args.Input.BirthPlace,
+		Bio:        args.Input.Bio,
+	}
+	if args.Input.DeathDate != nil {
+		req.DeathDate = &args.Input.DeathDate.Time
+	}
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	star := &stars.StarDetails{
+		Star: stars.Star{
+			FirstName: req.FirstName,
+			LastName:  req.LastName,
+			BirthDate: req.BirthDate,
+			DeathDate: req.DeathDate,
+		},
+		MiddleName: req.MiddleName,
+		BirthPlace: req.BirthPlace,
+		Bio:        req.Bio,
+	}
+	if err := r.starsService.CreateStar(ctx, star); err != nil {
+		return nil, err
+	}
+	return newStarResolvers(loadersFromContext(ctx), []*stars.Star{&star.Star})[0], nil
+}
+
+func (r *Resolver) DeleteStar(ctx context.Context, args struct{ ID int32 }) (bool, error) {
+	if err := requireEditor(ctx); err != nil {
+		return false, err
+	}
+
+	if err := r.starsService.DeleteStar(ctx, int(args.ID), nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type createReviewInput struct {
+	MovieID int32
+	Rating  int32
+	Title   string
+	Content string
+}
+
+func (r *Resolver) CreateReview(ctx context.Context, args struct {
+	UserID int32
+	Input  createReviewInput
+},
+) (*reviewResolver, error) {
+	if err := requireSelf(ctx, int(args.UserID)); err != nil {
+		return nil, err
+	}
+
+	req := &contracts.CreateReviewRequest{
+		MovieID: int(args.Input.MovieID),
+		UserID:  int(args.UserID),
+		Rating:  int(args.Input.Rating),
+		Title:   args.Input.Title,
+		Content: args.Input.Content,
+	}
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	review := &reviews.Review{
+		MovieID: req.MovieID,
+		UserID:  req.UserID,
+		Rating:  req.Rating,
+		Title:   req.Title,
+		Content: req.Content,
+	}
+	if err := r.reviewsService.Create(ctx, review); err != nil {
+		return nil, err
+	}
+	return newReviewResolvers(loadersFromContext(ctx), []*reviews.Review{review})[0], nil
+}
+
+type updateReviewInput struct {
+	Rating  int32
+	Title   string
+	Content string
+}
+
+func (r *Resolver) UpdateReview(ctx context.Context, args struct {
+	UserID int32
+	ID     int32
+	Input  updateReviewInput
+},
+) (*reviewResolver, error) {
+	if err := requireSelf(ctx, int(args.UserID)); err != nil {
+		return nil, err
+	}
+
+	req := &contracts.UpdateReviewRequest{
+		ReviewID: int(args.ID),
+		UserID:   int(args.UserID),
+		Rating:   int(args.Input.Rating),
+		Title:    args.Input.Title,
+		Content:  args.Input.Content,
+	}
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	_, err := r.reviewsService.Update(ctx, req.ReviewID, req.UserID, req.Title, req.Content, req.Rating, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := r.reviewsService.GetByID(ctx, req.ReviewID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newReviewResolvers(loadersFromContext(ctx), []*reviews.Review{review})[0], nil
+}
+
+func (r *Resolver) DeleteReview(ctx context.Context, args struct {
+	UserID int32
+	ID     int32
+},
+) (bool, error) {
+	if err := requireSelf(ctx, int(args.UserID)); err != nil {
+		return false, err
+	}
+
+	if err := r.reviewsService.Delete(ctx, int(args.ID), int(args.UserID), nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resolvePagination validates the list request, which carries the same rules as its REST counterpart.
+func (r *Resolver) resolvePagination(req any, paginated *contracts.PaginatedRequest) (*pagination.Params, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+	return pagination.Resolve(paginated, r.paginationConfig)
+}
+
+// requireEditor is the counterpart of auth.Editor for mutations.
+func requireEditor(ctx context.Context) error {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if claims.Role == users.AdminRole || claims.Role == users.EditorRole {
+		return nil
+	}
+	return apperrors.Forbidden("insufficient permissions")
+}
+
+// requireSelf is the counterpart of auth.Self for mutations.
+func requireSelf(ctx context.Context, userID int) error {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if claims.Role == users.AdminRole || claims.Subject == strconv.Itoa(userID) {
+		return nil
+	}
+	return apperrors.Forbidden("insufficient permissions")
+}
+
+func withClaims(ctx context.Context, claims *jwt.AccessClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func claimsFromContext(ctx context.Context) (*jwt.AccessClaims, error) {
+	claims, _ := ctx.Value(claimsKey{}).(*jwt.AccessClaims)
+	if claims == nil {
+		return nil, apperrors.Unauthorized("invalid or missing token")
+	}
+	return claims, nil
+}
+
+func newPage[T, I any](items []T, res *contracts.PaginatedResponse[I]) *pageResolver[T] {
+	page := &pageResolver[T]{items: items, next: res.Next}
+	if !res.Uncounted {
+		total := int32(res.Total)
+		page.total = &total
+	}
+	return page
+}
+
+func toPaginatedRequest(size *int32, after *string) contracts.PaginatedRequest {
+	var req contracts.PaginatedRequest
+	if size != nil {
+		req.Size = int(*size)
+	}
+	if after != nil {
+		req.After = *after
+	}
+	return req
+}
+
+func toIntPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
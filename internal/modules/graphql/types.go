@@ -0,0 +1,276 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+)
+
+// movieResolver resolves a movie of a list, the relations selected for it are loaded for its siblings at once.
+type movieResolver struct {
+	m        *movies.Movie
+	l        *loaders
+	siblings []*movies.Movie
+}
+
+func newMovieResolvers(l *loaders, ms []*movies.Movie) []*movieResolver {
+	return slices.Map(ms, func(m *movies.Movie) *movieResolver { return &movieResolver{m: m, l: l, siblings: ms} })
+}
+
+func (r *movieResolver) ID() int32             { return int32(r.m.ID) }
+func (r *movieResolver) Kind() string          { return r.m.Kind }
+func (r *movieResolver) Number() *int32        { return toInt32Ptr(r.m.Number) }
+func (r *movieResolver) Title() string         { return r.m.Title }
+func (r *movieResolver) ReleaseDate() gql.Time { return gql.Time{Time: r.m.ReleaseDate} }
+func (r *movieResolver) Language() string      { return r.m.Language }
+func (r *movieResolver) AvgRating() *float64   { return r.m.AvgRating }
+func (r *movieResolver) CreatedAt() gql.Time   { return gql.Time{Time: r.m.CreatedAt} }
+
+func (r *movieResolver) Parent(ctx context.Context) (*movieResolver, error) {
+	if r.m.ParentID == nil {
+		return nil, nil
+	}
+	for _, m := range r.siblings {
+		if m.ParentID != nil {
+			r.l.movies.Prime(*m.ParentID)
+		}
+	}
+	parent, err := r.l.movies.Load(ctx, *r.m.ParentID)
+	if err != nil || parent == nil {
+		return nil, err
+	}
+	return &movieResolver{m: parent, l: r.l}, nil
+}
+
+func (r *movieResolver) Genres(ctx context.Context) ([]*genreResolver, error) {
+	r.l.movieGenres.Prime(r.siblingIDs()...)
+	gs, err := r.l.movieGenres.Load(ctx, r.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.l.count(len(gs)); err != nil {
+		return nil, err
+	}
+	return newGenreResolvers(r.l, gs), nil
+}
+
+func (r *movieResolver) Cast(ctx context.Context) ([]*creditResolver, error) {
+	r.l.cast.Prime(r.siblingIDs()...)
+	cast, err := r.l.cast.Load(ctx, r.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.l.count(len(cast)); err != nil {
+		return nil, err
+	}
+
+	// the stars of the cast are the siblings of each other
+	ss := slices.Map(cast, func(c *stars.MovieCredit) *stars.Star { return &c.Star })
+	return slices.MapIndex(cast, func(i int, c *stars.MovieCredit) *creditResolver {
+		return &creditResolver{c: c, star: &starResolver{s: ss[i], l: r.l, siblings: ss}}
+	}), nil
+}
+
+// Reviews pages the reviews of the movie the same way as the reviews query.
+func (r *movieResolver) Reviews(ctx context.Context, args reviewPageArgs) (*pageResolver[*reviewResolver], error) {
+	movieID := int32(r.m.ID)
+	return r.l.reviews(ctx, r.l.movieReviews, reviewsArgs{MovieID: &movieID, Sort: args.Sort, Size: args.Size, After: args.After}, r.m.ID, r.siblingIDs())
+}
+
+func (r *movieResolver) siblingIDs() []int {
+	return slices.Map(r.siblings, func(m *movies.Movie) int { return m.ID })
+}
+
+type creditResolver struct {
+	c    *stars.MovieCredit
+	star *starResolver
+}
+
+func (r *creditResolver) Star() *starResolver {
+	return r.star
+}
+
+func (r *creditResolver) Role() string         { return r.c.Role }
+func (r *creditResolver) Department() string   { return r.c.Department }
+func (r *creditResolver) Characters() []string { return nonNil(r.c.Characters) }
+func (r *creditResolver) Job() *string         { return r.c.Job }
+func (r *creditResolver) Uncredited() bool     { return r.c.Uncredited }
+func (r *creditResolver) Voice() bool          { return r.c.Voice }
+func (r *creditResolver) Order() int32         { return int32(r.c.OrderNo) }
+
+type starResolver struct {
+	s        *stars.Star
+	l        *loaders
+	siblings []*stars.Star
+}
+
+func newStarResolvers(l *loaders, ss []*stars.Star) []*starResolver {
+	return slices.Map(ss, func(s *stars.Star) *starResolver { return &starResolver{s: s, l: l, siblings: ss} })
+}
+
+func (r *starResolver) ID() int32            { return int32(r.s.ID) }
+func (r *starResolver) FirstName() string    { return r.s.FirstName }
+func (r *starResolver) LastName() string     { return r.s.LastName }
+func (r *starResolver) BirthDate() gql.Time  { return gql.Time{Time: r.s.BirthDate} }
+func (r *starResolver) DeathDate() *gql.Time { return toTimePtr(r.s.DeathDate) }
+func (r *starResolver) CreatedAt() gql.Time  { return gql.Time{Time: r.s.CreatedAt} }
+
+func (r *starResolver) Credits(ctx context.Context) ([]*starCreditResolver, error) {
+	r.l.credits.Prime(slices.Map(r.siblings, func(s *stars.Star) int { return s.ID })...)
+	credits, err := r.l.credits.Load(ctx, r.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.l.count(len(credits)); err != nil {
+		return nil, err
+	}
+	return slices.Map(credits, func(c *stars.StarCredit) *starCreditResolver {
+		return &starCreditResolver{c: c, l: r.l, siblings: credits}
+	}), nil
+}
+
+type starCreditResolver struct {
+	c        *stars.StarCredit
+	l        *loaders
+	siblings []*stars.StarCredit
+}
+
+func (r *starCreditResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	r.l.movies.Prime(slices.Map(r.siblings, func(c *stars.StarCredit) int { return c.Movie.ID })...)
+	m, err := r.l.movies.Load(ctx, r.c.Movie.ID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, apperrors.NotFound("movie", "id", r.c.Movie.ID)
+	}
+	return &movieResolver{m: m, l: r.l}, nil
+}
+
+func (r *starCreditResolver) Role() string         { return r.c.Role }
+func (r *starCreditResolver) Department() string   { return r.c.Department }
+func (r *starCreditResolver) Characters() []string { return nonNil(r.c.Characters) }
+func (r *starCreditResolver) Job() *string         { return r.c.Job }
+func (r *starCreditResolver) Uncredited() bool     { return r.c.Uncredited }
+func (r *starCreditResolver) Voice() bool          { return r.c.Voice }
+
+type genreResolver struct {
+	g *genres.Genre
+	l *loaders
+}
+
+func newGenreResolvers(l *loaders, gs []*genres.Genre) []*genreResolver {
+	return slices.Map(gs, func(g *genres.Genre) *genreResolver { return &genreResolver{g: g, l: l} })
+}
+
+func (r *genreResolver) ID() int32    { return int32(r.g.ID) }
+func (r *genreResolver) Name() string { return r.g.Name }
+
+func (r *genreResolver) Parent(ctx context.Context) (*genreResolver, error) {
+	if r.g.ParentID == nil {
+		return nil, nil
+	}
+	parent, err := r.l.genres.Load(ctx, *r.g.ParentID)
+	if err != nil || parent == nil {
+		return nil, err
+	}
+	return &genreResolver{g: parent, l: r.l}, nil
+}
+
+type reviewResolver struct {
+	r        *reviews.Review
+	l        *loaders
+	siblings []*reviews.Review
+}
+
+func newReviewResolvers(l *loaders, rs []*reviews.Review) []*reviewResolver {
+	return slices.Map(rs, func(review *reviews.Review) *reviewResolver { return &reviewResolver{r: review, l: l, siblings: rs} })
+}
+
+func (r *reviewResolver) ID() int32           { return int32(r.r.ID) }
+func (r *reviewResolver) Rating() int32       { return int32(r.r.Rating) }
+func (r *reviewResolver) Title() string       { return r.r.Title }
+func (r *reviewResolver) Content() string     { return r.r.Content }
+func (r *reviewResolver) CreatedAt() gql.Time { return gql.Time{Time: r.r.CreatedAt} }
+
+func (r *reviewResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	r.l.movies.Prime(slices.Map(r.siblings, func(review *reviews.Review) int { return review.MovieID })...)
+	m, err := r.l.movies.Load(ctx, r.r.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, apperrors.NotFound("movie", "id", r.r.MovieID)
+	}
+	return &movieResolver{m: m, l: r.l}, nil
+}
+
+func (r *reviewResolver) Author(ctx context.Context) (*userResolver, error) {
+	authorIDs := slices.Map(r.siblings, func(review *reviews.Review) int { return review.UserID })
+	r.l.users.Prime(authorIDs...)
+	u, err := r.l.users.Load(ctx, r.r.UserID)
+	if err != nil || u == nil {
+		return nil, err
+	}
+	return &userResolver{u: u, l: r.l, siblingIDs: authorIDs}, nil
+}
+
+// userResolver exposes only the public part of a user, the email is left out.
+type userResolver struct {
+	u *users.User
+	l *loaders
+	// siblingIDs are the ids of the authors of the sibling reviews the user came from
+	siblingIDs []int
+}
+
+func (r *userResolver) ID() int32           { return int32(r.u.ID) }
+func (r *userResolver) Username() string    { return r.u.Username }
+func (r *userResolver) Role() string        { return r.u.Role }
+func (r *userResolver) Bio() *string        { return r.u.Bio }
+func (r *userResolver) CreatedAt() gql.Time { return gql.Time{Time: r.u.CreatedAt} }
+
+func (r *userResolver) Reviews(ctx context.Context, args reviewPageArgs) (*pageResolver[*reviewResolver], error) {
+	userID := int32(r.u.ID)
+	return r.l.reviews(ctx, r.l.userReviews, reviewsArgs{UserID: &userID, Sort: args.Sort, Size: args.Size, After: args.After}, r.u.ID, r.siblingIDs)
+}
+
+// pageResolver is a page of a list, Next is the cursor to pass as after to get the next one.
+type pageResolver[T any] struct {
+	items []T
+	next  *string
+	total *int32
+}
+
+func (r *pageResolver[T]) Items() []T    { return r.items }
+func (r *pageResolver[T]) Next() *string { return r.next }
+func (r *pageResolver[T]) Total() *int32 { return r.total }
+
+func toInt32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
+func toTimePtr(t *time.Time) *gql.Time {
+	if t == nil {
+		return nil
+	}
+	return &gql.Time{Time: *t}
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package images
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+)
+
+// Variants never change once uploaded, so clients may cache them forever.
+const contentCacheControl = "public, max-age=31536000, immutable"
+
+type Handler struct {
+	Service *Service
+	Config  config.ImagesConfig
+}
+
+func NewHandler(service *Service, cfg config.ImagesConfig) *Handler {
+	return &Handler{
+		Service: service,
+		Config:  cfg,
+	}
+}
+
+func (h *Handler) UploadMovieImage(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UploadMovieImageRequest](c)
+	if err != nil {
+		return err
+	}
+
+	return h.upload(c, &Image{MovieID: &req.ID, Kind: req.Kind, Primary: req.Primary})
+}
+
+func (h *Handler) UploadStarImage(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UploadStarImageRequest](c)
+	if err != nil {
+		return err
+	}
+
+	return h.upload(c, &Image{StarID: &req.ID, Kind: KindPhoto, Primary: req.Primary})
+}
+
+func (h *Handler) GetMovieImages(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetImagesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	return h.getByOwner(c, Owner{MovieID: &req.ID})
+}
+
+func (h *Handler) GetStarImages(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetImagesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	return h.getByOwner(c, Owner{StarID: &req.ID})
+}
+
+func (h *Handler) GetImage(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetImageRequest](c)
+	if err != nil {
+		return err
+	}
+
+	image, err := h.Service.GetByID(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, image)
+}
+
+func (h *Handler) GetImageContent(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetImageContentRequest](c)
+	if err != nil {
+		return err
+	}
+
+	content, variant, err := h.Service.Open(c.Request().Context(), req.ID, req.Variant)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderCacheControl, contentCacheControl)
+	return c.Stream(http.StatusOK, variant.ContentType, content)
+}
+
+func (h *Handler) SetPrimaryImage(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.SetPrimaryImageRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.SetPrimary(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteImage(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteImageRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Delete(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) upload(c echo.Context, image *Image) error {
+	data, err := h.readFile(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Upload(c.Request().Context(), image, data)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, image)
+}
+
+func (h *Handler) getByOwner(c echo.Context, owner Owner) error {
+	images, err := h.Service.GetByOwner(c.Request().Context(), owner)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, images)
+}
+
+// readFile reads the uploaded file, making sure it isn't larger than allowed.
+func (h *Handler) readFile(c echo.Context) ([]byte, error) {
+	tooLarge := apperrors.BadRequest(fmt.Errorf("image must not be larger than %d bytes", h.Config.MaxSize))
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, apperrors.BadRequestHidden(err, "file is required")
+	}
+	if header.Size > h.Config.MaxSize {
+		return nil, tooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.Config.MaxSize+1))
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	if int64(len(data)) > h.Config.MaxSize {
+		return nil, tooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package images
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of images. Posters, backdrops and stills belong to movies, photos belong to stars.
+const (
+	KindPoster   = "poster"
+	KindBackdrop = "backdrop"
+	KindStill    = "still"
+	KindPhoto    = "photo"
+)
+
+// Variants of an image, the original is kept as uploaded and the rest are resized copies of it.
+const (
+	VariantOriginal = "original"
+	VariantLarge    = "large"
+	VariantMedium   = "medium"
+	VariantThumb    = "thumb"
+)
+
+type Image struct {
+	ID          int        `json:"id"`
+	MovieID     *int       `json:"movie_id,omitempty"`
+	StarID      *int       `json:"star_id,omitempty"`
+	Kind        string     `json:"kind"`
+	ContentType string     `json:"content_type"`
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	Size        int        `json:"size"`
+	Primary     bool       `json:"primary"`
+	Variants    []*Variant `json:"variants"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type Variant struct {
+	Name        string `json:"name"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	URL         string `json:"url,omitempty"`
+}
+
+// Owner points either to a movie or to a star.
+type Owner struct {
+	MovieID *int
+	StarID  *int
+}
+
+func (o Owner) String() string {
+	if o.MovieID != nil {
+		return fmt.Sprintf("movie:%d", *o.MovieID)
+	}
+	return fmt.Sprintf("star:%d", *o.StarID)
+}
+
+func (i *Image) Owner() Owner {
+	return Owner{MovieID: i.MovieID, StarID: i.StarID}
+}
+
+func (i *Image) variant(name string) *Variant {
+	for _, v := range i.Variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// setURLs points the variants to the endpoint serving their content.
+func (i *Image) setURLs() {
+	for _, v := range i.Variants {
+		v.URL = fmt.Sprintf("/api/images/%d/%s", i.ID, v.Name)
+	}
+}
+
+// storageKey is where the content of a variant is kept.
+func storageKey(imageID int, variant *Variant) string {
+	return fmt.Sprintf("images/%d/%s%s", imageID, variant.Name, extensions[variant.ContentType])
+}
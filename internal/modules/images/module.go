@@ -0,0 +1,25 @@
+package images
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/storage"
+)
+
+type Module struct {
+	Handler    *Handler
+	Service    *Service
+	Repository *Repository
+}
+
+func NewModule(db *pgxpool.Pool, storage storage.Storage, imagesConfig config.ImagesConfig) *Module {
+	repo := NewRepository(db)
+	service := NewService(repo, storage)
+	handler := NewHandler(service, imagesConfig)
+
+	return &Module{
+		Handler:    handler,
+		Service:    service,
+		Repository: repo,
+	}
+}
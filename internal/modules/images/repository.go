@@ -0,0 +1,259 @@
+package images
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+)
+
+const imageColumns = "id, movie_id, star_id, kind, content_type, width, height, size, variants, is_primary, created_at"
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Create adds an image to a non-deleted movie or star. The first image of an owner always becomes the primary one.
+func (r *Repository) Create(ctx context.Context, image *Image) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		if err := r.checkOwner(ctx, image.Owner()); err != nil {
+			return err
+		}
+
+		if image.Primary {
+			_, err := tx.Exec(ctx,
+				"UPDATE images SET is_primary = FALSE WHERE (movie_id = $1 or star_id = $2) and is_primary",
+				image.MovieID, image.StarID)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		queryString := `
+	INSERT INTO images (movie_id, star_id, kind, content_type, width, height, size, variants, is_primary)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+		$9 or NOT EXISTS (SELECT 1 FROM images WHERE (movie_id = $1 or star_id = $2) and is_primary))
+	RETURNING id, is_primary, created_at`
+
+		err := tx.QueryRow(ctx, queryString,
+			image.MovieID,
+			image.StarID,
+			image.Kind,
+			image.ContentType,
+			image.Width,
+			image.Height,
+			image.Size,
+			image.Variants,
+			image.Primary,
+		).Scan(&image.ID, &image.Primary, &image.CreatedAt)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+	return nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Image, error) {
+	q := dbx.FromContext(ctx, r.db)
+	row := q.QueryRow(ctx, "SELECT "+imageColumns+" FROM images WHERE id = $1", id)
+
+	image, err := scanImage(row)
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("image", "id", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return image, nil
+}
+
+// GetByOwner returns the gallery of a movie or a star, the primary image goes first.
+func (r *Repository) GetByOwner(ctx context.Context, owner Owner) ([]*Image, error) {
+	if err := r.checkOwner(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	if owner.MovieID != nil {
+		images, err := r.GetByMovieIDs(ctx, []int{*owner.MovieID}, false)
+		return images[*owner.MovieID], err
+	}
+	images, err := r.GetByStarIDs(ctx, []int{*owner.StarID}, false)
+	return images[*owner.StarID], err
+}
+
+func (r *Repository) GetByMovieIDs(ctx context.Context, movieIDs []int, primaryOnly bool) (map[int][]*Image, error) {
+	return r.getByOwnerIDs(ctx, "movie_id", movieIDs, primaryOnly)
+}
+
+func (r *Repository) GetByStarIDs(ctx context.Context, starIDs []int, primaryOnly bool) (map[int][]*Image, error) {
+	return r.getByOwnerIDs(ctx, "star_id", starIDs, primaryOnly)
+}
+
+func (r *Repository) getByOwnerIDs(ctx context.Context, column string, ids []int, primaryOnly bool) (map[int][]*Image, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := "SELECT " + imageColumns + " FROM images WHERE " + column + " = ANY($1) and (is_primary or not $2) ORDER BY is_primary DESC, id"
+
+	rows, err := q.Query(ctx, queryString, ids, primaryOnly)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	images := make(map[int][]*Image, len(ids))
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		ownerID := image.StarID
+		if column == "movie_id" {
+			ownerID = image.MovieID
+		}
+		images[*ownerID] = append(images[*ownerID], image)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return images, nil
+}
+
+// DeleteByOwners deletes the images of the movies and the stars, it's meant to be run in the transaction
+// removing the owners so that the returned images are exactly the ones gone with them.
+func (r *Repository) DeleteByOwners(ctx context.Context, movieIDs, starIDs []int) ([]*Image, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := "DELETE FROM images WHERE movie_id = ANY($1) or star_id = ANY($2) RETURNING " + imageColumns
+
+	rows, err := q.Query(ctx, queryString, movieIDs, starIDs)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var images []*Image
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		images = append(images, image)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return images, nil
+}
+
+// SetPrimary makes the image the primary one of its owner.
+func (r *Repository) SetPrimary(ctx context.Context, id int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		image, err := r.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// unset the current primary image first, otherwise the unique index is violated
+		_, err = tx.Exec(ctx,
+			"UPDATE images SET is_primary = FALSE WHERE (movie_id = $1 or star_id = $2) and is_primary and id <> $3",
+			image.MovieID, image.StarID, id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE images SET is_primary = TRUE WHERE id = $1", id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+	return nil
+}
+
+// Delete removes an image and returns it. When the primary image is deleted the oldest remaining one takes its place.
+func (r *Repository) Delete(ctx context.Context, id int) (*Image, error) {
+	var image *Image
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		image, err = scanImage(tx.QueryRow(ctx, "DELETE FROM images WHERE id = $1 RETURNING "+imageColumns, id))
+		if dbx.IsNoRows(err) {
+			return apperrors.NotFound("image", "id", id)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if !image.Primary {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx, `
+	UPDATE images SET is_primary = TRUE
+	WHERE id = (SELECT min(id) FROM images WHERE movie_id = $1 or star_id = $2)`,
+			image.MovieID, image.StarID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.EnsureInternal(err)
+	}
+	return image, nil
+}
+
+func (r *Repository) checkOwner(ctx context.Context, owner Owner) error {
+	q := dbx.FromContext(ctx, r.db)
+
+	subject, table, id := "movie", "movies", owner.MovieID
+	if owner.StarID != nil {
+		subject, table, id = "star", "stars", owner.StarID
+	}
+
+	var exists bool
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = $1 and deleted_at IS NULL)", *id).Scan(&exists)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if !exists {
+		return apperrors.NotFound(subject, "id", *id)
+	}
+	return nil
+}
+
+func scanImage(row pgx.Row) (*Image, error) {
+	var image Image
+	err := row.Scan(
+		&image.ID,
+		&image.MovieID,
+		&image.StarID,
+		&image.Kind,
+		&image.ContentType,
+		&image.Width,
+		&image.Height,
+		&image.Size,
+		&image.Variants,
+		&image.Primary,
+		&image.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	image.setURLs()
+	return &image, nil
+}
@@ -0,0 +1,152 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/storage"
+)
+
+type Service struct {
+	repo    *Repository
+	storage storage.Storage
+}
+
+func NewService(repo *Repository, storage storage.Storage) *Service {
+	return &Service{
+		repo:    repo,
+		storage: storage,
+	}
+}
+
+// Upload validates the image, stores it with all its variants and attaches it to the owner.
+func (s *Service) Upload(ctx context.Context, image *Image, data []byte) error {
+	variants, err := process(data)
+	if err != nil {
+		return err
+	}
+
+	original := variants[0]
+	image.ContentType = original.ContentType
+	image.Width = original.Width
+	image.Height = original.Height
+	image.Size = original.Size
+	image.Variants = nil
+	for _, v := range variants {
+		image.Variants = append(image.Variants, v.Variant)
+	}
+
+	if err = s.repo.Create(ctx, image); err != nil {
+		return err
+	}
+
+	for _, v := range variants {
+		err = s.storage.Put(ctx, storageKey(image.ID, v.Variant), bytes.NewReader(v.data), int64(len(v.data)), v.ContentType)
+		if err != nil {
+			// don't leave images without content behind
+			if _, derr := s.repo.Delete(ctx, image.ID); derr != nil {
+				err = errors.Join(err, derr)
+			}
+			s.deleteContent(ctx, image)
+			return apperrors.Internal(err)
+		}
+	}
+	image.setURLs()
+
+	logger := log.FromContext(ctx)
+	logger.Info("image uploaded",
+		"image_id", image.ID,
+		"owner", image.Owner().String(),
+	)
+
+	return nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Image, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) GetByOwner(ctx context.Context, owner Owner) ([]*Image, error) {
+	return s.repo.GetByOwner(ctx, owner)
+}
+
+func (s *Service) GetByMovieIDs(ctx context.Context, movieIDs []int, primaryOnly bool) (map[int][]*Image, error) {
+	return s.repo.GetByMovieIDs(ctx, movieIDs, primaryOnly)
+}
+
+func (s *Service) GetByStarIDs(ctx context.Context, starIDs []int, primaryOnly bool) (map[int][]*Image, error) {
+	return s.repo.GetByStarIDs(ctx, starIDs, primaryOnly)
+}
+
+// Open returns the content of an image variant, the caller must close it.
+func (s *Service) Open(ctx context.Context, id int, variantName string) (io.ReadCloser, *Variant, error) {
+	image, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variant := image.variant(variantName)
+	if variant == nil {
+		return nil, nil, apperrors.NotFound("image variant", "name", variantName)
+	}
+
+	content, err := s.storage.Get(ctx, storageKey(id, variant))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperrors.NotFound("image variant", "name", variantName)
+	}
+	if err != nil {
+		return nil, nil, apperrors.Internal(err)
+	}
+	return content, variant, nil
+}
+
+func (s *Service) SetPrimary(ctx context.Context, id int) error {
+	err := s.repo.SetPrimary(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("primary image set",
+		"image_id", id)
+
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	image, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.deleteContent(ctx, image)
+
+	logger := log.FromContext(ctx)
+	logger.Info("image deleted",
+		"image_id", id)
+
+	return nil
+}
+
+// DeleteContent removes the stored variants of the images whose rows are already gone, e.g. purged with their owners.
+func (s *Service) DeleteContent(ctx context.Context, images []*Image) {
+	for _, image := range images {
+		s.deleteContent(ctx, image)
+	}
+}
+
+// deleteContent removes the stored variants. Failures are only logged, orphaned content is harmless.
+func (s *Service) deleteContent(ctx context.Context, image *Image) {
+	logger := log.FromContext(ctx)
+	for _, v := range image.Variants {
+		if err := s.storage.Delete(ctx, storageKey(image.ID, v)); err != nil {
+			logger.Warn("delete image content",
+				"image_id", image.ID,
+				"variant", v.Name,
+				"err", err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"golang.org/x/image/draw"
+
+	// register the webp decoder for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxPixels protects from decompression bombs, i.e. small files of enormous dimensions
+	maxPixels   = 50_000_000
+	jpegQuality = 85
+)
+
+// extensions maps the supported content types to file extensions
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// resizedVariants are generated only when the original is wider than their width.
+var resizedVariants = []struct {
+	name  string
+	width int
+}{
+	{VariantLarge, 1280},
+	{VariantMedium, 500},
+	{VariantThumb, 185},
+}
+
+type encodedVariant struct {
+	*Variant
+	data []byte
+}
+
+// process validates an uploaded image and generates its resized variants, the original goes first.
+func process(data []byte) ([]*encodedVariant, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return nil, apperrors.BadRequest(fmt.Errorf("unsupported image type %s, expected jpeg, png or webp", contentType))
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid image")
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, apperrors.BadRequest(fmt.Errorf("image must not have more than %d pixels", maxPixels))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperrors.BadRequestHidden(err, "invalid image")
+	}
+
+	variants := []*encodedVariant{{
+		Variant: &Variant{
+			Name:        VariantOriginal,
+			Width:       cfg.Width,
+			Height:      cfg.Height,
+			ContentType: contentType,
+			Size:        len(data),
+		},
+		data: data,
+	}}
+
+	for _, rv := range resizedVariants {
+		if rv.width >= cfg.Width {
+			continue
+		}
+
+		height := cfg.Height * rv.width / cfg.Width
+		if height == 0 {
+			height = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, rv.width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+		// PNGs may be transparent, everything else is re-encoded as JPEG
+		var buf bytes.Buffer
+		variantType := "image/jpeg"
+		if contentType == "image/png" {
+			variantType = contentType
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		variants = append(variants, &encodedVariant{
+			Variant: &Variant{
+				Name:        rv.name,
+				Width:       rv.width,
+				Height:      height,
+				ContentType: variantType,
+				Size:        buf.Len(),
+			},
+			data: buf.Bytes(),
+		})
+	}
+
+	return variants, nil
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Handler struct {
+	Service          *Service
+	PaginationConfig config.PaginationConfig
+}
+
+func NewHandler(service *Service, cfg config.PaginationConfig) *Handler {
+	return &Handler{
+		Service:          service,
+		PaginationConfig: cfg,
+	}
+}
+
+func (h *Handler) GetAll(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetJobsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Service.GetAllPaginated(c.Request().Context(), req.Status, req.Kind, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, pagination.Response(&req.PaginatedRequest, params, page))
+}
+
+func (h *Handler) Get(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetJobRequest](c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.Service.GetByID(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *Handler) Enqueue(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.EnqueueJobRequest](c)
+	if err != nil {
+		return err
+	}
+
+	var payload any
+	if len(req.Payload) > 0 {
+		payload = req.Payload
+	}
+
+	job, err := h.Service.Enqueue(c.Request().Context(), req.Kind, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+func (h *Handler) Retry(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.RetryJobRequest](c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.Service.Retry(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
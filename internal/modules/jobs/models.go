@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/cron"
+)
+
+// Statuses of a job. A failed job is retried with a backoff until it runs out of attempts,
+// then it stays failed until an admin retries it.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// jobColumns are the columns scanned by Job.scanDest.
+const jobColumns = "id, kind, payload, status, attempts, max_attempts, run_at, last_error, created_at, finished_at, claims"
+
+type Job struct {
+	ID          int             `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LastError   *string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+
+	// claim identifies the claim of the job a worker holds, see Repository.Claim
+	claim int
+}
+
+func (j *Job) scanDest() []any {
+	return []any{
+		&j.ID,
+		&j.Kind,
+		&j.Payload,
+		&j.Status,
+		&j.Attempts,
+		&j.MaxAttempts,
+		&j.RunAt,
+		&j.LastError,
+		&j.CreatedAt,
+		&j.FinishedAt,
+		&j.claim,
+	}
+}
+
+// HandlerFunc runs a job of a kind. The context is cancelled if the server shuts down before the job is done,
+// the job is then retried later.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type jobKey struct{}
+
+func withJob(ctx context.Context, job *Job) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// IsLastAttempt tells a handler whether the job it runs is not going to be retried if it fails,
+// e.g. to record the final outcome on its own.
+func IsLastAttempt(ctx context.Context) bool {
+	job, ok := ctx.Value(jobKey{}).(*Job)
+	return ok && job.Attempts >= job.MaxAttempts
+}
+
+type schedule struct {
+	name string
+	kind string
+	spec string
+	cron *cron.Schedule
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+)
+
+type Module struct {
+	Handler    *Handler
+	Service    *Service
+	Repository *Repository
+	Worker     *Worker
+}
+
+func NewModule(db *pgxpool.Pool, cfg config.JobsConfig, paginationConfig config.PaginationConfig) *Module {
+	repo := NewRepository(db)
+	service := NewService(repo, cfg)
+	handler := NewHandler(service, paginationConfig)
+	worker := NewWorker(service, cfg.Workers, cfg.PollInterval)
+
+	return &Module{
+		Handler:    handler,
+		Service:    service,
+		Repository: repo,
+		Worker:     worker,
+	}
+}
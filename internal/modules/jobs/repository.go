@@ -0,0 +1,306 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+// errLeaseLost is returned when the claim finishing a job no longer holds it, e.g. the job was released
+// as stale and claimed again. The outcome of such a claim is discarded.
+var errLeaseLost = errors.New("job lease lost")
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Enqueue adds a job to the queue, it becomes part of the transaction of the context if there is one.
+func (r *Repository) Enqueue(ctx context.Context, kind string, payload []byte, maxAttempts int) (*Job, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := fmt.Sprintf(`
+	INSERT INTO jobs (kind, payload, max_attempts) VALUES ($1, $2, $3)
+	RETURNING %s`, jobColumns)
+
+	job, err := scanJob(q.QueryRow(ctx, queryString, kind, payload, maxAttempts))
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return job, nil
+}
+
+// Claim marks the next due job of the kinds as running and returns it, or nil when there is none.
+// Jobs locked by other workers are skipped. The claims counter of the job identifies the claim, unlike attempts
+// it's never reset. locked_at is the lease of the claim which must be extended while the job runs.
+func (r *Repository) Claim(ctx context.Context, kinds []string) (*Job, error) {
+	queryString := fmt.Sprintf(`
+	UPDATE jobs SET status = 'running', attempts = attempts + 1, claims = claims + 1, locked_at = NOW()
+	WHERE id = (
+		SELECT id FROM jobs
+		WHERE status = 'pending' and run_at <= NOW() and kind = ANY($1)
+		ORDER BY run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING %s`, jobColumns)
+
+	job, err := scanJob(r.db.QueryRow(ctx, queryString, kinds))
+	if dbx.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return job, nil
+}
+
+// Extend renews the lease of the job held by the claim.
+func (r *Repository) Extend(ctx context.Context, id, claim int) error {
+	tag, err := r.db.Exec(ctx, `
+	UPDATE jobs SET locked_at = NOW()
+	WHERE id = $1 and status = 'running' and claims = $2`, id, claim)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (r *Repository) Complete(ctx context.Context, id, claim int) error {
+	tag, err := r.db.Exec(ctx, `
+	UPDATE jobs SET status = 'succeeded', locked_at = NULL, finished_at = NOW()
+	WHERE id = $1 and status = 'running' and claims = $2`, id, claim)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// Fail records the error of the job and puts it back to the queue to run after the backoff,
+// unless it has run out of attempts. It returns the new status of the job.
+func (r *Repository) Fail(ctx context.Context, id, claim int, message string, backoff time.Duration) (string, error) {
+	queryString := `
+	UPDATE jobs
+	SET status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+		run_at = CASE WHEN attempts < max_attempts THEN NOW() + make_interval(secs => $3) ELSE run_at END,
+		finished_at = CASE WHEN attempts < max_attempts THEN NULL ELSE NOW() END,
+		locked_at = NULL,
+		last_error = $2
+	WHERE id = $1 and status = 'running' and claims = $4
+	RETURNING status`
+
+	var status string
+	err := r.db.QueryRow(ctx, queryString, id, message, backoff.Seconds(), claim).Scan(&status)
+	if dbx.IsNoRows(err) {
+		return "", errLeaseLost
+	}
+	if err != nil {
+		return "", apperrors.Internal(err)
+	}
+	return status, nil
+}
+
+// ReleaseStale puts back to the queue the jobs whose lease hasn't been extended for the timeout, their workers
+// are assumed to be gone. Jobs which have run out of attempts are failed instead. A released job can't be finished
+// by its former claim anymore, as Complete and Fail check that the claim still holds it.
+func (r *Repository) ReleaseStale(ctx context.Context, timeout time.Duration) (int64, error) {
+	queryString := `
+	UPDATE jobs
+	SET status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+		finished_at = CASE WHEN attempts < max_attempts THEN NULL ELSE NOW() END,
+		locked_at = NULL,
+		last_error = 'timed out'
+	WHERE status = 'running' and locked_at < NOW() - make_interval(secs => $1)`
+
+	tag, err := r.db.Exec(ctx, queryString, timeout.Seconds())
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Retry puts a failed job back to the queue with its attempts reset. Its claims go on counting, so that
+// a former claim which is still running can't finish it.
+func (r *Repository) Retry(ctx context.Context, id int) (*Job, error) {
+	queryString := fmt.Sprintf(`
+	UPDATE jobs SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL
+	WHERE id = $1 and status = 'failed'
+	RETURNING %s`, jobColumns)
+
+	job, err := scanJob(r.db.QueryRow(ctx, queryString, id))
+	if dbx.IsNoRows(err) {
+		current, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperrors.BadRequest(fmt.Errorf("job with id %d is %s, only failed jobs can be retried", id, current.Status))
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return job, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Job, error) {
+	queryString := fmt.Sprintf("SELECT %s FROM jobs WHERE id = $1", jobColumns)
+
+	job, err := scanJob(r.db.QueryRow(ctx, queryString, id))
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("job", "id", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return job, nil
+}
+
+// GetAllPaginated returns the jobs with the status and of the kind if they are given, the most recent first.
+func (r *Repository) GetAllPaginated(ctx context.Context, status, kind *string, params *pagination.Params) (*pagination.Page[Job], error) {
+	queryPage := dbx.StatementBuilder.
+		Select(jobColumns).
+		From("jobs").
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
+	queryTotal := dbx.StatementBuilder.
+		Select("count(*)").
+		From("jobs")
+
+	if status != nil {
+		queryPage = queryPage.Where("status = ?", *status)
+		queryTotal = queryTotal.Where("status = ?", *status)
+	}
+	if kind != nil {
+		queryPage = queryPage.Where("kind = ?", *kind)
+		queryTotal = queryTotal.Where("kind = ?", *kind)
+	}
+
+	keyset := dbx.Keyset{{Expr: "id", Desc: true}}
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &pgx.Batch{}
+
+	err = dbx.QueueBatchSelect(b, queryPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, queryTotal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	br := r.db.SendBatch(ctx, b)
+	defer br.Close()
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var items []*Job
+	var keys [][]string
+	for rows.Next() {
+		var job Job
+		key := keyset.NewKey()
+		err = rows.Scan(append(job.scanDest(), keyset.ScanDest(key)...)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		items = append(items, &job)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, items, keys, total), nil
+}
+
+// SaveSchedule creates or updates the schedule. The next run is kept unless the cron expression has changed.
+func (r *Repository) SaveSchedule(ctx context.Context, s *schedule, untilNext time.Duration) error {
+	queryString := `
+	INSERT INTO job_schedules (name, kind, cron, next_run_at) VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+	ON CONFLICT (name) DO UPDATE
+	SET kind = EXCLUDED.kind,
+		cron = EXCLUDED.cron,
+		next_run_at = CASE WHEN job_schedules.cron = EXCLUDED.cron THEN job_schedules.next_run_at ELSE EXCLUDED.next_run_at END`
+
+	_, err := r.db.Exec(ctx, queryString, s.name, s.kind, s.spec, untilNext.Seconds())
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// EnqueueDue enqueues a job of the schedule if its next run has come and moves the next run forward,
+// it reports whether a job was enqueued. Only one instance gets to enqueue it.
+func (r *Repository) EnqueueDue(ctx context.Context, s *schedule, maxAttempts int, untilNext time.Duration) (bool, error) {
+	var enqueued bool
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		var kind string
+		err := tx.QueryRow(ctx,
+			"SELECT kind FROM job_schedules WHERE name = $1 and next_run_at <= NOW() FOR UPDATE SKIP LOCKED",
+			s.name).Scan(&kind)
+		if dbx.IsNoRows(err) {
+			return nil
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = r.Enqueue(ctx, kind, []byte("{}"), maxAttempts); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE job_schedules SET next_run_at = NOW() + make_interval(secs => $2) WHERE name = $1",
+			s.name, untilNext.Seconds())
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		enqueued = true
+		return nil
+	})
+	return enqueued, err
+}
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var job Job
+	if err := row.Scan(job.scanDest()...); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
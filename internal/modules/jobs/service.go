@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/backoff"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/cron"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+// finishTimeout bounds recording the outcome of a job, which is done even if the job was cancelled.
+const finishTimeout = 5 * time.Second
+
+type Service struct {
+	repo      *Repository
+	cfg       config.JobsConfig
+	handlers  map[string]HandlerFunc
+	schedules []*schedule
+}
+
+func NewService(repo *Repository, cfg config.JobsConfig) *Service {
+	return &Service{
+		repo:     repo,
+		cfg:      cfg,
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+// Register sets the handler of the kind of jobs, all the kinds must be registered before the worker is started.
+func (s *Service) Register(kind string, fn HandlerFunc) {
+	s.handlers[kind] = fn
+}
+
+// Schedule enqueues a job of the kind by the cron expression, e.g. "0 * * * *" for every hour.
+// The name identifies the schedule across restarts and instances.
+func (s *Service) Schedule(name, kind, spec string) error {
+	if _, ok := s.handlers[kind]; !ok {
+		return fmt.Errorf("unknown job kind %q", kind)
+	}
+
+	c, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("parse schedule %s: %w", name, err)
+	}
+	if c.Next(time.Now()).IsZero() {
+		return fmt.Errorf("schedule %s never runs", name)
+	}
+
+	s.schedules = append(s.schedules, &schedule{name: name, kind: kind, spec: spec, cron: c})
+	return nil
+}
+
+// Enqueue adds a job of the kind with the payload marshaled to JSON, it becomes part of the transaction
+// of the context if there is one.
+func (s *Service) Enqueue(ctx context.Context, kind string, payload any) (*Job, error) {
+	if _, ok := s.handlers[kind]; !ok {
+		return nil, apperrors.BadRequest(fmt.Errorf("unknown job kind %q", kind))
+	}
+
+	if payload == nil {
+		payload = struct{}{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	job, err := s.repo.Enqueue(ctx, kind, data, s.cfg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("job enqueued",
+		"id", job.ID,
+		"kind", kind)
+
+	return job, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Job, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) GetAllPaginated(ctx context.Context, status, kind *string, params *pagination.Params) (*pagination.Page[Job], error) {
+	return s.repo.GetAllPaginated(ctx, status, kind, params)
+}
+
+func (s *Service) Retry(ctx context.Context, id int) (*Job, error) {
+	job, err := s.repo.Retry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("job retried",
+		"id", id,
+		"kind", job.Kind)
+
+	return job, nil
+}
+
+// syncSchedules saves the schedules so that every instance agrees on their next runs.
+func (s *Service) syncSchedules(ctx context.Context) error {
+	for _, sch := range s.schedules {
+		if err := s.repo.SaveSchedule(ctx, sch, time.Until(sch.cron.Next(time.Now()))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueDue enqueues the jobs of the schedules whose time has come and releases the jobs of the workers gone missing.
+func (s *Service) enqueueDue(ctx context.Context) error {
+	for _, sch := range s.schedules {
+		enqueued, err := s.repo.EnqueueDue(ctx, sch, s.cfg.MaxAttempts, time.Until(sch.cron.Next(time.Now())))
+		if err != nil {
+			return err
+		}
+		if enqueued {
+			log.FromContext(ctx).Info("scheduled job enqueued",
+				"schedule", sch.name,
+				"kind", sch.kind)
+		}
+	}
+
+	released, err := s.repo.ReleaseStale(ctx, s.cfg.LockTimeout)
+	if err != nil {
+		return err
+	}
+	if released > 0 {
+		log.FromContext(ctx).Warn("stale jobs released", "count", released)
+	}
+	return nil
+}
+
+// runNext claims the next due job and runs it, it reports whether there was a job to run.
+func (s *Service) runNext(ctx context.Context) (bool, error) {
+	kinds := make([]string, 0, len(s.handlers))
+	for kind := range s.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	job, err := s.repo.Claim(ctx, kinds)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	logger := log.FromContext(ctx).With("job", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+	jobCtx, cancelJob := context.WithCancel(withJob(log.WithLogger(ctx, logger), job))
+	stopHeartbeat := s.heartbeat(jobCtx, cancelJob, job)
+	runErr := s.run(jobCtx, job)
+	stopHeartbeat()
+	cancelJob()
+
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if runErr == nil {
+		err = s.repo.Complete(ctx, job.ID, job.claim)
+		if errors.Is(err, errLeaseLost) {
+			logger.Warn("job succeeded after its lease was lost, the outcome is discarded")
+			return true, nil
+		}
+		if err == nil {
+			logger.Info("job succeeded")
+		}
+		return true, err
+	}
+
+	status, err := s.repo.Fail(ctx, job.ID, job.claim, runErr.Error(), s.backoff(job.Attempts))
+	if errors.Is(err, errLeaseLost) {
+		logger.Warn("job failed after its lease was lost, the outcome is discarded", "err", runErr)
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	if status == StatusFailed {
+		logger.Error("job failed", "err", runErr)
+	} else {
+		logger.Warn("job will be retried", "err", runErr)
+	}
+	return true, nil
+}
+
+// heartbeat extends the lease of the running job every third of the lock timeout, so that a long job isn't
+// taken for an abandoned one. If the lease is lost anyway, the job is cancelled. The returned func stops it.
+func (s *Service) heartbeat(ctx context.Context, cancelJob context.CancelFunc, job *Job) (stop func()) {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.cfg.LockTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := s.repo.Extend(ctx, job.ID, job.claim)
+			if errors.Is(err, errLeaseLost) {
+				log.FromContext(ctx).Warn("job lease lost, cancelling the job")
+				cancelJob()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.FromContext(ctx).Warn("extend job lease", "err", err)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *Service) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return s.handlers[job.Kind](ctx, job.Payload)
+}
+
+func (s *Service) backoff(attempts int) time.Duration {
+	return backoff.Exponential(s.cfg.BaseBackoff, s.cfg.MaxBackoff, attempts)
+}
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
+
+// Worker polls the queue and runs the jobs in its goroutines, it also enqueues the scheduled jobs.
+type Worker struct {
+	service  *Service
+	workers  int
+	interval time.Duration
+
+	stop    context.CancelFunc
+	abort   context.CancelFunc
+	wg      sync.WaitGroup
+	stopped sync.Once
+}
+
+func NewWorker(service *Service, workers int, pollInterval time.Duration) *Worker {
+	return &Worker{
+		service:  service,
+		workers:  workers,
+		interval: pollInterval,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	if err := w.service.syncSchedules(ctx); err != nil {
+		return err
+	}
+
+	// polling stops first on shutdown, the running jobs are cancelled only if they don't finish in time
+	pollCtx, stop := context.WithCancel(context.Background())
+	jobCtx, abort := context.WithCancel(context.Background())
+	w.stop, w.abort = stop, abort
+
+	w.wg.Add(w.workers + 1)
+	for i := 0; i < w.workers; i++ {
+		go func() {
+			defer w.wg.Done()
+			w.process(pollCtx, jobCtx)
+		}()
+	}
+	go func() {
+		defer w.wg.Done()
+		w.schedule(pollCtx)
+	}()
+
+	return nil
+}
+
+// Shutdown stops taking new jobs and waits for the running ones to finish. If the context is done first,
+// the running jobs are cancelled, they will be retried later.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	if w.stop == nil {
+		return nil
+	}
+
+	w.stopped.Do(w.stop)
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.abort()
+		return nil
+	case <-ctx.Done():
+		w.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) process(pollCtx, jobCtx context.Context) {
+	for {
+		ran, err := w.service.runNext(jobCtx)
+		if err != nil {
+			log.FromContext(jobCtx).Error("run job", "err", err)
+		}
+
+		if ran && err == nil {
+			select {
+			case <-pollCtx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-pollCtx.Done():
+			return
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+func (w *Worker) schedule(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.enqueueDue(ctx); err != nil && ctx.Err() == nil {
+			log.FromContext(ctx).Error("enqueue scheduled jobs", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
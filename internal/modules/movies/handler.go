@@ -1,17 +1,28 @@
 package movies
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"golang.org/x/sync/singleflight"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/config"
 	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/locale"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
 	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
+)
+
+const (
+	defaultSuggestionsLimit = 10
+	defaultDuplicatesLimit  = 20
 )
 
 type Handler struct {
@@ -35,11 +46,22 @@ func (h *Handler) CreateMovie(c echo.Context) error {
 
 	movie := &MovieDetails{
 		Movie: Movie{
+			Kind:        req.Kind,
+			ParentID:    req.ParentID,
+			Number:      req.Number,
 			Title:       req.Title,
 			ReleaseDate: req.ReleaseDate,
+			Language:    req.Language,
 		},
 		Description: req.Description,
 	}
+	if movie.Kind == "" {
+		movie.Kind = KindMovie
+	}
+	if movie.Language == "" {
+		movie.Language = DefaultLanguage
+	}
+	setMetadata(movie, &req.MovieMetadata)
 
 	for _, item := range req.Genres {
 		movie.Genres = append(movie.Genres, &genres.Genre{ID: item})
@@ -50,8 +72,13 @@ func (h *Handler) CreateMovie(c echo.Context) error {
 			Star: stars.Star{
 				ID: item.StarID,
 			},
-			Role:    item.Role,
-			Details: item.Details,
+			Credit: stars.Credit{
+				Role:       item.Role,
+				Characters: item.Characters,
+				Job:        item.Job,
+				Uncredited: item.Uncredited,
+				Voice:      item.Voice,
+			},
 		})
 	}
 
@@ -69,51 +96,171 @@ func (h *Handler) GetMovieByID(c echo.Context) error {
 		return err
 	}
 
-	movie, err := h.Service.GetMovieByID(c.Request().Context(), req.ID)
+	includes := sparse.ParseIncludes(req.Include, IncludeGenres, IncludeCast, IncludeImage)
+	movie, err := h.Service.GetMovieByID(c.Request().Context(), req.ID, locale.FromRequest(c.Request()), includes)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		targetID, mergedErr := h.Service.GetMergedInto(c.Request().Context(), req.ID)
+		if mergedErr != nil {
+			return mergedErr
+		}
+		if targetID != nil {
+			return echox.RedirectMoved(c, *targetID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Add(echo.HeaderVary, locale.AcceptLanguageHeader)
+	if movie.Locale != nil {
+		c.Response().Header().Set(locale.ContentLanguageHeader, *movie.Locale)
+	} else {
+		c.Response().Header().Set(locale.ContentLanguageHeader, movie.Language)
+	}
+
+	res, err := sparse.Project(movie, sparse.Fields(req.Fields, includes))
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, movie)
+	// the rating, the translations and the relations change without the version of the movie
+	return echox.JSONOrNotModified(c, movie.Version, res)
 }
 
 func (h *Handler) GetAll(c echo.Context) error {
-	res, err, _ := h.reqGroup.Do(c.Request().RequestURI, func() (any, error) {
+	locales := locale.FromRequest(c.Request())
+	key := c.Request().RequestURI + "|" + strings.Join(locales, ",")
+
+	res, err, _ := h.reqGroup.Do(key, func() (any, error) {
 		req, err := echox.BindAndValidate[contracts.GetMoviesRequest](c)
 		if err != nil {
 			return nil, err
 		}
 
-		pagination.SetDefaults(&req.PaginatedRequest, h.PaginationConfig)
-		offset, limit := pagination.OffsetLimit(&req.PaginatedRequest)
+		params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+		if err != nil {
+			return nil, err
+		}
 
-		movies, total, err := h.Service.GetAllPaginated(c.Request().Context(), req.StarID, req.SearchTerm, req.SortByRating, offset, limit)
+		includes := sparse.ParseIncludes(req.Include)
+		filter := &Filter{
+			StarID:          req.StarID,
+			Kind:            req.Kind,
+			ParentID:        req.ParentID,
+			AwardCategoryID: req.AwardCategoryID,
+			AwardWinner:     req.AwardWinner,
+			SearchTerm:      req.SearchTerm,
+			Language:        req.Language,
+		}
+		page, err := h.Service.GetAllPaginated(c.Request().Context(), filter, req.SortOrAlias(), locales, includes, params)
 		if err != nil {
 			return nil, err
 		}
-		return pagination.Response(&req.PaginatedRequest, total, movies), nil
+		return sparse.ProjectPage(pagination.Response(&req.PaginatedRequest, params, page), sparse.Fields(req.Fields, includes))
 	})
 	if err != nil {
 		return err
 	}
 
+	c.Response().Header().Add(echo.HeaderVary, locale.AcceptLanguageHeader)
 	return c.JSON(http.StatusOK, res)
 }
 
+func (h *Handler) GetSuggestions(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetMovieSuggestionsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestionsLimit
+	}
+
+	suggestions, err := h.Service.GetSuggestions(c.Request().Context(), req.Query, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, suggestions)
+}
+
 func (h *Handler) UpdateMovie(c echo.Context) error {
 	req, err := echox.BindAndValidate[contracts.UpdateMovieRequest](c)
 	if err != nil {
 		return err
 	}
 
+	return h.updateMovie(c, req)
+}
+
+// PatchMovie applies a merge patch to the movie, the genres and the cast change only when the patch has them.
+// The patch is conditional on the version it was applied to, unless it sets the version or If-Match is given.
+func (h *Handler) PatchMovie(c echo.Context) error {
+	req, err := echox.BindMergePatch(c, func(req *contracts.UpdateMovieRequest) error {
+		includes := sparse.Includes{IncludeGenres: true, IncludeCast: true}
+		movie, err := h.Service.GetMovieByID(c.Request().Context(), req.ID, nil, includes)
+		if err != nil {
+			return err
+		}
+
+		req.Title = movie.Title
+		req.ReleaseDate = movie.ReleaseDate
+		req.Description = movie.Description
+		req.Language = movie.Language
+		req.Version = movie.Version
+		req.MovieMetadata = contracts.MovieMetadata{
+			Tagline:         movie.Tagline,
+			Runtime:         movie.Runtime,
+			Countries:       movie.Countries,
+			SpokenLanguages: movie.SpokenLanguages,
+			Certifications: slices.Map(movie.Certifications, func(c *Certification) *contracts.Certification {
+				return &contracts.Certification{
+					Country:       c.Country,
+					Certification: c.Certification,
+				}
+			}),
+			ExternalIDs: movie.ExternalIDs,
+		}
+		req.Genres = slices.Map(movie.Genres, func(g *genres.Genre) int { return g.ID })
+		req.Cast = slices.Map(movie.Cast, func(mc *stars.MovieCredit) *contracts.MovieCreditInfo {
+			return &contracts.MovieCreditInfo{
+				StarID:     mc.Star.ID,
+				Role:       mc.Role,
+				Characters: mc.Characters,
+				Job:        mc.Job,
+				Uncredited: mc.Uncredited,
+				Voice:      mc.Voice,
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.updateMovie(c, req)
+}
+
+func (h *Handler) updateMovie(c echo.Context, req *contracts.UpdateMovieRequest) error {
+	// If-Match takes precedence over the version in the body
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+	if version != nil {
+		req.Version = *version
+	}
+
 	movie := &MovieDetails{
 		Movie: Movie{
 			Title:       req.Title,
 			ReleaseDate: req.ReleaseDate,
+			Language:    req.Language,
 		},
 		Description: req.Description,
 		Version:     req.Version,
 	}
+	setMetadata(movie, &req.MovieMetadata)
 	id := req.ID
 
 	for _, item := range req.Genres {
@@ -125,8 +272,13 @@ func (h *Handler) UpdateMovie(c echo.Context) error {
 			Star: stars.Star{
 				ID: item.StarID,
 			},
-			Role:    item.Role,
-			Details: item.Details,
+			Credit: stars.Credit{
+				Role:       item.Role,
+				Characters: item.Characters,
+				Job:        item.Job,
+				Uncredited: item.Uncredited,
+				Voice:      item.Voice,
+			},
 		})
 	}
 
@@ -135,19 +287,142 @@ func (h *Handler) UpdateMovie(c echo.Context) error {
 		return err
 	}
 
+	if err = h.setETag(c, id, movie.Version); err != nil {
+		return err
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+// setETag sets the ETag of the movie as GET returns it by default. It's left out when the movie has changed
+// again since the update, as the tag of the newer version would let the client overwrite that change.
+func (h *Handler) setETag(c echo.Context, id, version int) error {
+	movie, err := h.Service.GetMovieByID(c.Request().Context(), id, nil, sparse.ParseIncludes(nil, IncludeGenres, IncludeCast, IncludeImage))
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if movie.Version != version {
+		return nil
+	}
+	return echox.SetRepresentationETag(c, version, movie)
+}
+
 func (h *Handler) DeleteMovie(c echo.Context) error {
 	req, err := echox.BindAndValidate[contracts.DeleteMovieRequest](c)
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	err = h.Service.DeleteMovie(c.Request().Context(), req.ID)
+	err = h.Service.DeleteMovie(c.Request().Context(), req.ID, version)
 	if err != nil {
 		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *Handler) GetTranslations(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetMovieTranslationsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	translations, err := h.Service.GetTranslations(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, translations)
+}
+
+func (h *Handler) PutTranslation(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.PutMovieTranslationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if req.Title == nil && req.Description == nil {
+		return apperrors.BadRequest(errors.New("translation must contain a title or a description"))
+	}
+
+	translation := &MovieTranslation{
+		MovieID:     req.ID,
+		Locale:      locale.Normalize(req.Locale),
+		Title:       req.Title,
+		Description: req.Description,
+	}
+
+	err = h.Service.PutTranslation(c.Request().Context(), translation)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, translation)
+}
+
+func (h *Handler) DeleteTranslation(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteMovieTranslationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.DeleteTranslation(c.Request().Context(), req.ID, locale.Normalize(req.Locale))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// setMetadata copies the metadata from a request, country codes are upper and language codes are lower case.
+func setMetadata(movie *MovieDetails, metadata *contracts.MovieMetadata) {
+	movie.Tagline = metadata.Tagline
+	movie.Runtime = metadata.Runtime
+	movie.Countries = slices.Map(metadata.Countries, strings.ToUpper)
+	movie.SpokenLanguages = slices.Map(metadata.SpokenLanguages, strings.ToLower)
+	movie.Certifications = slices.Map(metadata.Certifications, func(c *contracts.Certification) *Certification {
+		return &Certification{
+			Country:       strings.ToUpper(c.Country),
+			Certification: c.Certification,
+		}
+	})
+	movie.ExternalIDs = metadata.ExternalIDs
+}
+
+func (h *Handler) MergeMovie(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.MergeMovieRequest](c)
+	if err != nil {
+		return err
+	}
+
+	movie, err := h.Service.Merge(c.Request().Context(), req.ID, req.TargetID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, movie)
+}
+
+func (h *Handler) GetDuplicates(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetDuplicatesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultDuplicatesLimit
+	}
+
+	duplicates, err := h.Service.GetDuplicates(c.Request().Context(), req.Limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, duplicates)
+}
@@ -3,23 +3,135 @@ package movies
 import (
 	"time"
 
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/collections"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
 )
 
+// DefaultLanguage is the language of movies created without an explicit one.
+// It is also used to parse search terms when no language hint is given.
+const DefaultLanguage = "en"
+
+// Relations of a movie which can be requested with the include parameter.
+const (
+	IncludeGenres  = "genres"
+	IncludeCast    = "cast"
+	IncludeReviews = "reviews"
+	IncludeImage   = "image"
+	IncludeImages  = "images"
+)
+
+// IncludedReviewsLimit is the number of the first reviews included in every movie, the rest are paginated
+// with the reviews API.
+const IncludedReviewsLimit = 10
+
+// Kinds of titles. Seasons belong to series and episodes belong to seasons.
+const (
+	KindMovie   = "movie"
+	KindSeries  = "series"
+	KindSeason  = "season"
+	KindEpisode = "episode"
+)
+
+// parentKinds maps the kinds of nested titles to the kinds of their parents.
+var parentKinds = map[string]string{
+	KindSeason:  KindSeries,
+	KindEpisode: KindSeason,
+}
+
 type Movie struct {
-	ID          int        `json:"id"`
-	Title       string     `json:"title"`
-	ReleaseDate time.Time  `json:"release_date"`
-	AvgRating   *float64   `json:"avg_rating,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ID          int                  `json:"id"`
+	Kind        string               `json:"kind"`
+	ParentID    *int                 `json:"parent_id,omitempty"`
+	Number      *int                 `json:"number,omitempty"`
+	Title       string               `json:"title"`
+	ReleaseDate time.Time            `json:"release_date"`
+	Language    string               `json:"language"`
+	Locale      *string              `json:"locale,omitempty"`
+	Original    *string              `json:"original_title,omitempty"`
+	AvgRating   *float64             `json:"avg_rating,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	DeletedAt   *time.Time           `json:"deleted_at,omitempty"`
+	Genres      []*genres.Genre      `json:"genres,omitempty"`
+	Cast        []*stars.MovieCredit `json:"cast,omitempty"`
+	Reviews     []*MovieReview       `json:"reviews,omitempty"`
+	Image       *images.Image        `json:"image,omitempty"`
+	Images      []*images.Image      `json:"images,omitempty"`
 }
 
 type MovieDetails struct {
 	Movie
-	Description string               `json:"description"`
-	Version     int                  `json:"version"`
-	Genres      []*genres.Genre      `json:"genres"`
-	Cast        []*stars.MovieCredit `json:"cast"`
+	Description     string                         `json:"description"`
+	Tagline         *string                        `json:"tagline,omitempty"`
+	Runtime         *int                           `json:"runtime,omitempty"`
+	Countries       []string                       `json:"countries,omitempty"`
+	SpokenLanguages []string                       `json:"spoken_languages,omitempty"`
+	Certifications  []*Certification               `json:"certifications,omitempty"`
+	ExternalIDs     map[string]string              `json:"external_ids,omitempty"`
+	Collections     []*collections.MovieCollection `json:"collections,omitempty"`
+	Awards          *awards.Summary                `json:"awards,omitempty"`
+	Version         int                            `json:"version"`
+}
+
+// Certification is the age rating of a movie in a country, e.g. PG-13 in the US.
+type Certification struct {
+	Country       string `json:"country"`
+	Certification string `json:"certification"`
+}
+
+// Filter narrows down the list of titles, nil fields are ignored.
+type Filter struct {
+	StarID   *int
+	Kind     *string
+	ParentID *int
+	// AwardCategoryID and AwardWinner select nominated movies, e.g. Best Picture winners
+	AwardCategoryID *int
+	AwardWinner     *bool
+	SearchTerm      *string
+	Language        *string
+}
+
+// MovieReview is a review of a movie. Reviews depend on movies, so they can't be used here.
+type MovieReview struct {
+	ID        int       `json:"id"`
+	MovieID   int       `json:"movie_id"`
+	UserID    int       `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MovieDuplicate is a pair of titles which are likely the same one. The duplicate is the newer one,
+// so it is the one to merge into the other.
+type MovieDuplicate struct {
+	Movie      *Movie  `json:"movie"`
+	Duplicate  *Movie  `json:"duplicate"`
+	Similarity float64 `json:"similarity"`
+}
+
+type MovieSuggestion struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+	Similarity  float64   `json:"similarity"`
+}
+
+type MovieTranslation struct {
+	MovieID     int     `json:"-"`
+	Locale      string  `json:"locale"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// localize replaces the title with the translated one keeping the original title aside.
+func (m *Movie) localize(translation *MovieTranslation) {
+	m.Locale = &translation.Locale
+	if translation.Title != nil && *translation.Title != m.Title {
+		original := m.Title
+		m.Original = &original
+		m.Title = *translation.Title
+	}
 }
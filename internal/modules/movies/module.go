@@ -3,7 +3,10 @@ package movies
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/collections"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
 )
 
@@ -13,9 +16,9 @@ type Module struct {
 	Repository *Repository
 }
 
-func NewModule(db *pgxpool.Pool, genresModule *genres.Module, starsModule *stars.Module, paginationConfig config.PaginationConfig) *Module {
+func NewModule(db *pgxpool.Pool, genresModule *genres.Module, starsModule *stars.Module, imagesModule *images.Module, collectionsModule *collections.Module, awardsModule *awards.Module, paginationConfig config.PaginationConfig) *Module {
 	repo := NewRepository(db, genresModule.Repository, starsModule.Repository)
-	service := NewService(repo, genresModule.Service, starsModule.Service)
+	service := NewService(repo, genresModule.Service, starsModule.Service, imagesModule.Service, collectionsModule.Service, awardsModule.Service)
 	handler := NewHandler(service, paginationConfig)
 
 	return &Module{
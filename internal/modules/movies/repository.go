@@ -2,16 +2,28 @@ package movies
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/live"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
 	"github.com/mkuptsov/movie-reviews/internal/slices"
 )
 
+var sortFields = dbx.SortFields{
+	// Unrated movies keep sorting as NULLs would, i.e. after any rating
+	"rating":       {{Expr: "coalesce(avg_rating, 'Infinity')", Type: dbx.KeyReal}},
+	"release_date": {{Expr: "release_date", Type: dbx.KeyDate}},
+	"title":        {{Expr: "title", Type: dbx.KeyText}},
+	"created_at":   {{Expr: "created_at", Type: dbx.KeyTimestamp}},
+}
+
 type Repository struct {
 	db               *pgxpool.Pool
 	genresRepository *genres.Repository
@@ -30,25 +42,49 @@ func (r *Repository) CreateMovie(ctx context.Context, movie *MovieDetails) error
 	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
 		queryString := `
 	INSERT INTO movies 
-	(title, release_date, description) 
+	(title, release_date, description, language, tagline, runtime, countries, spoken_languages, kind, parent_id, number) 
 	VALUES 
-	($1, $2, $3)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	RETURNING
 	id, created_at;
 	`
+		err := r.checkParent(ctx, &movie.Movie)
+		if err != nil {
+			return err
+		}
+
 		row := tx.QueryRow(ctx, queryString,
 			&movie.Title,
 			&movie.ReleaseDate,
 			&movie.Description,
+			&movie.Language,
+			movie.Tagline,
+			movie.Runtime,
+			nonNil(movie.Countries),
+			nonNil(movie.SpokenLanguages),
+			movie.Kind,
+			movie.ParentID,
+			movie.Number,
 		)
-		err := row.Scan(
+		err = row.Scan(
 			&movie.ID,
 			&movie.CreatedAt,
 		)
+		if dbx.IsForeignKeyViolation(err, "language") {
+			return apperrors.BadRequest(fmt.Errorf("unsupported language %q", movie.Language))
+		}
+		if dbx.IsUniqueViolation(err, "parent_id_number") {
+			return apperrors.AlreadyExists(movie.Kind, "number", *movie.Number)
+		}
 		if err != nil {
 			return apperrors.Internal(err)
 		}
 
+		err = r.updateMetadata(ctx, movie.ID, movie)
+		if err != nil {
+			return err
+		}
+
 		nextGenres := slices.MapIndex(movie.Genres, func(i int, genre *genres.Genre) *genres.MovieGenreRelation {
 			return &genres.MovieGenreRelation{
 				MovieID: movie.ID,
@@ -66,13 +102,22 @@ func (r *Repository) CreateMovie(ctx context.Context, movie *MovieDetails) error
 			return &stars.MovieStarRelation{
 				MovieID: movie.ID,
 				StarID:  cast.Star.ID,
-				Role:    cast.Role,
-				Details: cast.Details,
+				Credit:  cast.Credit,
 				OrderNo: i,
 			}
 		})
 
-		return r.updateCast(ctx, []*stars.MovieStarRelation{}, nextCast)
+		err = r.updateCast(ctx, []*stars.MovieStarRelation{}, nextCast)
+		if err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.MovieCreated, movie.ID, &events.Movie{
+			ID:       movie.ID,
+			Title:    movie.Title,
+			Kind:     movie.Kind,
+			ParentID: movie.ParentID,
+		})
 	})
 	if err != nil {
 		return apperrors.EnsureInternal(err)
@@ -83,7 +128,12 @@ func (r *Repository) CreateMovie(ctx context.Context, movie *MovieDetails) error
 func (r *Repository) GetMovieByID(ctx context.Context, id int) (*MovieDetails, error) {
 	q := dbx.FromContext(ctx, r.db)
 	queryString := `
-	SELECT id, title, description, release_date, avg_rating, created_at, deleted_at, version
+	SELECT id, kind, parent_id, number, title, description, release_date, language, avg_rating, created_at, deleted_at, version,
+		tagline, runtime, countries, spoken_languages,
+		(SELECT coalesce(jsonb_agg(jsonb_build_object('country', country, 'certification', certification) ORDER BY country), '[]')
+			FROM movie_certifications WHERE movie_id = movies.id),
+		(SELECT coalesce(jsonb_object_agg(source, external_id), '{}')
+			FROM movie_external_ids WHERE movie_id = movies.id)
 	FROM movies
 	WHERE id = $1 and deleted_at IS NULL;`
 
@@ -92,13 +142,23 @@ func (r *Repository) GetMovieByID(ctx context.Context, id int) (*MovieDetails, e
 	row := q.QueryRow(ctx, queryString, id)
 	err := row.Scan(
 		&movie.ID,
+		&movie.Kind,
+		&movie.ParentID,
+		&movie.Number,
 		&movie.Title,
 		&movie.Description,
 		&movie.ReleaseDate,
+		&movie.Language,
 		&movie.AvgRating,
 		&movie.CreatedAt,
 		&movie.DeletedAt,
 		&movie.Version,
+		&movie.Tagline,
+		&movie.Runtime,
+		&movie.Countries,
+		&movie.SpokenLanguages,
+		&movie.Certifications,
+		&movie.ExternalIDs,
 	)
 
 	if dbx.IsNoRows(err) {
@@ -111,52 +171,100 @@ func (r *Repository) GetMovieByID(ctx context.Context, id int) (*MovieDetails, e
 	return &movie, nil
 }
 
-func (r *Repository) GetAllPaginated(ctx context.Context, starID *int, searchTerm *string, sortByRating *string, offset, limit int) ([]*Movie, int, error) {
+func (r *Repository) GetAllPaginated(ctx context.Context, filter *Filter, sort *string, params *pagination.Params) (*pagination.Page[Movie], error) {
 	queryPage := dbx.StatementBuilder.
-		Select("id, title, release_date, avg_rating, created_at, deleted_at").
+		Select("id, kind, parent_id, number, title, release_date, language, avg_rating, created_at, deleted_at").
 		From("movies").
 		Where("deleted_at IS NULL").
-		Limit(uint64(limit)).
-		Offset(uint64(offset))
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
 
 	queryTotal := dbx.StatementBuilder.
 		Select("count(*)").
 		From("movies").
 		Where("deleted_at IS NULL")
 
-	if starID != nil {
+	if filter.StarID != nil {
 		queryPage = queryPage.
-			Join("movie_stars on movies.id = movie_stars.movie_id").
-			Where("star_id = ?", starID)
+			Where("id IN (SELECT movie_id FROM movie_stars WHERE star_id = ?)", filter.StarID)
 
 		queryTotal = queryTotal.
-			Join("movie_stars on movies.id = movie_stars.movie_id").
-			Where("star_id = ?", starID)
+			Where("id IN (SELECT movie_id FROM movie_stars WHERE star_id = ?)", filter.StarID)
+	}
+
+	if filter.Kind != nil {
+		queryPage = queryPage.Where("kind = ?", filter.Kind)
+		queryTotal = queryTotal.Where("kind = ?", filter.Kind)
+	}
+
+	if filter.ParentID != nil {
+		queryPage = queryPage.Where("parent_id = ?", filter.ParentID)
+		queryTotal = queryTotal.Where("parent_id = ?", filter.ParentID)
+	}
+
+	if filter.AwardCategoryID != nil || filter.AwardWinner != nil {
+		nominated := `id IN (
+			SELECT movie_id FROM award_nominations
+			WHERE (?::integer IS NULL or category_id = ?) and (?::boolean IS NULL or winner = ?))`
+		args := []any{filter.AwardCategoryID, filter.AwardCategoryID, filter.AwardWinner, filter.AwardWinner}
+
+		queryPage = queryPage.Where(nominated, args...)
+		queryTotal = queryTotal.Where(nominated, args...)
 	}
 
+	var relevance *dbx.OrderColumn
+
+	searchTerm := filter.SearchTerm
 	if searchTerm != nil {
+		lang := DefaultLanguage
+		if filter.Language != nil {
+			lang = *filter.Language
+		}
+
+		// Unknown languages fall back to the 'simple' configuration, i.e. no stemming at all
+		const tsQuery = "to_tsquery(coalesce((SELECT search_config FROM languages WHERE code = ?), 'simple'::regconfig), ?)"
+
 		queryPage = queryPage.
-			Where("search_vector @@ to_tsquery('english', ?)", *searchTerm).
-			OrderByClause("ts_rank_cd(search_vector, to_tsquery('english', ?)) DESC", *searchTerm)
+			Where("search_vector @@ "+tsQuery, lang, *searchTerm)
+		relevance = &dbx.OrderColumn{
+			Expr: "ts_rank_cd(search_vector, " + tsQuery + ")",
+			Args: []any{lang, *searchTerm},
+			Type: dbx.KeyReal,
+			Desc: true,
+		}
 
 		queryTotal = queryTotal.
-			Where("search_vector @@ to_tsquery('english', ?)", *searchTerm)
+			Where("search_vector @@ "+tsQuery, lang, *searchTerm)
 	}
 
-	if sortByRating != nil {
-		queryPage = queryPage.
-			OrderByClause("avg_rating " + *sortByRating)
+	var keyset dbx.Keyset
+	switch {
+	case sort != nil:
+		keyset = dbx.SortKeyset(*sort, sortFields, dbx.OrderColumn{Expr: "id"})
+	case relevance != nil:
+		keyset = dbx.Keyset{*relevance, {Expr: "id"}}
+	case filter.ParentID != nil:
+		keyset = dbx.Keyset{{Expr: "number"}, {Expr: "id"}}
+	default:
+		keyset = dbx.Keyset{{Expr: "id"}}
+	}
+
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
 	}
 
 	b := &pgx.Batch{}
 
-	err := dbx.QueueBatchSelect(b, queryPage)
+	err = dbx.QueueBatchSelect(b, queryPage)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	err = dbx.QueueBatchSelect(b, queryTotal)
-	if err != nil {
-		return nil, 0, err
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, queryTotal)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	br := r.db.SendBatch(ctx, b)
@@ -164,34 +272,121 @@ func (r *Repository) GetAllPaginated(ctx context.Context, starID *int, searchTer
 
 	rows, err := br.Query()
 	if err != nil {
-		return nil, 0, apperrors.Internal(err)
+		return nil, apperrors.Internal(err)
 	}
 
 	var movies []*Movie
+	var keys [][]string
 	for rows.Next() {
 		var movie Movie
-		err = rows.Scan(
+		key := keyset.NewKey()
+		err = rows.Scan(append([]any{
 			&movie.ID,
+			&movie.Kind,
+			&movie.ParentID,
+			&movie.Number,
 			&movie.Title,
 			&movie.ReleaseDate,
+			&movie.Language,
 			&movie.AvgRating,
 			&movie.CreatedAt,
 			&movie.DeletedAt,
-		)
+		}, keyset.ScanDest(key)...)...)
 		if err != nil {
-			return nil, 0, apperrors.Internal(err)
+			return nil, apperrors.Internal(err)
 		}
 
 		movies = append(movies, &movie)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
 	}
 
-	var total int
-	err = br.QueryRow().Scan(&total)
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, movies, keys, total), nil
+}
+
+func (r *Repository) GetMoviesByIDs(ctx context.Context, ids []int) (map[int]*Movie, error) {
+	queryString := `
+	SELECT id, kind, parent_id, number, title, release_date, language, avg_rating, created_at, deleted_at
+	FROM movies
+	WHERE id = ANY($1) and deleted_at IS NULL`
+
+	rows, err := r.db.Query(ctx, queryString, ids)
 	if err != nil {
-		return nil, 0, apperrors.Internal(err)
+		return nil, apperrors.Internal(err)
 	}
+	defer rows.Close()
 
-	return movies, total, nil
+	movies := make(map[int]*Movie, len(ids))
+	for rows.Next() {
+		var movie Movie
+		err = rows.Scan(
+			&movie.ID,
+			&movie.Kind,
+			&movie.ParentID,
+			&movie.Number,
+			&movie.Title,
+			&movie.ReleaseDate,
+			&movie.Language,
+			&movie.AvgRating,
+			&movie.CreatedAt,
+			&movie.DeletedAt,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		movies[movie.ID] = &movie
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return movies, nil
+}
+
+func (r *Repository) GetSuggestions(ctx context.Context, query string, limit int) ([]*MovieSuggestion, error) {
+	queryString := `
+	SELECT id, title, release_date, greatest(similarity(title, $1), word_similarity($1, title)) AS score
+	FROM movies
+	WHERE deleted_at IS NULL and (title % $1 or $1 <% title)
+	ORDER BY score DESC, id
+	LIMIT $2`
+
+	rows, err := r.db.Query(ctx, queryString, query, limit)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	suggestions := []*MovieSuggestion{}
+	for rows.Next() {
+		var suggestion MovieSuggestion
+		err = rows.Scan(
+			&suggestion.ID,
+			&suggestion.Title,
+			&suggestion.ReleaseDate,
+			&suggestion.Similarity,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return suggestions, nil
 }
 
 func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetails) error {
@@ -202,6 +397,11 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 		title = $2,
 		release_date = $3,
 		description = $4,
+		language = coalesce(nullif($6, ''), language),
+		tagline = $7,
+		runtime = $8,
+		countries = $9,
+		spoken_languages = $10,
 		version = version + 1
 	WHERE id = $1 and deleted_at IS NULL and version = $5`
 
@@ -211,7 +411,15 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 			movie.ReleaseDate,
 			movie.Description,
 			movie.Version,
+			movie.Language,
+			movie.Tagline,
+			movie.Runtime,
+			nonNil(movie.Countries),
+			nonNil(movie.SpokenLanguages),
 		)
+		if dbx.IsForeignKeyViolation(err, "language") {
+			return apperrors.BadRequest(fmt.Errorf("unsupported language %q", movie.Language))
+		}
 		if err != nil {
 			return apperrors.Internal(err)
 		}
@@ -223,6 +431,12 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 			}
 			return apperrors.VersionMismatch("movie", "id", id, movie.Version)
 		}
+		movie.Version++
+
+		err = r.updateMetadata(ctx, id, movie)
+		if err != nil {
+			return err
+		}
 
 		nextGenres := slices.MapIndex(movie.Genres, func(i int, genre *genres.Genre) *genres.MovieGenreRelation {
 			return &genres.MovieGenreRelation{
@@ -246,8 +460,7 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 			return &stars.MovieStarRelation{
 				MovieID: id,
 				StarID:  mc.Star.ID,
-				Role:    mc.Role,
-				Details: mc.Details,
+				Credit:  mc.Credit,
 				OrderNo: i,
 			}
 		})
@@ -257,7 +470,12 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 			return err
 		}
 
-		return r.updateCast(ctx, currentCast, nextCast)
+		err = r.updateCast(ctx, currentCast, nextCast)
+		if err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.MovieUpdated, id, &events.Movie{ID: id, Title: movie.Title})
 	})
 	if err != nil {
 		return apperrors.EnsureInternal(err)
@@ -266,30 +484,175 @@ func (r *Repository) UpdateMovie(ctx context.Context, id int, movie *MovieDetail
 	return nil
 }
 
-func (r *Repository) DeleteMovie(ctx context.Context, id int) error {
+func (r *Repository) DeleteMovie(ctx context.Context, id int, version *int) error {
 	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
-		queryString := "UPDATE movies SET deleted_at = NOW() WHERE id = $1 and deleted_at IS NULL;"
-		cmdTag, err := tx.Exec(ctx, queryString, id)
+		err := r.Lock(ctx, tx, id)
 		if err != nil {
-			return apperrors.Internal(err)
+			return err
+		}
+		movie, err := r.GetMovieByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != nil && movie.Version != *version {
+			return apperrors.VersionMismatch("movie", "id", id, *version)
 		}
 
-		if cmdTag.RowsAffected() == 0 {
-			return apperrors.NotFound("movie", "id", id)
+		// Seasons and episodes go away together with their series. Genres and cast are kept for a restore,
+		// the same timestamp tells which nested titles were deleted along with the title.
+		queryString := "UPDATE movies SET deleted_at = NOW() WHERE id IN (SELECT title_subtree($1)) and deleted_at IS NULL"
+		_, err = tx.Exec(ctx, queryString, id)
+		if err != nil {
+			return apperrors.Internal(err)
 		}
-		currentGenres, err := r.genresRepository.GetRelationsByMovieID(ctx, id)
+
+		err = events.Record(ctx, tx, events.MovieDeleted, id, &events.Movie{ID: id, Kind: movie.Kind, ParentID: movie.ParentID})
 		if err != nil {
 			return err
 		}
-		err = r.updateGenres(ctx, currentGenres, []*genres.MovieGenreRelation{})
+
+		if movie.ParentID != nil {
+			return r.RecalculateRating(ctx, *movie.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+
+	return nil
+}
+
+// Merge moves everything attached to the title to the target one: credits, genres, reviews, images, collections,
+// nominations, translations, metadata and nested titles. It fills in the details the target lacks and deletes
+// the title leaving a redirect to the target. When a user reviewed both titles, the live and then the latest
+// review is kept.
+func (r *Repository) Merge(ctx context.Context, id, targetID int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, "SELECT id, kind, parent_id FROM movies WHERE id = ANY($1) and deleted_at IS NULL FOR UPDATE", []int{id, targetID})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		locked, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Movie, error) {
+			var movie Movie
+			err := row.Scan(&movie.ID, &movie.Kind, &movie.ParentID)
+			return &movie, err
+		})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		titles := slices.ToMap(locked, func(m *Movie) int { return m.ID }, slices.NoChangeFunc[*Movie]())
+		for _, movieID := range []int{id, targetID} {
+			if titles[movieID] == nil {
+				return apperrors.NotFound("movie", "id", movieID)
+			}
+		}
+		source, target := titles[id], titles[targetID]
+		if source.Kind != target.Kind {
+			return apperrors.BadRequest(fmt.Errorf("%s can't be merged into a %s", source.Kind, target.Kind))
+		}
+
+		var clash bool
+		err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM movies s
+			INNER JOIN movies t on t.number = s.number
+			WHERE s.parent_id = $1 and t.parent_id = $2 and s.deleted_at IS NULL and t.deleted_at IS NULL)`,
+			id, targetID).Scan(&clash)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if clash {
+			return apperrors.BadRequest(fmt.Errorf("%s has nested titles with the same numbers as the target", source.Kind))
+		}
+
+		rows, err = tx.Query(ctx, "SELECT id, movie_id, user_id FROM reviews WHERE movie_id = ANY($1) and deleted_at IS NULL", []int{id, targetID})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		liveReviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*events.Review, error) {
+			var review events.Review
+			err := row.Scan(&review.ID, &review.MovieID, &review.UserID)
+			return &review, err
+		})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		rows, err = tx.Query(ctx, "SELECT id FROM movies WHERE parent_id = $1 and deleted_at IS NULL", id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		nestedIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		statements := []string{
+			// Credits and genres the target already has are dropped, the others go after the target ones
+			`DELETE FROM movie_stars s USING movie_stars t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.star_id = s.star_id and t.role = s.role`,
+			`UPDATE movie_stars
+			SET movie_id = $2, order_no = order_no + (SELECT coalesce(max(order_no) + 1, 0) FROM movie_stars WHERE movie_id = $2)
+			WHERE movie_id = $1`,
+			`DELETE FROM movie_genres s USING movie_genres t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.genre_id = s.genre_id`,
+			`UPDATE movie_genres
+			SET movie_id = $2, order_no = order_no + (SELECT coalesce(max(order_no) + 1, 0) FROM movie_genres WHERE movie_id = $2)
+			WHERE movie_id = $1`,
+			`DELETE FROM reviews r USING reviews o
+			WHERE r.user_id = o.user_id and r.movie_id IN ($1, $2) and o.movie_id IN ($1, $2) and r.movie_id <> o.movie_id
+				and (r.deleted_at IS NULL, r.created_at, r.id) < (o.deleted_at IS NULL, o.created_at, o.id)`,
+			"UPDATE reviews SET movie_id = $2, version = version + 1 WHERE movie_id = $1",
+			"UPDATE images SET is_primary = FALSE WHERE movie_id = $1 and EXISTS (SELECT 1 FROM images WHERE movie_id = $2 and is_primary)",
+			"UPDATE images SET movie_id = $2 WHERE movie_id = $1",
+			`DELETE FROM collection_movies s USING collection_movies t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.collection_id = s.collection_id`,
+			"UPDATE collection_movies SET movie_id = $2 WHERE movie_id = $1",
+			`DELETE FROM award_nominations s USING award_nominations t
+			WHERE s.movie_id = $1 and t.movie_id = $2
+				and t.ceremony_id = s.ceremony_id and t.category_id = s.category_id and t.star_id IS NOT DISTINCT FROM s.star_id`,
+			"UPDATE award_nominations SET movie_id = $2 WHERE movie_id = $1",
+			`DELETE FROM movie_translations s USING movie_translations t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.locale = s.locale`,
+			"UPDATE movie_translations SET movie_id = $2 WHERE movie_id = $1",
+			`DELETE FROM movie_certifications s USING movie_certifications t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.country = s.country`,
+			"UPDATE movie_certifications SET movie_id = $2 WHERE movie_id = $1",
+			`DELETE FROM movie_external_ids s USING movie_external_ids t
+			WHERE s.movie_id = $1 and t.movie_id = $2 and t.source = s.source`,
+			"UPDATE movie_external_ids SET movie_id = $2 WHERE movie_id = $1",
+			"UPDATE movies SET parent_id = $2 WHERE parent_id = $1 and deleted_at IS NULL",
+			`UPDATE movies t
+			SET description = CASE WHEN t.description = '' THEN s.description ELSE t.description END,
+				tagline = coalesce(t.tagline, s.tagline),
+				runtime = coalesce(t.runtime, s.runtime),
+				version = t.version + 1
+			FROM movies s
+			WHERE s.id = $1 and t.id = $2`,
+			"UPDATE movies SET merged_into = $2 WHERE merged_into = $1",
+			"UPDATE movies SET deleted_at = NOW(), merged_into = $2 WHERE id = $1",
+		}
+		for _, statement := range statements {
+			_, err = tx.Exec(ctx, statement, id, targetID)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		err = r.recordMergeEvents(ctx, tx, source, targetID, liveReviews, nestedIDs)
 		if err != nil {
 			return err
 		}
-		currentCast, err := r.starsRepository.GetRelationsByMovieID(ctx, id)
+
+		err = r.RecalculateRating(ctx, targetID)
 		if err != nil {
 			return err
 		}
-		return r.updateCast(ctx, currentCast, []*stars.MovieStarRelation{})
+		if source.ParentID != nil {
+			return r.RecalculateRating(ctx, *source.ParentID)
+		}
+		return nil
 	})
 	if err != nil {
 		return apperrors.EnsureInternal(err)
@@ -298,6 +661,233 @@ func (r *Repository) DeleteMovie(ctx context.Context, id int) error {
 	return nil
 }
 
+// recordMergeEvents records the deletion of the merged title, the update of the target and of the nested titles
+// moved to it, and what has become of the live reviews of both: they are either moved to the target or dropped.
+func (r *Repository) recordMergeEvents(ctx context.Context, tx pgx.Tx, source *Movie, targetID int, liveReviews []*events.Review, nestedIDs []int) error {
+	rows, err := tx.Query(ctx, "SELECT id FROM reviews WHERE id = ANY($1)", slices.Map(liveReviews, func(r *events.Review) int { return r.ID }))
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	keptIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	for _, review := range liveReviews {
+		switch {
+		case !slices.Contains(keptIDs, review.ID):
+			err = events.Record(ctx, tx, events.ReviewDeleted, review.ID, review)
+		case review.MovieID == source.ID:
+			review.MovieID = targetID
+			err = events.Record(ctx, tx, events.ReviewUpdated, review.ID, review)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, nestedID := range nestedIDs {
+		err = events.Record(ctx, tx, events.MovieUpdated, nestedID, &events.Movie{ID: nestedID, ParentID: &targetID})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = events.Record(ctx, tx, events.MovieDeleted, source.ID, &events.Movie{
+		ID:         source.ID,
+		Kind:       source.Kind,
+		ParentID:   source.ParentID,
+		MergedInto: &targetID,
+	})
+	if err != nil {
+		return err
+	}
+	return events.Record(ctx, tx, events.MovieUpdated, targetID, &events.Movie{ID: targetID})
+}
+
+// GetMergedInto returns the title the deleted one was merged into, or nil if it wasn't merged.
+func (r *Repository) GetMergedInto(ctx context.Context, id int) (*int, error) {
+	var targetID int
+	err := r.db.QueryRow(ctx, "SELECT merged_into FROM movies WHERE id = $1 and merged_into IS NOT NULL", id).Scan(&targetID)
+	if dbx.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return &targetID, nil
+}
+
+// GetDuplicates finds pairs of titles of the same kind and parent with similar titles released in the same year.
+func (r *Repository) GetDuplicates(ctx context.Context, limit int) ([]*MovieDuplicate, error) {
+	queryString := `
+	SELECT a.id, a.kind, a.parent_id, a.number, a.title, a.release_date, a.language, a.avg_rating, a.created_at,
+		b.id, b.kind, b.parent_id, b.number, b.title, b.release_date, b.language, b.avg_rating, b.created_at,
+		similarity(a.title, b.title) AS score
+	FROM movies a
+	INNER JOIN movies b on a.id < b.id and a.title % b.title
+	WHERE a.deleted_at IS NULL and b.deleted_at IS NULL
+		and a.kind = b.kind and a.parent_id IS NOT DISTINCT FROM b.parent_id
+		and extract(year FROM a.release_date) = extract(year FROM b.release_date)
+	ORDER BY score DESC, a.id, b.id
+	LIMIT $1`
+
+	rows, err := r.db.Query(ctx, queryString, limit)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	duplicates := []*MovieDuplicate{}
+	for rows.Next() {
+		duplicate := MovieDuplicate{Movie: &Movie{}, Duplicate: &Movie{}}
+		err = rows.Scan(
+			&duplicate.Movie.ID,
+			&duplicate.Movie.Kind,
+			&duplicate.Movie.ParentID,
+			&duplicate.Movie.Number,
+			&duplicate.Movie.Title,
+			&duplicate.Movie.ReleaseDate,
+			&duplicate.Movie.Language,
+			&duplicate.Movie.AvgRating,
+			&duplicate.Movie.CreatedAt,
+			&duplicate.Duplicate.ID,
+			&duplicate.Duplicate.Kind,
+			&duplicate.Duplicate.ParentID,
+			&duplicate.Duplicate.Number,
+			&duplicate.Duplicate.Title,
+			&duplicate.Duplicate.ReleaseDate,
+			&duplicate.Duplicate.Language,
+			&duplicate.Duplicate.AvgRating,
+			&duplicate.Duplicate.CreatedAt,
+			&duplicate.Similarity,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		duplicates = append(duplicates, &duplicate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return duplicates, nil
+}
+
+// RecalculateRating updates the average rating of a title and of all its ancestors, so that ratings
+// of episodes roll up to their seasons and series. The new ratings are pushed as live updates.
+func (r *Repository) RecalculateRating(ctx context.Context, movieID int) error {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := `
+	UPDATE movies m
+	SET avg_rating = (
+		SELECT avg(rating)
+		FROM reviews
+		WHERE deleted_at IS NULL and movie_id IN (SELECT title_subtree(m.id)))
+	WHERE id IN (SELECT title_lineage($1))
+	RETURNING id, avg_rating`
+
+	rows, err := q.Query(ctx, queryString, movieID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	type rating struct {
+		movieID   int
+		avgRating *float64
+	}
+	ratings, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*rating, error) {
+		var r rating
+		err := row.Scan(&r.movieID, &r.avgRating)
+		return &r, err
+	})
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if len(ratings) == 0 {
+		return apperrors.NotFound("movie", "id", movieID)
+	}
+
+	for _, rating := range ratings {
+		if err = live.Notify(ctx, q, live.RatingChanged, rating.movieID, &live.Rating{AvgRating: rating.avgRating}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkParent makes sure seasons belong to series, episodes belong to seasons and other kinds are top level.
+func (r *Repository) checkParent(ctx context.Context, movie *Movie) error {
+	expected, nested := parentKinds[movie.Kind]
+	if !nested {
+		if movie.ParentID != nil || movie.Number != nil {
+			return apperrors.BadRequest(fmt.Errorf("%s can't have a parent or a number", movie.Kind))
+		}
+		return nil
+	}
+
+	if movie.ParentID == nil || movie.Number == nil {
+		return apperrors.BadRequest(fmt.Errorf("%s must have a parent and a number", movie.Kind))
+	}
+
+	q := dbx.FromContext(ctx, r.db)
+	var kind string
+	err := q.QueryRow(ctx, "SELECT kind FROM movies WHERE id = $1 and deleted_at IS NULL", *movie.ParentID).Scan(&kind)
+	if dbx.IsNoRows(err) {
+		return apperrors.NotFound("movie", "id", *movie.ParentID)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if kind != expected {
+		return apperrors.BadRequest(fmt.Errorf("%s must belong to a %s", movie.Kind, expected))
+	}
+	return nil
+}
+
+// updateMetadata replaces the certifications and the external IDs of a movie.
+func (r *Repository) updateMetadata(ctx context.Context, movieID int, movie *MovieDetails) error {
+	q := dbx.FromContext(ctx, r.db)
+	_, err := q.Exec(ctx, "DELETE FROM movie_certifications WHERE movie_id = $1", movieID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	for _, c := range movie.Certifications {
+		_, err = q.Exec(ctx,
+			"INSERT INTO movie_certifications (movie_id, country, certification) VALUES ($1, $2, $3)",
+			movieID, c.Country, c.Certification)
+		if dbx.IsUniqueViolation(err, "pkey") {
+			return apperrors.BadRequest(fmt.Errorf("duplicate certification for country %q", c.Country))
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+	}
+
+	_, err = q.Exec(ctx, "DELETE FROM movie_external_ids WHERE movie_id = $1", movieID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	for source, externalID := range movie.ExternalIDs {
+		_, err = q.Exec(ctx,
+			"INSERT INTO movie_external_ids (movie_id, source, external_id) VALUES ($1, $2, $3)",
+			movieID, source, externalID)
+		if dbx.IsUniqueViolation(err, "source_external_id") {
+			return apperrors.AlreadyExists("movie", source, externalID)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+	}
+
+	return nil
+}
+
 func (r *Repository) updateGenres(ctx context.Context, current, next []*genres.MovieGenreRelation) error {
 	q := dbx.FromContext(ctx, r.db)
 	addFunc := func(mgo *genres.MovieGenreRelation) error {
@@ -326,9 +916,16 @@ func (r *Repository) updateGenres(ctx context.Context, current, next []*genres.M
 func (r *Repository) updateCast(ctx context.Context, current, next []*stars.MovieStarRelation) error {
 	q := dbx.FromContext(ctx, r.db)
 	addFunc := func(s *stars.MovieStarRelation) error {
+		characters := s.Characters
+		if characters == nil {
+			characters = []string{}
+		}
 		_, err := q.Exec(ctx,
-			"INSERT INTO movie_stars (movie_id, star_id, role, details, order_no) VALUES ($1, $2, $3, $4, $5)",
-			s.MovieID, s.StarID, s.Role, s.Details, s.OrderNo)
+			"INSERT INTO movie_stars (movie_id, star_id, role, characters, job, uncredited, voice, order_no) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			s.MovieID, s.StarID, s.Role, characters, s.Job, s.Uncredited, s.Voice, s.OrderNo)
+		if dbx.IsForeignKeyViolation(err, "role") {
+			return apperrors.BadRequest(fmt.Errorf("unknown credit role %q", s.Role))
+		}
 		if err != nil {
 			return apperrors.Internal(err)
 		}
@@ -358,3 +955,152 @@ func (r *Repository) Lock(ctx context.Context, tx pgx.Tx, movieID int) error {
 
 	return nil
 }
+
+// GetReviewsByMovieIDs returns up to limit first reviews of every movie.
+func (r *Repository) GetReviewsByMovieIDs(ctx context.Context, movieIDs []int, limit int) (map[int][]*MovieReview, error) {
+	queryString := `
+	SELECT id, movie_id, user_id, rating, title, content, created_at
+	FROM (
+		SELECT *, row_number() OVER (PARTITION BY movie_id ORDER BY id) AS n
+		FROM reviews
+		WHERE movie_id = ANY($1) and deleted_at IS NULL
+	) r
+	WHERE n <= $2
+	ORDER BY movie_id, id`
+
+	rows, err := r.db.Query(ctx, queryString, movieIDs, limit)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	reviews := make(map[int][]*MovieReview, len(movieIDs))
+	for rows.Next() {
+		var review MovieReview
+		err = rows.Scan(
+			&review.ID,
+			&review.MovieID,
+			&review.UserID,
+			&review.Rating,
+			&review.Title,
+			&review.Content,
+			&review.CreatedAt,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		reviews[review.MovieID] = append(reviews[review.MovieID], &review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return reviews, nil
+}
+
+func (r *Repository) GetTranslations(ctx context.Context, movieID int) ([]*MovieTranslation, error) {
+	translations, err := r.GetTranslationsByMovieIDs(ctx, []int{movieID})
+	if err != nil {
+		return nil, err
+	}
+	return translations[movieID], nil
+}
+
+func (r *Repository) GetTranslationsByMovieIDs(ctx context.Context, movieIDs []int) (map[int][]*MovieTranslation, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := `
+	SELECT movie_id, locale, title, description
+	FROM movie_translations
+	WHERE movie_id = ANY($1)
+	ORDER BY movie_id, locale`
+
+	rows, err := q.Query(ctx, queryString, movieIDs)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	translations := make(map[int][]*MovieTranslation)
+	for rows.Next() {
+		var translation MovieTranslation
+		err = rows.Scan(
+			&translation.MovieID,
+			&translation.Locale,
+			&translation.Title,
+			&translation.Description,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		translations[translation.MovieID] = append(translations[translation.MovieID], &translation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return translations, nil
+}
+
+// UpsertTranslation adds or replaces the translation of the movie, which counts as an update of the movie.
+func (r *Repository) UpsertTranslation(ctx context.Context, translation *MovieTranslation) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := `
+		INSERT INTO movie_translations (movie_id, locale, title, description)
+		SELECT id, $2, $3, $4
+		FROM movies
+		WHERE id = $1 and deleted_at IS NULL
+		ON CONFLICT (movie_id, locale) DO UPDATE
+		SET title = excluded.title, description = excluded.description`
+
+		cmdTag, err := tx.Exec(ctx, queryString,
+			translation.MovieID,
+			translation.Locale,
+			translation.Title,
+			translation.Description,
+		)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("movie", "id", translation.MovieID)
+		}
+
+		return events.Record(ctx, tx, events.MovieUpdated, translation.MovieID, &events.Movie{ID: translation.MovieID})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteTranslation(ctx context.Context, movieID int, locale string) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		cmdTag, err := tx.Exec(ctx,
+			"DELETE FROM movie_translations WHERE movie_id = $1 and locale = $2",
+			movieID, locale)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("movie translation", "locale", locale)
+		}
+
+		return events.Record(ctx, tx, events.MovieUpdated, movieID, &events.Movie{ID: movieID})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+
+	return nil
+}
+
+// nonNil makes sure an empty array rather than NULL is written to NOT NULL array columns.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -2,24 +2,39 @@ package movies
 
 import (
 	"context"
+	"errors"
 
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/locale"
 	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/collections"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
 	"golang.org/x/sync/errgroup"
 )
 
 type Service struct {
-	repo         *Repository
-	genreService *genres.Service
-	starsService *stars.Service
+	repo               *Repository
+	genreService       *genres.Service
+	starsService       *stars.Service
+	imagesService      *images.Service
+	collectionsService *collections.Service
+	awardsService      *awards.Service
 }
 
-func NewService(repo *Repository, genresService *genres.Service, starsService *stars.Service) *Service {
+func NewService(repo *Repository, genresService *genres.Service, starsService *stars.Service, imagesService *images.Service, collectionsService *collections.Service, awardsService *awards.Service) *Service {
 	return &Service{
-		repo:         repo,
-		genreService: genresService,
-		starsService: starsService,
+		repo:               repo,
+		genreService:       genresService,
+		starsService:       starsService,
+		imagesService:      imagesService,
+		collectionsService: collectionsService,
+		awardsService:      awardsService,
 	}
 }
 
@@ -33,23 +48,112 @@ func (s *Service) CreateMovie(ctx context.Context, movie *MovieDetails) error {
 	logger.Info("movie created",
 		"movie_id", movie.ID)
 
-	return s.assemble(ctx, movie)
+	return s.include(ctx, []*Movie{&movie.Movie}, sparse.ParseIncludes(nil, IncludeGenres, IncludeCast))
 }
 
-func (s *Service) GetMovieByID(ctx context.Context, id int) (*MovieDetails, error) {
+func (s *Service) GetMovieByID(ctx context.Context, id int, locales []string, includes sparse.Includes) (*MovieDetails, error) {
 	movie, err := s.repo.GetMovieByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	err = s.assemble(ctx, movie)
+	err = s.include(ctx, []*Movie{&movie.Movie}, includes)
 	if err != nil {
 		return nil, err
 	}
+
+	movie.Collections, err = s.collectionsService.GetByMovieID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	movie.Awards, err = s.awardsService.GetSummaryByMovieID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(locales) > 0 {
+		translations, err := s.repo.GetTranslations(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if translation := pickTranslation(translations, movie.Language, locales); translation != nil {
+			movie.Movie.localize(translation)
+			if translation.Description != nil {
+				movie.Description = *translation.Description
+			}
+		}
+	}
+
 	return movie, nil
 }
 
-func (s *Service) GetAllPaginated(ctx context.Context, starID *int, searchTerm *string, sortByRating *string, offset, limit int) ([]*Movie, int, error) {
-	return s.repo.GetAllPaginated(ctx, starID, searchTerm, sortByRating, offset, limit)
+func (s *Service) GetAllPaginated(ctx context.Context, filter *Filter, sort *string, locales []string, includes sparse.Includes, params *pagination.Params) (*pagination.Page[Movie], error) {
+	page, err := s.repo.GetAllPaginated(ctx, filter, sort, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.include(ctx, page.Items, includes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(locales) > 0 && len(page.Items) > 0 {
+		ids := slices.Map(page.Items, func(m *Movie) int { return m.ID })
+		translations, err := s.repo.GetTranslationsByMovieIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, movie := range page.Items {
+			if translation := pickTranslation(translations[movie.ID], movie.Language, locales); translation != nil {
+				movie.localize(translation)
+			}
+		}
+	}
+
+	return page, nil
+}
+
+func (s *Service) GetTranslations(ctx context.Context, movieID int) ([]*MovieTranslation, error) {
+	_, err := s.repo.GetMovieByID(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetTranslations(ctx, movieID)
+}
+
+func (s *Service) PutTranslation(ctx context.Context, translation *MovieTranslation) error {
+	err := s.repo.UpsertTranslation(ctx, translation)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("movie translation saved",
+		"movie_id", translation.MovieID,
+		"locale", translation.Locale,
+	)
+
+	return nil
+}
+
+func (s *Service) DeleteTranslation(ctx context.Context, movieID int, locale string) error {
+	err := s.repo.DeleteTranslation(ctx, movieID, locale)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("movie translation deleted",
+		"movie_id", movieID,
+		"locale", locale,
+	)
+
+	return nil
+}
+
+func (s *Service) GetSuggestions(ctx context.Context, query string, limit int) ([]*MovieSuggestion, error) {
+	return s.repo.GetSuggestions(ctx, query, limit)
 }
 
 func (s *Service) UpdateMovie(ctx context.Context, id int, movie *MovieDetails) error {
@@ -65,8 +169,8 @@ func (s *Service) UpdateMovie(ctx context.Context, id int, movie *MovieDetails)
 	return nil
 }
 
-func (s *Service) DeleteMovie(ctx context.Context, id int) error {
-	err := s.repo.DeleteMovie(ctx, id)
+func (s *Service) DeleteMovie(ctx context.Context, id int, version *int) error {
+	err := s.repo.DeleteMovie(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -79,20 +183,105 @@ func (s *Service) DeleteMovie(ctx context.Context, id int) error {
 	return nil
 }
 
-func (s *Service) assemble(ctx context.Context, movie *MovieDetails) error {
+func (s *Service) Merge(ctx context.Context, id, targetID int) (*MovieDetails, error) {
+	if id == targetID {
+		return nil, apperrors.BadRequest(errors.New("movie can't be merged into itself"))
+	}
+
+	err := s.repo.Merge(ctx, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("movies merged",
+		"movie_id", id,
+		"target_id", targetID)
+
+	return s.GetMovieByID(ctx, targetID, nil, sparse.ParseIncludes(nil, IncludeGenres, IncludeCast))
+}
+
+func (s *Service) GetMergedInto(ctx context.Context, id int) (*int, error) {
+	return s.repo.GetMergedInto(ctx, id)
+}
+
+func (s *Service) GetDuplicates(ctx context.Context, limit int) ([]*MovieDuplicate, error) {
+	return s.repo.GetDuplicates(ctx, limit)
+}
+
+// pickTranslation returns the translation that matches the preferred locales best or nil if there is none
+// or the original language of the movie matches them better.
+func pickTranslation(translations []*MovieTranslation, original string, locales []string) *MovieTranslation {
+	original = locale.Normalize(original)
+	available := append([]string{original}, slices.Map(translations, func(t *MovieTranslation) string { return t.Locale })...)
+	matched, ok := locale.Match(locales, available)
+	if !ok || matched == original {
+		return nil
+	}
+
+	for _, translation := range translations {
+		if translation.Locale == matched {
+			return translation
+		}
+	}
+	return nil
+}
+
+// include batch-loads the requested relations for all the movies at once.
+func (s *Service) include(ctx context.Context, movies []*Movie, includes sparse.Includes) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := slices.Map(movies, func(m *Movie) int { return m.ID })
+	byID := slices.ToMap(movies, func(m *Movie) int { return m.ID }, slices.NoChangeFunc[*Movie]())
 	group, groupCtx := errgroup.WithContext(ctx)
 
-	group.Go(func() error {
-		var err error
-		movie.Genres, err = s.genreService.GetGenresByMovieID(groupCtx, movie.ID)
-		return err
-	})
+	if includes.Has(IncludeGenres) {
+		group.Go(func() error {
+			movieGenres, err := s.genreService.GetGenresByMovieIDs(groupCtx, ids)
+			for id, movie := range byID {
+				movie.Genres = movieGenres[id]
+			}
+			return err
+		})
+	}
 
-	group.Go(func() error {
-		var err error
-		movie.Cast, err = s.starsService.GetCastByMovieID(groupCtx, movie.ID)
-		return err
-	})
+	if includes.Has(IncludeCast) {
+		group.Go(func() error {
+			cast, err := s.starsService.GetCastByMovieIDs(groupCtx, ids)
+			for id, movie := range byID {
+				movie.Cast = cast[id]
+			}
+			return err
+		})
+	}
+
+	if includes.Has(IncludeReviews) {
+		group.Go(func() error {
+			reviews, err := s.repo.GetReviewsByMovieIDs(groupCtx, ids, IncludedReviewsLimit)
+			for id, movie := range byID {
+				movie.Reviews = reviews[id]
+			}
+			return err
+		})
+	}
+
+	if includes.Has(IncludeImage) || includes.Has(IncludeImages) {
+		group.Go(func() error {
+			movieImages, err := s.imagesService.GetByMovieIDs(groupCtx, ids, !includes.Has(IncludeImages))
+			for id, movie := range byID {
+				gallery := movieImages[id]
+				if includes.Has(IncludeImage) && len(gallery) > 0 && gallery[0].Primary {
+					movie.Image = gallery[0]
+				}
+				if includes.Has(IncludeImages) {
+					movie.Images = gallery
+				}
+			}
+			return err
+		})
+	}
 
 	return group.Wait()
 }
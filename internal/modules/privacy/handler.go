@@ -0,0 +1,63 @@
+package privacy
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+)
+
+type Handler struct {
+	Service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		Service: service,
+	}
+}
+
+func (h *Handler) Export(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.ExportUserDataRequest](c)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.Service.Export(c.Request().Context(), req.UserID)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"user-%d.json\"", req.UserID))
+	return c.JSON(http.StatusOK, export)
+}
+
+func (h *Handler) ScheduleErasure(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.ScheduleErasureRequest](c)
+	if err != nil {
+		return err
+	}
+
+	erasure, err := h.Service.ScheduleErasure(c.Request().Context(), req.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, erasure)
+}
+
+func (h *Handler) CancelErasure(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CancelErasureRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.CancelErasure(c.Request().Context(), req.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
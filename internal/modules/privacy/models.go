@@ -0,0 +1,29 @@
+package privacy
+
+import (
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+)
+
+// EraseJobKind is the kind of the job erasing the users whose erasure is due.
+const EraseJobKind = "privacy.erase"
+
+// Export is the archive of the personal data of a user.
+type Export struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	Profile    *users.User       `json:"profile"`
+	Reviews    []*reviews.Review `json:"reviews"`
+}
+
+type Erasure struct {
+	UserID  int       `json:"user_id"`
+	EraseAt time.Time `json:"erase_at"`
+}
+
+// Erased are the users erased at once and the number of their reviews removed along with them.
+type Erased struct {
+	UserIDs []int
+	Reviews int
+}
@@ -0,0 +1,25 @@
+package privacy
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+)
+
+type Module struct {
+	Handler *Handler
+	Service *Service
+}
+
+func NewModule(db *pgxpool.Pool, usersModule *users.Module, moviesModule *movies.Module, reviewsModule *reviews.Module, cfg config.PrivacyConfig) *Module {
+	repo := NewRepository(db, moviesModule.Repository)
+	service := NewService(repo, usersModule.Repository, reviewsModule.Service, cfg.ErasureGracePeriod)
+	handler := NewHandler(service)
+
+	return &Module{
+		Handler: handler,
+		Service: service,
+	}
+}
@@ -0,0 +1,106 @@
+package privacy
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+)
+
+type Repository struct {
+	db         *pgxpool.Pool
+	moviesRepo *movies.Repository
+}
+
+func NewRepository(db *pgxpool.Pool, moviesRepo *movies.Repository) *Repository {
+	return &Repository{
+		db:         db,
+		moviesRepo: moviesRepo,
+	}
+}
+
+// EraseDue permanently removes the users whose erasure is due together with their reviews, and returns them.
+// The ratings of the movies that lose live reviews are recalculated. The events and webhook deliveries
+// of the users and their reviews are scrubbed of the personal data: the ones in the outbox are left with
+// the IDs only, so that they are still dispatched, the deliveries are removed.
+func (r *Repository) EraseDue(ctx context.Context) (*Erased, error) {
+	var erased Erased
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, "SELECT id FROM users WHERE erase_at < NOW() FOR UPDATE")
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		erased.UserIDs, err = pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if len(erased.UserIDs) == 0 {
+			return nil
+		}
+
+		rows, err = tx.Query(ctx, "SELECT DISTINCT movie_id FROM reviews WHERE user_id = ANY($1) and deleted_at IS NULL", erased.UserIDs)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		affectedMovieIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		rows, err = tx.Query(ctx, "DELETE FROM reviews WHERE user_id = ANY($1) RETURNING id, movie_id", erased.UserIDs)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		erasedReviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*events.Review, error) {
+			var review events.Review
+			err := row.Scan(&review.ID, &review.MovieID)
+			return &review, err
+		})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		erased.Reviews = len(erasedReviews)
+
+		statements := []string{
+			`UPDATE outbox_events SET payload = jsonb_build_object('id', subject_id)
+			WHERE type LIKE 'user.%' and subject_id = ANY($1)`,
+			`UPDATE outbox_events SET payload = payload - 'user_id'
+			WHERE type LIKE 'review.%' and (payload->>'user_id')::int = ANY($1)`,
+			`DELETE FROM webhook_deliveries
+			WHERE (event_type LIKE 'user.%' and (body->>'subject_id')::int = ANY($1))
+				or (event_type LIKE 'review.%' and (body->'payload'->>'user_id')::int = ANY($1))`,
+			"DELETE FROM users WHERE id = ANY($1)",
+		}
+		for _, statement := range statements {
+			if _, err = tx.Exec(ctx, statement, erased.UserIDs); err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		for _, movieID := range affectedMovieIDs {
+			if err = r.moviesRepo.RecalculateRating(ctx, movieID); err != nil {
+				return err
+			}
+		}
+
+		for _, review := range erasedReviews {
+			if err = events.Record(ctx, tx, events.ReviewPurged, review.ID, review); err != nil {
+				return err
+			}
+		}
+		for _, id := range erased.UserIDs {
+			if err = events.Record(ctx, tx, events.UserPurged, id, &events.User{ID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.EnsureInternal(err)
+	}
+	return &erased, nil
+}
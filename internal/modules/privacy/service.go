@@ -0,0 +1,102 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
+	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
+)
+
+type Service struct {
+	repo               *Repository
+	usersRepo          *users.Repository
+	reviewsService     *reviews.Service
+	erasureGracePeriod time.Duration
+}
+
+func NewService(repo *Repository, usersRepo *users.Repository, reviewsService *reviews.Service, erasureGracePeriod time.Duration) *Service {
+	return &Service{
+		repo:               repo,
+		usersRepo:          usersRepo,
+		reviewsService:     reviewsService,
+		erasureGracePeriod: erasureGracePeriod,
+	}
+}
+
+func (s *Service) Export(ctx context.Context, userID int) (*Export, error) {
+	user, err := s.usersRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userReviews, err := s.reviewsService.GetAllByUserID(ctx, userID, sparse.Includes{reviews.IncludeMovie: true})
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("user data exported",
+		"user_id", userID)
+
+	return &Export{
+		ExportedAt: time.Now().UTC(),
+		Profile:    user,
+		Reviews:    userReviews,
+	}, nil
+}
+
+// ScheduleErasure schedules the erasure of the user after the grace period. The user and all their reviews
+// are deleted for good by the erasure job, which recalculates the ratings of the reviewed movies.
+func (s *Service) ScheduleErasure(ctx context.Context, userID int) (*Erasure, error) {
+	eraseAt, err := s.usersRepo.ScheduleErasure(ctx, userID, s.erasureGracePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("user erasure scheduled",
+		"user_id", userID,
+		"erase_at", eraseAt)
+
+	return &Erasure{UserID: userID, EraseAt: eraseAt}, nil
+}
+
+func (s *Service) CancelErasure(ctx context.Context, userID int) error {
+	err := s.usersRepo.CancelErasure(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("user erasure cancelled",
+		"user_id", userID)
+
+	return nil
+}
+
+// Erase removes the users whose erasure is due.
+func (s *Service) Erase(ctx context.Context) error {
+	erased, err := s.repo.EraseDue(ctx)
+	if err != nil {
+		return err
+	}
+	if len(erased.UserIDs) == 0 {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("users erased",
+		"users", len(erased.UserIDs),
+		"reviews", erased.Reviews)
+
+	return nil
+}
+
+// EraseJob runs the erasure as a background job, it takes no payload.
+func (s *Service) EraseJob(ctx context.Context, _ json.RawMessage) error {
+	return s.Erase(ctx)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/mkuptsov/movie-reviews/internal/config"
 	"github.com/mkuptsov/movie-reviews/internal/echox"
 	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
 )
 
 type Handler struct {
@@ -38,14 +39,17 @@ func (h *Handler) GetAll(c echo.Context) error {
 			return nil, apperrors.BadRequest(errors.New("either movie_id or user_id must be provided"))
 		}
 
-		pagination.SetDefaults(&req.PaginatedRequest, h.paginationConfig)
-		offset, limit := pagination.OffsetLimit(&req.PaginatedRequest)
+		params, err := pagination.Resolve(&req.PaginatedRequest, h.paginationConfig)
+		if err != nil {
+			return nil, err
+		}
 
-		reviews, total, err := h.service.GetPaginated(c.Request().Context(), req.MovieID, req.UserID, offset, limit)
+		includes := sparse.ParseIncludes(req.Include)
+		page, err := h.service.GetPaginated(c.Request().Context(), req.MovieID, req.UserID, req.Sort, includes, params)
 		if err != nil {
 			return nil, err
 		}
-		return pagination.Response(&req.PaginatedRequest, total, reviews), nil
+		return sparse.ProjectPage(pagination.Response(&req.PaginatedRequest, params, page), sparse.Fields(req.Fields, includes))
 	})
 	if err != nil {
 		return err
@@ -60,12 +64,19 @@ func (h *Handler) Get(c echo.Context) error {
 		return err
 	}
 
-	review, err := h.service.GetByID(c.Request().Context(), req.ReviewID)
+	includes := sparse.ParseIncludes(req.Include)
+	review, err := h.service.GetByID(c.Request().Context(), req.ReviewID, includes)
+	if err != nil {
+		return err
+	}
+
+	res, err := sparse.Project(review, sparse.Fields(req.Fields, includes))
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, review)
+	// the included movie and user change without the version of the review
+	return echox.JSONOrNotModified(c, review.Version, res)
 }
 
 func (h *Handler) Create(c echo.Context) error {
@@ -95,21 +106,48 @@ func (h *Handler) Update(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	if err = h.service.Update(c.Request().Context(), req.ReviewID, req.UserID, req.Title, req.Content, req.Rating); err != nil {
+	newVersion, err := h.service.Update(c.Request().Context(), req.ReviewID, req.UserID, req.Title, req.Content, req.Rating, version)
+	if err != nil {
 		return err
 	}
 
+	if err = h.setETag(c, req.ReviewID, newVersion); err != nil {
+		return err
+	}
 	return c.NoContent(http.StatusOK)
 }
 
+// setETag sets the ETag of the review as GET returns it by default, unless the review has changed again since the update.
+func (h *Handler) setETag(c echo.Context, id, version int) error {
+	review, err := h.service.GetByID(c.Request().Context(), id, nil)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if review.Version != version {
+		return nil
+	}
+	return echox.SetRepresentationETag(c, version, review)
+}
+
 func (h *Handler) Delete(c echo.Context) error {
 	req, err := echox.BindAndValidate[contracts.DeleteReviewRequest](c)
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	if err = h.service.Delete(c.Request().Context(), req.ReviewID, req.UserID); err != nil {
+	if err = h.service.Delete(c.Request().Context(), req.ReviewID, req.UserID, version); err != nil {
 		return err
 	}
 
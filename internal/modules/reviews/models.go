@@ -1,14 +1,33 @@
 package reviews
 
-import "time"
+import (
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+)
+
+// Relations of a review which can be requested with the include parameter.
+const (
+	IncludeMovie = "movie"
+	IncludeUser  = "user"
+)
 
 type Review struct {
-	ID        int        `json:"id"`
-	MovieID   int        `json:"movie_id"`
-	UserID    int        `json:"user_id"`
-	Rating    int        `json:"rating"`
-	Title     string     `json:"title"`
-	Content   string     `json:"content"`
-	CreatedAt time.Time  `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID        int           `json:"id"`
+	MovieID   int           `json:"movie_id"`
+	UserID    int           `json:"user_id"`
+	Rating    int           `json:"rating"`
+	Title     string        `json:"title"`
+	Content   string        `json:"content"`
+	CreatedAt time.Time     `json:"created_at"`
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"`
+	Version   int           `json:"version"`
+	Movie     *movies.Movie `json:"movie,omitempty"`
+	User      *Author       `json:"user,omitempty"`
+}
+
+// Author is the public part of the user who wrote a review.
+type Author struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
 }
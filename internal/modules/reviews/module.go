@@ -14,7 +14,7 @@ type Module struct {
 
 func NewModule(db *pgxpool.Pool, moviesModule *movies.Module, paginationConfig config.PaginationConfig) *Module {
 	repo := NewRepository(db, moviesModule.Repository)
-	service := NewService(repo)
+	service := NewService(repo, moviesModule.Repository)
 	handler := NewHandler(service, paginationConfig)
 
 	return &Module{
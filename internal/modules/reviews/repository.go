@@ -8,9 +8,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/live"
 	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
 )
 
+var sortFields = dbx.SortFields{
+	"created_at": {{Expr: "created_at", Type: dbx.KeyTimestamp}},
+	"rating":     {{Expr: "coalesce(rating, 0)"}},
+}
+
 type Repository struct {
 	db         *pgxpool.Pool
 	moviesRepo *movies.Repository
@@ -42,7 +50,25 @@ func (r *Repository) Create(ctx context.Context, review *Review) error {
 			return apperrors.Internal(err)
 		}
 
-		return r.recalculateMovieRating(ctx, review.MovieID)
+		err = events.Record(ctx, tx, events.ReviewPosted, review.ID, &events.Review{
+			ID:      review.ID,
+			MovieID: review.MovieID,
+			UserID:  review.UserID,
+			Rating:  review.Rating,
+		})
+		if err != nil {
+			return err
+		}
+		err = live.Notify(ctx, tx, live.ReviewCreated, review.MovieID, &live.Review{
+			ID:     review.ID,
+			UserID: review.UserID,
+			Rating: review.Rating,
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.moviesRepo.RecalculateRating(ctx, review.MovieID)
 	})
 	if err != nil {
 		return apperrors.EnsureInternal(err)
@@ -57,9 +83,9 @@ func (r *Repository) GetByID(ctx context.Context, reviewID int) (*Review, error)
 
 	err := q.QueryRow(
 		ctx,
-		"select id, movie_id, user_id, title, content, rating, created_at from reviews where deleted_at is null and id = $1",
+		"select id, movie_id, user_id, title, content, rating, created_at, version from reviews where deleted_at is null and id = $1",
 		reviewID).
-		Scan(&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt)
+		Scan(&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt, &review.Version)
 
 	switch {
 	case dbx.IsNoRows(err):
@@ -71,13 +97,13 @@ func (r *Repository) GetByID(ctx context.Context, reviewID int) (*Review, error)
 	return &review, nil
 }
 
-func (r *Repository) GetPaginated(ctx context.Context, movieID, userID *int, offset int, limit int) ([]*Review, int, error) {
+func (r *Repository) GetPaginated(ctx context.Context, movieID, userID *int, sort *string, params *pagination.Params) (*pagination.Page[Review], error) {
 	selectQuery := dbx.StatementBuilder.
-		Select("id", "movie_id", "user_id", "title", "content", "rating", "created_at").
+		Select("id", "movie_id", "user_id", "title", "content", "rating", "created_at", "version").
 		From("reviews").
 		Where("deleted_at is null").
-		Limit(uint64(limit)).
-		Offset(uint64(offset))
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
 
 	countQuery := dbx.StatementBuilder.
 		Select("count(*)").
@@ -94,12 +120,23 @@ func (r *Repository) GetPaginated(ctx context.Context, movieID, userID *int, off
 		countQuery = countQuery.Where("user_id = ?", *userID)
 	}
 
+	keyset := dbx.Keyset{{Expr: "id"}}
+	if sort != nil {
+		keyset = dbx.SortKeyset(*sort, sortFields, keyset...)
+	}
+	selectQuery, err := keyset.Apply(selectQuery, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
+	}
+
 	b := &pgx.Batch{}
-	if err := dbx.QueueBatchSelect(b, selectQuery); err != nil {
-		return nil, 0, apperrors.Internal(err)
+	if err = dbx.QueueBatchSelect(b, selectQuery); err != nil {
+		return nil, apperrors.Internal(err)
 	}
-	if err := dbx.QueueBatchSelect(b, countQuery); err != nil {
-		return nil, 0, apperrors.Internal(err)
+	if params.WithTotal {
+		if err = dbx.QueueBatchSelect(b, countQuery); err != nil {
+			return nil, apperrors.Internal(err)
+		}
 	}
 
 	br := r.db.SendBatch(ctx, b)
@@ -107,32 +144,165 @@ func (r *Repository) GetPaginated(ctx context.Context, movieID, userID *int, off
 
 	rows, err := br.Query()
 	if err != nil {
-		return nil, 0, apperrors.Internal(err)
+		return nil, apperrors.Internal(err)
 	}
 	defer rows.Close()
 
 	var reviews []*Review
+	var keys [][]string
 	for rows.Next() {
 		var review Review
-		if err = rows.Scan(&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt); err != nil {
-			return nil, 0, apperrors.Internal(err)
+		key := keyset.NewKey()
+		dest := append([]any{&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt, &review.Version}, keyset.ScanDest(key)...)
+		if err = rows.Scan(dest...); err != nil {
+			return nil, apperrors.Internal(err)
 		}
 		reviews = append(reviews, &review)
+		keys = append(keys, key)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, apperrors.Internal(err)
+		return nil, apperrors.Internal(err)
+	}
+
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		if err = br.QueryRow().Scan(total); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, reviews, keys, total), nil
+}
+
+// GetAllByUserID returns all the reviews of the user, the deleted ones included, oldest first.
+func (r *Repository) GetAllByUserID(ctx context.Context, userID int) ([]*Review, error) {
+	rows, err := r.db.Query(
+		ctx,
+		"select id, movie_id, user_id, title, content, rating, created_at, deleted_at, version from reviews where user_id = $1 order by id",
+		userID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
 	}
+	defer rows.Close()
 
-	var total int
-	if err = br.QueryRow().Scan(&total); err != nil {
-		return nil, 0, apperrors.Internal(err)
+	reviews := []*Review{}
+	for rows.Next() {
+		var review Review
+		err = rows.Scan(&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt, &review.DeletedAt, &review.Version)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		reviews = append(reviews, &review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
 	}
 
-	return reviews, total, nil
+	return reviews, nil
+}
+
+// GetFirstPagesByMovieIDs returns the first page of the reviews of every movie, the way GetPaginated selects it
+// without a cursor, in a single query.
+func (r *Repository) GetFirstPagesByMovieIDs(ctx context.Context, movieIDs []int, sort *string, limit int) (map[int]*pagination.Page[Review], error) {
+	return r.getFirstPages(ctx, "movie_id", movieIDs, sort, limit)
 }
 
-func (r *Repository) Update(ctx context.Context, reviewID, userID int, title, content string, rating int) error {
+// GetFirstPagesByUserIDs is the counterpart of GetFirstPagesByMovieIDs for the reviews of the users.
+func (r *Repository) GetFirstPagesByUserIDs(ctx context.Context, userIDs []int, sort *string, limit int) (map[int]*pagination.Page[Review], error) {
+	return r.getFirstPages(ctx, "user_id", userIDs, sort, limit)
+}
+
+// getFirstPages numbers the reviews of every owner in the order of the page and selects the first limit+1 of them,
+// the owner is the movie or the user referenced by the column. The pages are counted with a window as well.
+func (r *Repository) getFirstPages(ctx context.Context, column string, ids []int, sort *string, limit int) (map[int]*pagination.Page[Review], error) {
+	keyset := dbx.Keyset{{Expr: "id"}}
+	if sort != nil {
+		keyset = dbx.SortKeyset(*sort, sortFields, keyset...)
+	}
+
+	numbered := dbx.StatementBuilder.
+		Select("id", "movie_id", "user_id", "title", "content", "rating", "created_at", "version", "count(*) OVER (PARTITION BY "+column+")").
+		From("reviews").
+		Where("deleted_at is null").
+		Where(column+" = any(?)", ids)
+	selectQuery := dbx.StatementBuilder.
+		Select("*").
+		FromSelect(keyset.Number(numbered, column), "r").
+		Where("row_number <= ?", limit+1).
+		OrderBy(column, "row_number")
+
+	queryString, args, err := selectQuery.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	rows, err := r.db.Query(ctx, queryString, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	reviews := make(map[int][]*Review, len(ids))
+	keys := make(map[int][][]string, len(ids))
+	totals := make(map[int]int, len(ids))
+	for rows.Next() {
+		var review Review
+		var total, n int
+		key := keyset.NewKey()
+		dest := []any{&review.ID, &review.MovieID, &review.UserID, &review.Title, &review.Content, &review.Rating, &review.CreatedAt, &review.Version, &total}
+		dest = append(append(dest, keyset.ScanDest(key)...), &n)
+		if err = rows.Scan(dest...); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		owner := review.MovieID
+		if column == "user_id" {
+			owner = review.UserID
+		}
+		reviews[owner] = append(reviews[owner], &review)
+		keys[owner] = append(keys[owner], key)
+		totals[owner] = total
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	params := &pagination.Params{Limit: limit, WithTotal: true}
+	pages := make(map[int]*pagination.Page[Review], len(ids))
+	for _, id := range ids {
+		total := totals[id]
+		pages[id] = pagination.NewPage(params, reviews[id], keys[id], &total)
+	}
+	return pages, nil
+}
+
+func (r *Repository) GetAuthorsByIDs(ctx context.Context, ids []int) (map[int]*Author, error) {
+	rows, err := r.db.Query(ctx, "select id, username from users where id = any($1)", ids)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	authors := make(map[int]*Author, len(ids))
+	for rows.Next() {
+		var author Author
+		if err = rows.Scan(&author.ID, &author.Username); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		authors[author.ID] = &author
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return authors, nil
+}
+
+// Update updates the review if it still has the given version, any version will do when it is nil,
+// and returns the new version.
+func (r *Repository) Update(ctx context.Context, reviewID, userID int, title, content string, rating int, version *int) (int, error) {
+	var newVersion int
 	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
 		review, err := r.GetByID(ctx, reviewID)
 		if err != nil {
@@ -141,28 +311,48 @@ func (r *Repository) Update(ctx context.Context, reviewID, userID int, title, co
 		if err = r.moviesRepo.Lock(ctx, tx, review.MovieID); err != nil {
 			return err
 		}
-		n, err := tx.Exec(
+		err = tx.QueryRow(
 			ctx,
-			"update reviews set title = $1, content = $2, rating = $3 where deleted_at is null and id = $4 and user_id = $5",
-			title, content, rating, reviewID, userID)
-		if err != nil {
+			`update reviews set title = $1, content = $2, rating = $3, version = version + 1
+			where deleted_at is null and id = $4 and user_id = $5 and ($6::int is null or version = $6)
+			returning version`,
+			title, content, rating, reviewID, userID, version).
+			Scan(&newVersion)
+		switch {
+		case dbx.IsNoRows(err):
+			return r.specifyModificationError(ctx, reviewID, userID, version)
+		case err != nil:
 			return apperrors.Internal(err)
 		}
 
-		if n.RowsAffected() == 0 {
-			return r.specifyModificationError(ctx, reviewID, userID)
+		err = events.Record(ctx, tx, events.ReviewUpdated, reviewID, &events.Review{
+			ID:      reviewID,
+			MovieID: review.MovieID,
+			UserID:  userID,
+			Rating:  rating,
+		})
+		if err != nil {
+			return err
+		}
+		err = live.Notify(ctx, tx, live.ReviewUpdated, review.MovieID, &live.Review{
+			ID:     reviewID,
+			UserID: userID,
+			Rating: rating,
+		})
+		if err != nil {
+			return err
 		}
 
-		return r.recalculateMovieRating(ctx, review.MovieID)
+		return r.moviesRepo.RecalculateRating(ctx, review.MovieID)
 	})
 	if err != nil {
-		return apperrors.EnsureInternal(err)
+		return 0, apperrors.EnsureInternal(err)
 	}
 
-	return nil
+	return newVersion, nil
 }
 
-func (r *Repository) Delete(ctx context.Context, reviewID, userID int) error {
+func (r *Repository) Delete(ctx context.Context, reviewID, userID int, version *int) error {
 	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
 		review, err := r.GetByID(ctx, reviewID)
 		if err != nil {
@@ -172,19 +362,35 @@ func (r *Repository) Delete(ctx context.Context, reviewID, userID int) error {
 			return err
 		}
 
-		n, err := r.db.Exec(
+		n, err := tx.Exec(
 			ctx,
-			"update reviews set deleted_at = now() where deleted_at is null and id = $1 and user_id = $2",
-			reviewID, userID)
+			"update reviews set deleted_at = now() where deleted_at is null and id = $1 and user_id = $2 and ($3::int is null or version = $3)",
+			reviewID, userID, version)
 		if err != nil {
 			return apperrors.Internal(err)
 		}
 
 		if n.RowsAffected() == 0 {
-			return r.specifyModificationError(ctx, reviewID, userID)
+			return r.specifyModificationError(ctx, reviewID, userID, version)
 		}
 
-		return r.recalculateMovieRating(ctx, review.MovieID)
+		err = events.Record(ctx, tx, events.ReviewDeleted, reviewID, &events.Review{
+			ID:      reviewID,
+			MovieID: review.MovieID,
+			UserID:  userID,
+		})
+		if err != nil {
+			return err
+		}
+		err = live.Notify(ctx, tx, live.ReviewDeleted, review.MovieID, &live.Review{
+			ID:     reviewID,
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.moviesRepo.RecalculateRating(ctx, review.MovieID)
 	})
 	if err != nil {
 		return apperrors.EnsureInternal(err)
@@ -193,10 +399,11 @@ func (r *Repository) Delete(ctx context.Context, reviewID, userID int) error {
 	return nil
 }
 
-func (r *Repository) specifyModificationError(ctx context.Context, reviewID, userID int) error {
-	// Review is not found by reviewID and userID then there are two possibilities:
+func (r *Repository) specifyModificationError(ctx context.Context, reviewID, userID int, version *int) error {
+	// Review is not found by reviewID, userID and version then there are three possibilities:
 	// 1. Review with reviewID does not exist
 	// 2. Review with reviewID exists, but it is not owned by userID
+	// 3. Review with reviewID has another version
 	review, err := r.GetByID(ctx, reviewID)
 	if err != nil {
 		return err
@@ -206,19 +413,10 @@ func (r *Repository) specifyModificationError(ctx context.Context, reviewID, use
 		return apperrors.Forbidden(fmt.Sprintf("review with id %d is not owned by user with id %d", reviewID, userID))
 	}
 
-	// If we got here, then something is wrong
-	return apperrors.Internal(fmt.Errorf("unexpected error creating/updating review with id %d", reviewID))
-}
-
-func (r *Repository) recalculateMovieRating(ctx context.Context, movieID int) error {
-	q := dbx.FromContext(ctx, r.db)
-	n, err := q.Exec(ctx, "UPDATE movies SET avg_rating = (SELECT avg(rating) FROM reviews WHERE deleted_at IS NULL and movie_id = $1) where id = $1", movieID)
-	if err != nil {
-		return apperrors.Internal(err)
+	if version != nil && review.Version != *version {
+		return apperrors.VersionMismatch("review", "id", reviewID, *version)
 	}
 
-	if n.RowsAffected() == 0 {
-		return apperrors.NotFound("movie", "id", movieID)
-	}
-	return nil
+	// If we got here, then something is wrong
+	return apperrors.Internal(fmt.Errorf("unexpected error creating/updating review with id %d", reviewID))
 }
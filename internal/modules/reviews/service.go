@@ -4,14 +4,19 @@ import (
 	"context"
 
 	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
 )
 
 type Service struct {
-	repo *Repository
+	repo       *Repository
+	moviesRepo *movies.Repository
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo *Repository, moviesRepo *movies.Repository) *Service {
+	return &Service{repo: repo, moviesRepo: moviesRepo}
 }
 
 func (s *Service) Create(ctx context.Context, review *Review) error {
@@ -23,28 +28,96 @@ func (s *Service) Create(ctx context.Context, review *Review) error {
 	return nil
 }
 
-func (s *Service) GetByID(ctx context.Context, reviewID int) (*Review, error) {
-	return s.repo.GetByID(ctx, reviewID)
+func (s *Service) GetByID(ctx context.Context, reviewID int, includes sparse.Includes) (*Review, error) {
+	review, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.include(ctx, []*Review{review}, includes); err != nil {
+		return nil, err
+	}
+	return review, nil
 }
 
-func (s *Service) GetPaginated(ctx context.Context, movieID, userID *int, offset int, limit int) ([]*Review, int, error) {
-	return s.repo.GetPaginated(ctx, movieID, userID, offset, limit)
+func (s *Service) GetPaginated(ctx context.Context, movieID, userID *int, sort *string, includes sparse.Includes, params *pagination.Params) (*pagination.Page[Review], error) {
+	page, err := s.repo.GetPaginated(ctx, movieID, userID, sort, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.include(ctx, page.Items, includes); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-func (s *Service) Update(ctx context.Context, reviewID, userID int, title, content string, rating int) error {
-	if err := s.repo.Update(ctx, reviewID, userID, title, content, rating); err != nil {
-		return err
+func (s *Service) GetAllByUserID(ctx context.Context, userID int, includes sparse.Includes) ([]*Review, error) {
+	reviews, err := s.repo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.include(ctx, reviews, includes); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (s *Service) GetFirstPagesByMovieIDs(ctx context.Context, movieIDs []int, sort *string, limit int) (map[int]*pagination.Page[Review], error) {
+	return s.repo.GetFirstPagesByMovieIDs(ctx, movieIDs, sort, limit)
+}
+
+func (s *Service) GetFirstPagesByUserIDs(ctx context.Context, userIDs []int, sort *string, limit int) (map[int]*pagination.Page[Review], error) {
+	return s.repo.GetFirstPagesByUserIDs(ctx, userIDs, sort, limit)
+}
+
+func (s *Service) Update(ctx context.Context, reviewID, userID int, title, content string, rating int, version *int) (int, error) {
+	newVersion, err := s.repo.Update(ctx, reviewID, userID, title, content, rating, version)
+	if err != nil {
+		return 0, err
 	}
 
 	log.FromContext(ctx).Info("review updated", "reviewId", reviewID)
-	return nil
+	return newVersion, nil
 }
 
-func (s *Service) Delete(ctx context.Context, reviewID, userID int) error {
-	if err := s.repo.Delete(ctx, reviewID, userID); err != nil {
+func (s *Service) Delete(ctx context.Context, reviewID, userID int, version *int) error {
+	if err := s.repo.Delete(ctx, reviewID, userID, version); err != nil {
 		return err
 	}
 
 	log.FromContext(ctx).Info("review deleted")
 	return nil
 }
+
+// include batch-loads the requested relations for all the reviews at once.
+func (s *Service) include(ctx context.Context, reviews []*Review, includes sparse.Includes) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	if includes.Has(IncludeMovie) {
+		ids := slices.Map(reviews, func(r *Review) int { return r.MovieID })
+		movies, err := s.moviesRepo.GetMoviesByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			review.Movie = movies[review.MovieID]
+		}
+	}
+
+	if includes.Has(IncludeUser) {
+		ids := slices.Map(reviews, func(r *Review) int { return r.UserID })
+		authors, err := s.repo.GetAuthorsByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			review.User = authors[review.UserID]
+		}
+	}
+
+	return nil
+}
@@ -7,9 +7,16 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/config"
 	"github.com/mkuptsov/movie-reviews/internal/echox"
 	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
+)
+
+const (
+	defaultSuggestionsLimit = 10
+	defaultDuplicatesLimit  = 20
 )
 
 type Handler struct {
@@ -57,12 +64,42 @@ func (h *Handler) GetStarByID(c echo.Context) error {
 		return err
 	}
 
-	star, err := h.Service.GetStarByID(c.Request().Context(), req.ID)
+	includes := sparse.ParseIncludes(req.Include, IncludeImage)
+	star, err := h.Service.GetStarByID(c.Request().Context(), req.ID, includes)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		targetID, mergedErr := h.Service.GetMergedInto(c.Request().Context(), req.ID)
+		if mergedErr != nil {
+			return mergedErr
+		}
+		if targetID != nil {
+			return echox.RedirectMoved(c, *targetID)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, star)
+	res, err := sparse.Project(star, sparse.Fields(req.Fields, includes))
+	if err != nil {
+		return err
+	}
+
+	// the awards and the images change without the version of the star
+	return echox.JSONOrNotModified(c, star.Version, res)
+}
+
+func (h *Handler) GetFilmography(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetStarFilmographyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	filmography, err := h.Service.GetFilmography(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, filmography)
 }
 
 func (h *Handler) GetAll(c echo.Context) error {
@@ -72,15 +109,18 @@ func (h *Handler) GetAll(c echo.Context) error {
 			return nil, err
 		}
 
-		pagination.SetDefaults(&req.PaginatedRequest, h.PaginationConfig)
-		offset, limit := pagination.OffsetLimit(&req.PaginatedRequest)
+		params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+		if err != nil {
+			return nil, err
+		}
 
-		stars, total, err := h.Service.GetAllPaginated(c.Request().Context(), req.MovieID, offset, limit)
+		includes := sparse.ParseIncludes(req.Include)
+		page, err := h.Service.GetAllPaginated(c.Request().Context(), req.MovieID, req.Sort, includes, params)
 		if err != nil {
 			return nil, err
 		}
 
-		return pagination.Response(&req.PaginatedRequest, total, stars), nil
+		return sparse.ProjectPage(pagination.Response(&req.PaginatedRequest, params, page), sparse.Fields(req.Fields, includes))
 	})
 	if err != nil {
 		return err
@@ -89,12 +129,72 @@ func (h *Handler) GetAll(c echo.Context) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+func (h *Handler) GetSuggestions(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetStarSuggestionsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestionsLimit
+	}
+
+	suggestions, err := h.Service.GetSuggestions(c.Request().Context(), req.Query, req.Limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, suggestions)
+}
+
 func (h *Handler) UpdateStar(c echo.Context) error {
 	req, err := echox.BindAndValidate[contracts.UpdateStarRequest](c)
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	return h.updateStar(c, req, version)
+}
+
+// PatchStar applies a merge patch to the star. Unless If-Match says otherwise, the patch is conditional
+// on the version it was applied to.
+func (h *Handler) PatchStar(c echo.Context) error {
+	var current *StarDetails
+	req, err := echox.BindMergePatch(c, func(req *contracts.UpdateStarRequest) error {
+		star, err := h.Service.GetStarByID(c.Request().Context(), req.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		current = star
+		req.FirstName = star.FirstName
+		req.MiddleName = star.MiddleName
+		req.LastName = star.LastName
+		req.BirthDate = star.BirthDate
+		req.BirthPlace = star.BirthPlace
+		req.DeathDate = star.DeathDate
+		req.Bio = star.Bio
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		version = &current.Version
+	}
+
+	return h.updateStar(c, req, version)
+}
 
+func (h *Handler) updateStar(c echo.Context, req *contracts.UpdateStarRequest, version *int) error {
 	star := &StarDetails{
 		Star: Star{
 			FirstName: req.FirstName,
@@ -106,26 +206,108 @@ func (h *Handler) UpdateStar(c echo.Context) error {
 		BirthPlace: req.BirthPlace,
 		Bio:        req.Bio,
 	}
-	id := req.ID
 
-	err = h.Service.UpdateStar(c.Request().Context(), id, star)
+	err := h.Service.UpdateStar(c.Request().Context(), req.ID, star, version)
 	if err != nil {
 		return err
 	}
 
+	if err = h.setETag(c, req.ID, star.Version); err != nil {
+		return err
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+// setETag sets the ETag of the star as GET returns it by default, unless the star has changed again since the update.
+func (h *Handler) setETag(c echo.Context, id, version int) error {
+	star, err := h.Service.GetStarByID(c.Request().Context(), id, sparse.ParseIncludes(nil, IncludeImage))
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if star.Version != version {
+		return nil
+	}
+	return echox.SetRepresentationETag(c, version, star)
+}
+
 func (h *Handler) DeleteStar(c echo.Context) error {
 	req, err := echox.BindAndValidate[contracts.DeleteStarRequest](c)
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	err = h.Service.DeleteStar(c.Request().Context(), req.ID)
+	err = h.Service.DeleteStar(c.Request().Context(), req.ID, version)
 	if err != nil {
 		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *Handler) GetCreditRoles(c echo.Context) error {
+	roles, err := h.Service.GetCreditRoles(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, roles)
+}
+
+func (h *Handler) CreateCreditRole(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateCreditRoleRequest](c)
+	if err != nil {
+		return err
+	}
+
+	role := &CreditRole{
+		Name:       req.Name,
+		Department: req.Department,
+		IsCast:     req.IsCast,
+	}
+
+	err = h.Service.CreateCreditRole(c.Request().Context(), role)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, role)
+}
+
+func (h *Handler) MergeStar(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.MergeStarRequest](c)
+	if err != nil {
+		return err
+	}
+
+	star, err := h.Service.Merge(c.Request().Context(), req.ID, req.TargetID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, star)
+}
+
+func (h *Handler) GetDuplicates(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetDuplicatesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultDuplicatesLimit
+	}
+
+	duplicates, err := h.Service.GetDuplicates(c.Request().Context(), req.Limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, duplicates)
+}
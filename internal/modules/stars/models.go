@@ -4,35 +4,114 @@ import (
 	"time"
 
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+)
+
+// Relations of a star which can be requested with the include parameter.
+const (
+	IncludeMovies = "movies"
+	IncludeImage  = "image"
+	IncludeImages = "images"
 )
 
 type Star struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate time.Time  `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID        int             `json:"id"`
+	FirstName string          `json:"first_name"`
+	LastName  string          `json:"last_name"`
+	BirthDate time.Time       `json:"birth_date,omitempty"`
+	DeathDate *time.Time      `json:"death_date,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	DeletedAt *time.Time      `json:"deleted_at,omitempty"`
+	Movies    []*StarCredit   `json:"movies,omitempty"`
+	Image     *images.Image   `json:"image,omitempty"`
+	Images    []*images.Image `json:"images,omitempty"`
 }
 
 type StarDetails struct {
 	Star
-	MiddleName *string `json:"middle_name,omitempty"`
-	BirthPlace *string `json:"birth_place,omitempty"`
-	Bio        *string `json:"bio,omitempty"`
+	MiddleName *string         `json:"middle_name,omitempty"`
+	BirthPlace *string         `json:"birth_place,omitempty"`
+	Bio        *string         `json:"bio,omitempty"`
+	Version    int             `json:"version"`
+	Awards     *awards.Summary `json:"awards,omitempty"`
+}
+
+// StarDuplicate is a pair of stars which are likely the same person. The duplicate is the newer one,
+// so it is the one to merge into the other.
+type StarDuplicate struct {
+	Star       *Star   `json:"star"`
+	Duplicate  *Star   `json:"duplicate"`
+	Similarity float64 `json:"similarity"`
+}
+
+type StarSuggestion struct {
+	ID         int       `json:"id"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	BirthDate  time.Time `json:"birth_date"`
+	Similarity float64   `json:"similarity"`
+}
+
+// Credit describes what a star did in a movie: characters for cast roles, job for crew ones.
+type Credit struct {
+	Role       string   `json:"role"`
+	Department string   `json:"department"`
+	Characters []string `json:"characters,omitempty"`
+	Job        *string  `json:"job,omitempty"`
+	Uncredited bool     `json:"uncredited,omitempty"`
+	Voice      bool     `json:"voice,omitempty"`
+}
+
+// creditColumns are the columns scanned by Credit.scanDest, movie_stars and credit_roles must be aliased as ms and cr.
+const creditColumns = "ms.role, cr.department, ms.characters, ms.job, ms.uncredited, ms.voice"
+
+func (c *Credit) scanDest() []any {
+	return []any{&c.Role, &c.Department, &c.Characters, &c.Job, &c.Uncredited, &c.Voice}
+}
+
+type CreditRole struct {
+	Name       string `json:"name"`
+	Department string `json:"department"`
+	IsCast     bool   `json:"is_cast"`
 }
 
 type MovieCredit struct {
-	Star    Star    `json:"star"`
-	Role    string  `json:"role"`
-	Details *string `json:"details"`
+	Star Star `json:"star"`
+	Credit
+	OrderNo int `json:"order_no"`
+}
+
+// StarCredit is a credit seen from the star side. Stars can't depend on movies, hence the short movie.
+type StarCredit struct {
+	Movie StarMovie `json:"movie"`
+	Credit
+}
+
+type StarMovie struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ReleaseDate time.Time `json:"release_date"`
+}
+
+// Filmography contains the credits of a star grouped by role.
+type Filmography struct {
+	StarID int                `json:"star_id"`
+	Total  int                `json:"total"`
+	Roles  []*FilmographyRole `json:"roles"`
+}
+
+type FilmographyRole struct {
+	Role       string               `json:"role"`
+	Department string               `json:"department"`
+	Count      int                  `json:"count"`
+	Credits    []*FilmographyCredit `json:"credits"`
 }
 
-type MovieCreditInfo struct {
-	StarID  int     `json:"star_id"`
-	Role    string  `json:"role"`
-	Details *string `json:"details"`
+type FilmographyCredit struct {
+	Movie StarMovie `json:"movie"`
+	Credit
+	OrderNo int `json:"order_no"`
 }
 
 var _ dbx.Keyer = MovieStarRelation{}
@@ -40,8 +119,7 @@ var _ dbx.Keyer = MovieStarRelation{}
 type MovieStarRelation struct {
 	MovieID int
 	StarID  int
-	Role    string
-	Details *string
+	Credit
 	OrderNo int
 }
 
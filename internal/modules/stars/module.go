@@ -3,6 +3,8 @@ package stars
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
 )
 
 type Module struct {
@@ -11,9 +13,9 @@ type Module struct {
 	Repository *Repository
 }
 
-func NewModule(db *pgxpool.Pool, paginationConfig config.PaginationConfig) *Module {
+func NewModule(db *pgxpool.Pool, imagesModule *images.Module, awardsModule *awards.Module, paginationConfig config.PaginationConfig) *Module {
 	repo := NewRepository(db)
-	service := NewService(repo)
+	service := NewService(repo, imagesModule.Service, awardsModule.Service)
 	handler := NewHandler(service, paginationConfig)
 
 	return &Module{
@@ -7,8 +7,25 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
 )
 
+// creditsExpr counts the credits of the star, deleted movies keep their credits for a restore.
+const creditsExpr = `(
+	SELECT count(*)
+	FROM movie_stars ms
+	INNER JOIN movies m ON m.id = ms.movie_id
+	WHERE ms.star_id = stars.id and m.deleted_at IS NULL)`
+
+var sortFields = dbx.SortFields{
+	"name":       {{Expr: "last_name", Type: dbx.KeyText}, {Expr: "first_name", Type: dbx.KeyText}},
+	"birth_date": {{Expr: "birth_date", Type: dbx.KeyDate}},
+	"created_at": {{Expr: "created_at", Type: dbx.KeyTimestamp}},
+	"credits":    {{Expr: creditsExpr, Type: dbx.KeyBigInt}},
+}
+
 type Repository struct {
 	db *pgxpool.Pool
 }
@@ -20,7 +37,8 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 }
 
 func (r *Repository) CreateStar(ctx context.Context, star *StarDetails) error {
-	queryString := `
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := `
 	INSERT INTO stars 
 	(first_name, middle_name, last_name, birth_date, birth_place, death_date, bio) 
 	VALUES 
@@ -28,30 +46,36 @@ func (r *Repository) CreateStar(ctx context.Context, star *StarDetails) error {
 	RETURNING
 	id, created_at, deleted_at
 	`
-	row := r.db.QueryRow(ctx, queryString,
-		star.FirstName,
-		star.MiddleName,
-		star.LastName,
-		star.BirthDate,
-		star.BirthPlace,
-		star.DeathDate,
-		star.Bio,
-	)
+		row := tx.QueryRow(ctx, queryString,
+			star.FirstName,
+			star.MiddleName,
+			star.LastName,
+			star.BirthDate,
+			star.BirthPlace,
+			star.DeathDate,
+			star.Bio,
+		)
 
-	err := row.Scan(
-		&star.ID,
-		&star.CreatedAt,
-		&star.DeletedAt,
-	)
+		err := row.Scan(
+			&star.ID,
+			&star.CreatedAt,
+			&star.DeletedAt,
+		)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return events.Record(ctx, tx, events.StarCreated, star.ID, &events.Star{ID: star.ID, FirstName: star.FirstName, LastName: star.LastName})
+	})
 	if err != nil {
-		return apperrors.Internal(err)
+		return apperrors.EnsureInternal(err)
 	}
 	return nil
 }
 
 func (r *Repository) GetStarByID(ctx context.Context, id int) (*StarDetails, error) {
 	queryString := `
-	SELECT id, first_name, middle_name, last_name, birth_date, birth_place, death_date, bio, created_at, deleted_at
+	SELECT id, first_name, middle_name, last_name, birth_date, birth_place, death_date, bio, created_at, deleted_at, version
 	FROM stars
 	WHERE id = $1 and deleted_at IS NULL;`
 
@@ -69,6 +93,7 @@ func (r *Repository) GetStarByID(ctx context.Context, id int) (*StarDetails, err
 		&star.Bio,
 		&star.CreatedAt,
 		&star.DeletedAt,
+		&star.Version,
 	)
 	if dbx.IsNoRows(err) {
 		return nil, apperrors.NotFound("star", "id", id)
@@ -80,14 +105,13 @@ func (r *Repository) GetStarByID(ctx context.Context, id int) (*StarDetails, err
 	return &star, nil
 }
 
-func (r *Repository) GetAllPaginated(ctx context.Context, movieID *int, offset, limit int) ([]*Star, int, error) {
+func (r *Repository) GetAllPaginated(ctx context.Context, movieID *int, sort *string, params *pagination.Params) (*pagination.Page[Star], error) {
 	queryPage := dbx.StatementBuilder.
 		Select("id, first_name, last_name, birth_date, death_date, created_at, deleted_at").
 		From("stars").
 		Where("deleted_at IS NULL").
-		OrderBy("id").
-		Limit(uint64(limit)).
-		Offset(uint64(offset))
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
 
 	queryTotal := dbx.StatementBuilder.
 		Select("count(*)").
@@ -96,24 +120,34 @@ func (r *Repository) GetAllPaginated(ctx context.Context, movieID *int, offset,
 
 	if movieID != nil {
 		queryPage = queryPage.
-			Join("movie_stars on stars.id = movie_stars.star_id").
-			Where("movie_stars.movie_id = ?", movieID)
+			Where("id IN (SELECT star_id FROM movie_stars WHERE movie_id = ?)", movieID)
 
 		queryTotal = queryTotal.
-			Join("movie_stars on stars.id = movie_stars.star_id").
-			Where("movie_stars.movie_id = ?", movieID)
+			Where("id IN (SELECT star_id FROM movie_stars WHERE movie_id = ?)", movieID)
 	}
 
-	b := &pgx.Batch{}
+	keyset := dbx.Keyset{{Expr: "id"}}
+	if sort != nil {
+		keyset = dbx.SortKeyset(*sort, sortFields, keyset...)
+	}
 
-	err := dbx.QueueBatchSelect(b, queryPage)
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	err = dbx.QueueBatchSelect(b, queryTotal)
+	b := &pgx.Batch{}
+
+	err = dbx.QueueBatchSelect(b, queryPage)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, queryTotal)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	br := r.db.SendBatch(ctx, b)
@@ -121,13 +155,15 @@ func (r *Repository) GetAllPaginated(ctx context.Context, movieID *int, offset,
 
 	rows, err := br.Query()
 	if err != nil {
-		return nil, 0, apperrors.Internal(err)
+		return nil, apperrors.Internal(err)
 	}
 
 	var stars []*Star
+	var keys [][]string
 	for rows.Next() {
 		var star Star
-		err = rows.Scan(
+		key := keyset.NewKey()
+		err = rows.Scan(append([]any{
 			&star.ID,
 			&star.FirstName,
 			&star.LastName,
@@ -135,25 +171,72 @@ func (r *Repository) GetAllPaginated(ctx context.Context, movieID *int, offset,
 			&star.DeathDate,
 			&star.CreatedAt,
 			&star.DeletedAt,
-		)
+		}, keyset.ScanDest(key)...)...)
 		if err != nil {
-			return nil, 0, apperrors.Internal(err)
+			return nil, apperrors.Internal(err)
 		}
 
 		stars = append(stars, &star)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
 	}
 
-	var total int
-	err = br.QueryRow().Scan(&total)
-	if err != nil {
-		return nil, 0, apperrors.Internal(err)
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
 	}
 
-	return stars, total, nil
+	return pagination.NewPage(params, stars, keys, total), nil
 }
 
-func (r *Repository) UpdateStar(ctx context.Context, id int, star *StarDetails) error {
+func (r *Repository) GetSuggestions(ctx context.Context, query string, limit int) ([]*StarSuggestion, error) {
 	queryString := `
+	SELECT id, first_name, last_name, birth_date,
+		greatest(similarity(first_name || ' ' || last_name, $1), word_similarity($1, first_name || ' ' || last_name)) AS score
+	FROM stars
+	WHERE deleted_at IS NULL and ((first_name || ' ' || last_name) % $1 or $1 <% (first_name || ' ' || last_name))
+	ORDER BY score DESC, id
+	LIMIT $2`
+
+	rows, err := r.db.Query(ctx, queryString, query, limit)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	suggestions := []*StarSuggestion{}
+	for rows.Next() {
+		var suggestion StarSuggestion
+		err = rows.Scan(
+			&suggestion.ID,
+			&suggestion.FirstName,
+			&suggestion.LastName,
+			&suggestion.BirthDate,
+			&suggestion.Similarity,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return suggestions, nil
+}
+
+// UpdateStar updates the star if it still has the given version, any version will do when it is nil.
+// The new version is set to the star.
+func (r *Repository) UpdateStar(ctx context.Context, id int, star *StarDetails, version *int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := `
 	UPDATE stars 
 	SET 
 		first_name = $2,
@@ -162,60 +245,236 @@ func (r *Repository) UpdateStar(ctx context.Context, id int, star *StarDetails)
 		birth_date = $5,
 		birth_place = $6,
 		death_date = $7,
-		bio = $8
-		
-	WHERE id = $1`
-
-	cmdTag, err := r.db.Exec(ctx, queryString,
-		id,
-		star.FirstName,
-		star.MiddleName,
-		star.LastName,
-		star.BirthDate,
-		star.BirthPlace,
-		star.DeathDate,
-		star.Bio,
-	)
+		bio = $8,
+		version = version + 1
+	WHERE id = $1 and deleted_at IS NULL and ($9::int IS NULL or version = $9)
+	RETURNING version`
+
+		err := tx.QueryRow(ctx, queryString,
+			id,
+			star.FirstName,
+			star.MiddleName,
+			star.LastName,
+			star.BirthDate,
+			star.BirthPlace,
+			star.DeathDate,
+			star.Bio,
+			version,
+		).Scan(&star.Version)
+		if dbx.IsNoRows(err) {
+			return r.modificationError(ctx, id, version)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return events.Record(ctx, tx, events.StarUpdated, id, &events.Star{ID: id, FirstName: star.FirstName, LastName: star.LastName})
+	})
 	if err != nil {
-		return apperrors.Internal(err)
+		return apperrors.EnsureInternal(err)
 	}
+	return nil
+}
 
-	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("star", "id", id)
-	}
+func (r *Repository) DeleteStar(ctx context.Context, id int, version *int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "UPDATE stars SET deleted_at = NOW() WHERE id = $1 and deleted_at IS NULL and ($2::int IS NULL or version = $2);"
+		cmdTag, err := tx.Exec(ctx, queryString, id, version)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return r.modificationError(ctx, id, version)
+		}
 
+		return events.Record(ctx, tx, events.StarDeleted, id, &events.Star{ID: id})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
 	return nil
 }
 
-func (r *Repository) DeleteStar(ctx context.Context, id int) error {
-	queryString := "UPDATE stars SET deleted_at = NOW() WHERE id = $1 and deleted_at IS NULL;"
-	cmdTag, err := r.db.Exec(ctx, queryString, id)
+// modificationError tells whether the star wasn't modified because it doesn't exist or because of a stale version.
+func (r *Repository) modificationError(ctx context.Context, id int, version *int) error {
+	_, err := r.GetStarByID(ctx, id)
 	if err != nil {
-		return apperrors.Internal(err)
+		return err
 	}
 
-	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("star", "id", id)
+	return apperrors.VersionMismatch("star", "id", id, *version)
+}
+
+// Merge moves the credits, nominations and images of the star to the target one, fills in the details
+// the target lacks and deletes the star leaving a redirect to the target. The movies whose cast changes
+// are recorded as updated.
+func (r *Repository) Merge(ctx context.Context, id, targetID int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, "SELECT id FROM stars WHERE id = ANY($1) and deleted_at IS NULL FOR UPDATE", []int{id, targetID})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		locked, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		for _, starID := range []int{id, targetID} {
+			if !slices.Contains(locked, starID) {
+				return apperrors.NotFound("star", "id", starID)
+			}
+		}
+
+		rows, err = tx.Query(ctx, "SELECT DISTINCT movie_id FROM movie_stars WHERE star_id = $1", id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		movieIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		statements := []string{
+			// Credits and nominations the target already has are dropped
+			`DELETE FROM movie_stars s USING movie_stars t
+			WHERE s.star_id = $1 and t.star_id = $2 and t.movie_id = s.movie_id and t.role = s.role`,
+			"UPDATE movie_stars SET star_id = $2 WHERE star_id = $1",
+			`DELETE FROM award_nominations s USING award_nominations t
+			WHERE s.star_id = $1 and t.star_id = $2
+				and t.ceremony_id = s.ceremony_id and t.category_id = s.category_id and t.movie_id = s.movie_id`,
+			"UPDATE award_nominations SET star_id = $2 WHERE star_id = $1",
+			"UPDATE images SET is_primary = FALSE WHERE star_id = $1 and EXISTS (SELECT 1 FROM images WHERE star_id = $2 and is_primary)",
+			"UPDATE images SET star_id = $2 WHERE star_id = $1",
+			`DELETE FROM star_external_ids s USING star_external_ids t
+			WHERE s.star_id = $1 and t.star_id = $2 and t.source = s.source`,
+			"UPDATE star_external_ids SET star_id = $2 WHERE star_id = $1",
+			`UPDATE stars t
+			SET middle_name = coalesce(t.middle_name, s.middle_name),
+				birth_place = coalesce(t.birth_place, s.birth_place),
+				death_date = coalesce(t.death_date, s.death_date),
+				bio = coalesce(t.bio, s.bio),
+				version = t.version + 1
+			FROM stars s
+			WHERE s.id = $1 and t.id = $2`,
+			"UPDATE stars SET merged_into = $2 WHERE merged_into = $1",
+			"UPDATE stars SET deleted_at = NOW(), merged_into = $2 WHERE id = $1",
+		}
+		for _, statement := range statements {
+			_, err = tx.Exec(ctx, statement, id, targetID)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		for _, movieID := range movieIDs {
+			if err = events.Record(ctx, tx, events.MovieUpdated, movieID, &events.Movie{ID: movieID}); err != nil {
+				return err
+			}
+		}
+		err = events.Record(ctx, tx, events.StarDeleted, id, &events.Star{ID: id, MergedInto: &targetID})
+		if err != nil {
+			return err
+		}
+		return events.Record(ctx, tx, events.StarUpdated, targetID, &events.Star{ID: targetID})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
 	}
 
 	return nil
 }
 
+// GetMergedInto returns the star the deleted one was merged into, or nil if it wasn't merged.
+func (r *Repository) GetMergedInto(ctx context.Context, id int) (*int, error) {
+	var targetID int
+	err := r.db.QueryRow(ctx, "SELECT merged_into FROM stars WHERE id = $1 and merged_into IS NOT NULL", id).Scan(&targetID)
+	if dbx.IsNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return &targetID, nil
+}
+
+// GetDuplicates finds pairs of stars with similar names and the same birth date, or with the same name.
+func (r *Repository) GetDuplicates(ctx context.Context, limit int) ([]*StarDuplicate, error) {
+	queryString := `
+	SELECT a.id, a.first_name, a.last_name, a.birth_date, a.death_date, a.created_at,
+		b.id, b.first_name, b.last_name, b.birth_date, b.death_date, b.created_at,
+		similarity(a.first_name || ' ' || a.last_name, b.first_name || ' ' || b.last_name) AS score
+	FROM stars a
+	INNER JOIN stars b on a.id < b.id and (a.first_name || ' ' || a.last_name) % (b.first_name || ' ' || b.last_name)
+	WHERE a.deleted_at IS NULL and b.deleted_at IS NULL
+		and (a.birth_date = b.birth_date or lower(a.first_name || ' ' || a.last_name) = lower(b.first_name || ' ' || b.last_name))
+	ORDER BY score DESC, a.id, b.id
+	LIMIT $1`
+
+	rows, err := r.db.Query(ctx, queryString, limit)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	duplicates := []*StarDuplicate{}
+	for rows.Next() {
+		duplicate := StarDuplicate{Star: &Star{}, Duplicate: &Star{}}
+		err = rows.Scan(
+			&duplicate.Star.ID,
+			&duplicate.Star.FirstName,
+			&duplicate.Star.LastName,
+			&duplicate.Star.BirthDate,
+			&duplicate.Star.DeathDate,
+			&duplicate.Star.CreatedAt,
+			&duplicate.Duplicate.ID,
+			&duplicate.Duplicate.FirstName,
+			&duplicate.Duplicate.LastName,
+			&duplicate.Duplicate.BirthDate,
+			&duplicate.Duplicate.DeathDate,
+			&duplicate.Duplicate.CreatedAt,
+			&duplicate.Similarity,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		duplicates = append(duplicates, &duplicate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return duplicates, nil
+}
+
 func (r *Repository) GetCastsByMovieID(ctx context.Context, id int) ([]*MovieCredit, error) {
+	cast, err := r.GetCastByMovieIDs(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	return cast[id], nil
+}
+
+func (r *Repository) GetCastByMovieIDs(ctx context.Context, ids []int) (map[int][]*MovieCredit, error) {
 	queryString := `
-	SELECT s.id, s.first_name, s.last_name, s.birth_date, s.death_date, s.created_at, s.deleted_at, ms.role, ms.details
+	SELECT ms.movie_id, s.id, s.first_name, s.last_name, s.birth_date, s.death_date, s.created_at, s.deleted_at, ` + creditColumns + `, ms.order_no
 	FROM stars s
 	INNER JOIN movie_stars ms ON ms.star_id = s.id
-	WHERE ms.movie_id = $1
-	ORDER BY ms.order_no`
-	rows, err := r.db.Query(ctx, queryString, id)
+	INNER JOIN credit_roles cr ON cr.name = ms.role
+	WHERE ms.movie_id = ANY($1) and s.deleted_at IS NULL
+	ORDER BY ms.movie_id, ms.order_no`
+	rows, err := r.db.Query(ctx, queryString, ids)
 	if err != nil {
 		return nil, apperrors.Internal(err)
 	}
-	var cast []*MovieCredit
+	defer rows.Close()
+
+	cast := make(map[int][]*MovieCredit, len(ids))
 	for rows.Next() {
+		var movieID int
 		var mc MovieCredit
-		err = rows.Scan(
+		dest := []any{
+			&movieID,
 			&mc.Star.ID,
 			&mc.Star.FirstName,
 			&mc.Star.LastName,
@@ -223,20 +482,98 @@ func (r *Repository) GetCastsByMovieID(ctx context.Context, id int) ([]*MovieCre
 			&mc.Star.DeathDate,
 			&mc.Star.CreatedAt,
 			&mc.Star.DeletedAt,
-			&mc.Role,
-			&mc.Details,
-		)
+		}
+		dest = append(dest, mc.Credit.scanDest()...)
+		err = rows.Scan(append(dest, &mc.OrderNo)...)
 		if err != nil {
 			return nil, apperrors.Internal(err)
 		}
-		cast = append(cast, &mc)
+		cast[movieID] = append(cast[movieID], &mc)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
 	}
+
 	return cast, nil
 }
 
+func (r *Repository) GetCreditsByStarIDs(ctx context.Context, ids []int) (map[int][]*StarCredit, error) {
+	queryString := `
+	SELECT ms.star_id, m.id, m.title, m.release_date, ` + creditColumns + `
+	FROM movies m
+	INNER JOIN movie_stars ms ON ms.movie_id = m.id
+	INNER JOIN credit_roles cr ON cr.name = ms.role
+	WHERE ms.star_id = ANY($1) and m.deleted_at IS NULL
+	ORDER BY ms.star_id, m.release_date, m.id`
+	rows, err := r.db.Query(ctx, queryString, ids)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	credits := make(map[int][]*StarCredit, len(ids))
+	for rows.Next() {
+		var starID int
+		var sc StarCredit
+		dest := []any{
+			&starID,
+			&sc.Movie.ID,
+			&sc.Movie.Title,
+			&sc.Movie.ReleaseDate,
+		}
+		err = rows.Scan(append(dest, sc.Credit.scanDest()...)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		credits[starID] = append(credits[starID], &sc)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return credits, nil
+}
+
+// GetFilmography returns credits of the star ordered by role, cast first, and then by release date, newest first.
+func (r *Repository) GetFilmography(ctx context.Context, starID int) ([]*FilmographyCredit, error) {
+	queryString := `
+	SELECT m.id, m.title, m.release_date, ` + creditColumns + `, ms.order_no
+	FROM movies m
+	INNER JOIN movie_stars ms ON ms.movie_id = m.id
+	INNER JOIN credit_roles cr ON cr.name = ms.role
+	WHERE ms.star_id = $1 and m.deleted_at IS NULL
+	ORDER BY cr.is_cast DESC, cr.department, ms.role, m.release_date DESC, m.id`
+	rows, err := r.db.Query(ctx, queryString, starID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var credits []*FilmographyCredit
+	for rows.Next() {
+		var credit FilmographyCredit
+		dest := []any{
+			&credit.Movie.ID,
+			&credit.Movie.Title,
+			&credit.Movie.ReleaseDate,
+		}
+		dest = append(dest, credit.Credit.scanDest()...)
+		err = rows.Scan(append(dest, &credit.OrderNo)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		credits = append(credits, &credit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return credits, nil
+}
+
 func (r *Repository) GetRelationsByMovieID(ctx context.Context, id int) ([]*MovieStarRelation, error) {
 	queryString := `
-	SELECT movie_id, star_id, role, details, order_no
+	SELECT movie_id, star_id, role, characters, job, uncredited, voice, order_no
 	FROM movie_stars
 	WHERE movie_id = $1`
 	q := dbx.FromContext(ctx, r.db)
@@ -251,7 +588,10 @@ func (r *Repository) GetRelationsByMovieID(ctx context.Context, id int) ([]*Movi
 			&r.MovieID,
 			&r.StarID,
 			&r.Role,
-			&r.Details,
+			&r.Characters,
+			&r.Job,
+			&r.Uncredited,
+			&r.Voice,
 			&r.OrderNo,
 		)
 		if err != nil {
@@ -262,3 +602,43 @@ func (r *Repository) GetRelationsByMovieID(ctx context.Context, id int) ([]*Movi
 	}
 	return relations, nil
 }
+
+func (r *Repository) GetCreditRoles(ctx context.Context) ([]*CreditRole, error) {
+	rows, err := r.db.Query(ctx, "SELECT name, department, is_cast FROM credit_roles ORDER BY is_cast DESC, department, name")
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var roles []*CreditRole
+	for rows.Next() {
+		var role CreditRole
+		err = rows.Scan(
+			&role.Name,
+			&role.Department,
+			&role.IsCast,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		roles = append(roles, &role)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return roles, nil
+}
+
+func (r *Repository) CreateCreditRole(ctx context.Context, role *CreditRole) error {
+	_, err := r.db.Exec(ctx,
+		"INSERT INTO credit_roles (name, department, is_cast) VALUES ($1, $2, $3)",
+		role.Name, role.Department, role.IsCast)
+	if dbx.IsUniqueViolation(err, "") {
+		return apperrors.AlreadyExists("credit role", "name", role.Name)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
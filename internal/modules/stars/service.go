@@ -2,17 +2,28 @@ package stars
 
 import (
 	"context"
+	"errors"
 
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
 )
 
 type Service struct {
-	repo *Repository
+	repo          *Repository
+	imagesService *images.Service
+	awardsService *awards.Service
 }
 
-func NewService(repo *Repository) *Service {
+func NewService(repo *Repository, imagesService *images.Service, awardsService *awards.Service) *Service {
 	return &Service{
-		repo: repo,
+		repo:          repo,
+		imagesService: imagesService,
+		awardsService: awardsService,
 	}
 }
 
@@ -30,16 +41,73 @@ func (s *Service) CreateStar(ctx context.Context, star *StarDetails) error {
 	return nil
 }
 
-func (s *Service) GetStarByID(ctx context.Context, id int) (*StarDetails, error) {
-	return s.repo.GetStarByID(ctx, id)
+func (s *Service) GetStarByID(ctx context.Context, id int, includes sparse.Includes) (*StarDetails, error) {
+	star, err := s.repo.GetStarByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.include(ctx, []*Star{&star.Star}, includes)
+	if err != nil {
+		return nil, err
+	}
+
+	star.Awards, err = s.awardsService.GetSummaryByStarID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return star, nil
+}
+
+func (s *Service) GetFilmography(ctx context.Context, starID int) (*Filmography, error) {
+	_, err := s.repo.GetStarByID(ctx, starID)
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := s.repo.GetFilmography(ctx, starID)
+	if err != nil {
+		return nil, err
+	}
+
+	filmography := &Filmography{
+		StarID: starID,
+		Total:  len(credits),
+		Roles:  []*FilmographyRole{},
+	}
+	// Credits come ordered by role, so every group is a contiguous run
+	var group *FilmographyRole
+	for _, credit := range credits {
+		if group == nil || group.Role != credit.Role {
+			group = &FilmographyRole{Role: credit.Role, Department: credit.Department}
+			filmography.Roles = append(filmography.Roles, group)
+		}
+		group.Credits = append(group.Credits, credit)
+		group.Count++
+	}
+
+	return filmography, nil
+}
+
+func (s *Service) GetAllPaginated(ctx context.Context, movieID *int, sort *string, includes sparse.Includes, params *pagination.Params) (*pagination.Page[Star], error) {
+	page, err := s.repo.GetAllPaginated(ctx, movieID, sort, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.include(ctx, page.Items, includes)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-func (s *Service) GetAllPaginated(ctx context.Context, movieID *int, offset, limit int) ([]*Star, int, error) {
-	return s.repo.GetAllPaginated(ctx, movieID, offset, limit)
+func (s *Service) GetSuggestions(ctx context.Context, query string, limit int) ([]*StarSuggestion, error) {
+	return s.repo.GetSuggestions(ctx, query, limit)
 }
 
-func (s *Service) UpdateStar(ctx context.Context, id int, star *StarDetails) error {
-	err := s.repo.UpdateStar(ctx, id, star)
+func (s *Service) UpdateStar(ctx context.Context, id int, star *StarDetails, version *int) error {
+	err := s.repo.UpdateStar(ctx, id, star, version)
 	if err != nil {
 		return err
 	}
@@ -52,8 +120,8 @@ func (s *Service) UpdateStar(ctx context.Context, id int, star *StarDetails) err
 	return nil
 }
 
-func (s *Service) DeleteStar(ctx context.Context, id int) error {
-	err := s.repo.DeleteStar(ctx, id)
+func (s *Service) DeleteStar(ctx context.Context, id int, version *int) error {
+	err := s.repo.DeleteStar(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -66,6 +134,91 @@ func (s *Service) DeleteStar(ctx context.Context, id int) error {
 	return nil
 }
 
+func (s *Service) Merge(ctx context.Context, id, targetID int) (*StarDetails, error) {
+	if id == targetID {
+		return nil, apperrors.BadRequest(errors.New("star can't be merged into itself"))
+	}
+
+	err := s.repo.Merge(ctx, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("stars merged",
+		"star_id", id,
+		"target_id", targetID)
+
+	return s.repo.GetStarByID(ctx, targetID)
+}
+
+func (s *Service) GetMergedInto(ctx context.Context, id int) (*int, error) {
+	return s.repo.GetMergedInto(ctx, id)
+}
+
+func (s *Service) GetDuplicates(ctx context.Context, limit int) ([]*StarDuplicate, error) {
+	return s.repo.GetDuplicates(ctx, limit)
+}
+
+func (s *Service) GetCreditRoles(ctx context.Context) ([]*CreditRole, error) {
+	return s.repo.GetCreditRoles(ctx)
+}
+
+func (s *Service) CreateCreditRole(ctx context.Context, role *CreditRole) error {
+	err := s.repo.CreateCreditRole(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("credit role created",
+		"name", role.Name,
+		"department", role.Department)
+
+	return nil
+}
+
+func (s *Service) GetCastByMovieIDs(ctx context.Context, ids []int) (map[int][]*MovieCredit, error) {
+	return s.repo.GetCastByMovieIDs(ctx, ids)
+}
+
 func (s *Service) GetCastByMovieID(ctx context.Context, id int) ([]*MovieCredit, error) {
 	return s.repo.GetCastsByMovieID(ctx, id)
 }
+
+// include batch-loads the requested relations for all the stars at once.
+func (s *Service) include(ctx context.Context, stars []*Star, includes sparse.Includes) error {
+	if len(stars) == 0 {
+		return nil
+	}
+
+	ids := slices.Map(stars, func(s *Star) int { return s.ID })
+
+	if includes.Has(IncludeMovies) {
+		credits, err := s.repo.GetCreditsByStarIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, star := range stars {
+			star.Movies = credits[star.ID]
+		}
+	}
+
+	if includes.Has(IncludeImage) || includes.Has(IncludeImages) {
+		starImages, err := s.imagesService.GetByStarIDs(ctx, ids, !includes.Has(IncludeImages))
+		if err != nil {
+			return err
+		}
+		for _, star := range stars {
+			gallery := starImages[star.ID]
+			if includes.Has(IncludeImage) && len(gallery) > 0 && gallery[0].Primary {
+				star.Image = gallery[0]
+			}
+			if includes.Has(IncludeImages) {
+				star.Images = gallery
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package streams
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/live"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+)
+
+const MIMETextEventStream = "text/event-stream"
+
+type Handler struct {
+	Service   *Service
+	Heartbeat time.Duration
+}
+
+func NewHandler(service *Service, heartbeat time.Duration) *Handler {
+	return &Handler{
+		Service:   service,
+		Heartbeat: heartbeat,
+	}
+}
+
+func (h *Handler) MovieUpdates(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.StreamMovieUpdatesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	sub, targetID, err := h.Service.SubscribeMovie(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+	if targetID != nil {
+		return echox.RedirectMoved(c, *targetID)
+	}
+
+	return h.stream(c, sub)
+}
+
+func (h *Handler) AllUpdates(c echo.Context) error {
+	return h.stream(c, h.Service.SubscribeAll())
+}
+
+// stream writes the updates of the subscription as server-sent events named by their types, until the client
+// goes away or the subscription is closed. Heartbeat comments keep the connection alive while there are no updates.
+func (h *Handler) stream(c echo.Context, sub *live.Subscription) error {
+	defer h.Service.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ctx := c.Request().Context()
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			err = writeEvent(res, update)
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(res, ": heartbeat\n\n")
+		}
+		if err != nil {
+			log.FromContext(ctx).Debug("live stream closed", "err", err)
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+func writeEvent(res *echo.Response, update *live.Update) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", update.Type, data)
+	return err
+}
@@ -0,0 +1,22 @@
+package streams
+
+import (
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/live"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+)
+
+type Module struct {
+	Handler *Handler
+	Service *Service
+}
+
+func NewModule(broker *live.Broker, moviesModule *movies.Module, cfg config.LiveConfig) *Module {
+	service := NewService(broker, moviesModule.Repository)
+	handler := NewHandler(service, cfg.Heartbeat)
+
+	return &Module{
+		Handler: handler,
+		Service: service,
+	}
+}
@@ -0,0 +1,50 @@
+package streams
+
+import (
+	"context"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/live"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+)
+
+type Service struct {
+	broker     *live.Broker
+	moviesRepo *movies.Repository
+}
+
+func NewService(broker *live.Broker, moviesRepo *movies.Repository) *Service {
+	return &Service{
+		broker:     broker,
+		moviesRepo: moviesRepo,
+	}
+}
+
+// SubscribeMovie subscribes to the updates of the movie. If the movie has been merged into another one,
+// the ID of the latter is returned instead of a subscription.
+func (s *Service) SubscribeMovie(ctx context.Context, id int) (*live.Subscription, *int, error) {
+	_, err := s.moviesRepo.GetMovieByID(ctx, id)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		targetID, mergedErr := s.moviesRepo.GetMergedInto(ctx, id)
+		if mergedErr != nil {
+			return nil, nil, mergedErr
+		}
+		if targetID != nil {
+			return nil, targetID, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.broker.Subscribe(&id), nil, nil
+}
+
+// SubscribeAll subscribes to the updates of all the movies.
+func (s *Service) SubscribeAll() *live.Subscription {
+	return s.broker.Subscribe(nil)
+}
+
+func (s *Service) Unsubscribe(sub *live.Subscription) {
+	s.broker.Unsubscribe(sub)
+}
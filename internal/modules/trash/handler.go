@@ -0,0 +1,56 @@
+package trash
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Handler struct {
+	Service          *Service
+	PaginationConfig config.PaginationConfig
+}
+
+func NewHandler(service *Service, cfg config.PaginationConfig) *Handler {
+	return &Handler{
+		Service:          service,
+		PaginationConfig: cfg,
+	}
+}
+
+func (h *Handler) GetAll(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetTrashRequest](c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Service.GetAllPaginated(c.Request().Context(), req.Kind, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, pagination.Response(&req.PaginatedRequest, params, page))
+}
+
+func (h *Handler) Restore(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.RestoreRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Restore(c.Request().Context(), req.Kind, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,55 @@
+package trash
+
+import (
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+)
+
+// Kinds of soft-deleted entities that can be browsed and restored.
+const (
+	KindMovies  = "movies"
+	KindStars   = "stars"
+	KindUsers   = "users"
+	KindReviews = "reviews"
+)
+
+// PurgeJobKind is the kind of the job purging the trash.
+const PurgeJobKind = "trash.purge"
+
+// Item is a soft-deleted entity, which is purged for good at PurgeAt unless it is restored.
+type Item struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAt    time.Time `json:"purge_at"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+}
+
+// Purged holds the IDs of the entities removed by a purge.
+type Purged struct {
+	MovieIDs  []int
+	StarIDs   []int
+	UserIDs   []int
+	ReviewIDs []int
+	// Images of the purged movies and stars, their content is left in the storage
+	Images []*images.Image
+}
+
+func (p *Purged) Empty() bool {
+	return len(p.MovieIDs) == 0 && len(p.StarIDs) == 0 && len(p.UserIDs) == 0 && len(p.ReviewIDs) == 0
+}
+
+type kind struct {
+	subject    string
+	table      string
+	name       string
+	mergedInto string
+}
+
+var kinds = map[string]kind{
+	KindMovies:  {subject: "movie", table: "movies", name: "title", mergedInto: "merged_into"},
+	KindStars:   {subject: "star", table: "stars", name: "first_name || ' ' || last_name", mergedInto: "merged_into"},
+	KindUsers:   {subject: "user", table: "users", name: "username", mergedInto: "NULL::int"},
+	KindReviews: {subject: "review", table: "reviews", name: "coalesce(title, '')", mergedInto: "NULL::int"},
+}
@@ -0,0 +1,26 @@
+package trash
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+)
+
+type Module struct {
+	Handler    *Handler
+	Service    *Service
+	Repository *Repository
+}
+
+func NewModule(db *pgxpool.Pool, moviesModule *movies.Module, imagesModule *images.Module, cfg config.TrashConfig, paginationConfig config.PaginationConfig) *Module {
+	repo := NewRepository(db, moviesModule.Repository, imagesModule.Repository)
+	service := NewService(repo, imagesModule.Service, cfg.Retention)
+	handler := NewHandler(service, paginationConfig)
+
+	return &Module{
+		Handler:    handler,
+		Service:    service,
+		Repository: repo,
+	}
+}
@@ -0,0 +1,414 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Repository struct {
+	db         *pgxpool.Pool
+	moviesRepo *movies.Repository
+	imagesRepo *images.Repository
+}
+
+func NewRepository(db *pgxpool.Pool, moviesRepo *movies.Repository, imagesRepo *images.Repository) *Repository {
+	return &Repository{
+		db:         db,
+		moviesRepo: moviesRepo,
+		imagesRepo: imagesRepo,
+	}
+}
+
+// GetAllPaginated returns the deleted entities of the kind, the most recently deleted first.
+func (r *Repository) GetAllPaginated(ctx context.Context, kindName string, params *pagination.Params) (*pagination.Page[Item], error) {
+	k := kinds[kindName]
+	queryPage := dbx.StatementBuilder.
+		Select("id", k.name, "deleted_at", k.mergedInto).
+		From(k.table).
+		Where("deleted_at IS NOT NULL").
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
+
+	keyset := dbx.Keyset{{Expr: "deleted_at", Type: dbx.KeyTimestamp, Desc: true}, {Expr: "id", Desc: true}}
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &pgx.Batch{}
+
+	err = dbx.QueueBatchSelect(b, queryPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, dbx.StatementBuilder.Select("count(*)").From(k.table).Where("deleted_at IS NOT NULL"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	br := r.db.SendBatch(ctx, b)
+	defer br.Close()
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var items []*Item
+	var keys [][]string
+	for rows.Next() {
+		var item Item
+		key := keyset.NewKey()
+		err = rows.Scan(append([]any{
+			&item.ID,
+			&item.Name,
+			&item.DeletedAt,
+			&item.MergedInto,
+		}, keyset.ScanDest(key)...)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		items = append(items, &item)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, items, keys, total), nil
+}
+
+// RestoreMovie restores the movie together with its seasons and episodes deleted along with it.
+// Nested titles can't be restored while their parent is deleted.
+func (r *Repository) RestoreMovie(ctx context.Context, id int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		if err := r.lockDeleted(ctx, tx, KindMovies, id); err != nil {
+			return err
+		}
+
+		var parentDeleted bool
+		err := tx.QueryRow(ctx,
+			"SELECT coalesce((SELECT p.deleted_at IS NOT NULL FROM movies p WHERE p.id = m.parent_id), false) FROM movies m WHERE m.id = $1",
+			id).Scan(&parentDeleted)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if parentDeleted {
+			return apperrors.BadRequest(errors.New("parent title must be restored first"))
+		}
+
+		queryString := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at FROM movies WHERE id = $1
+			UNION ALL
+			SELECT m.id, m.deleted_at FROM movies m JOIN subtree s ON m.parent_id = s.id and m.deleted_at = s.deleted_at
+		)
+		UPDATE movies SET deleted_at = NULL, version = version + 1 WHERE id IN (SELECT id FROM subtree)
+		RETURNING id, title, kind, parent_id`
+		rows, err := tx.Query(ctx, queryString, id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		restored, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*events.Movie, error) {
+			var movie events.Movie
+			err := row.Scan(&movie.ID, &movie.Title, &movie.Kind, &movie.ParentID)
+			return &movie, err
+		})
+		if dbx.IsUniqueViolation(err, "parent_id_number") {
+			return apperrors.BadRequest(errors.New("another title with the same number has been added to the parent"))
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		for _, movie := range restored {
+			if err = events.Record(ctx, tx, events.MovieRestored, movie.ID, movie); err != nil {
+				return err
+			}
+		}
+		return r.moviesRepo.RecalculateRating(ctx, id)
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+	return nil
+}
+
+func (r *Repository) RestoreStar(ctx context.Context, id int) error {
+	return r.restore(ctx, KindStars, id, events.StarRestored, &events.Star{ID: id})
+}
+
+func (r *Repository) RestoreUser(ctx context.Context, id int) error {
+	return r.restore(ctx, KindUsers, id, events.UserRestored, &events.User{ID: id})
+}
+
+// RestoreReview restores the review and puts its rating back into the one of the movie.
+func (r *Repository) RestoreReview(ctx context.Context, id int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		review := events.Review{ID: id}
+		var userDeleted bool
+		err := tx.QueryRow(ctx, `
+		SELECT r.movie_id, r.user_id, r.rating, u.deleted_at IS NOT NULL
+		FROM reviews r
+		INNER JOIN users u ON u.id = r.user_id
+		WHERE r.id = $1`, id).Scan(&review.MovieID, &review.UserID, &review.Rating, &userDeleted)
+		if dbx.IsNoRows(err) {
+			return apperrors.NotFound("review", "id", id)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if userDeleted {
+			return apperrors.BadRequest(errors.New("author of the review must be restored first"))
+		}
+
+		err = r.moviesRepo.Lock(ctx, tx, review.MovieID)
+		if apperrors.Is(err, apperrors.NotFoundCode) {
+			return apperrors.BadRequest(errors.New("movie of the review must be restored first"))
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = r.lockDeleted(ctx, tx, KindReviews, id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, "UPDATE reviews SET deleted_at = NULL, version = version + 1 WHERE id = $1", id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = events.Record(ctx, tx, events.ReviewRestored, id, &review); err != nil {
+			return err
+		}
+		return r.moviesRepo.RecalculateRating(ctx, review.MovieID)
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+	return nil
+}
+
+// GetExpired returns the entities deleted longer than the retention ago.
+// Seasons and episodes expire along with their series.
+func (r *Repository) GetExpired(ctx context.Context, retention time.Duration) (*Purged, error) {
+	var expired Purged
+	cutoff := "deleted_at < NOW() - make_interval(secs => $1)"
+	queries := []struct {
+		queryString string
+		dest        *[]int
+	}{
+		{`
+		WITH RECURSIVE expired AS (
+			SELECT id FROM movies WHERE ` + cutoff + `
+			UNION
+			SELECT m.id FROM movies m JOIN expired e ON m.parent_id = e.id WHERE m.deleted_at IS NOT NULL
+		)
+		SELECT id FROM expired`, &expired.MovieIDs},
+		{"SELECT id FROM stars WHERE " + cutoff, &expired.StarIDs},
+		{"SELECT id FROM users WHERE " + cutoff, &expired.UserIDs},
+		{"SELECT id FROM reviews WHERE " + cutoff, &expired.ReviewIDs},
+	}
+	for _, query := range queries {
+		rows, err := r.db.Query(ctx, query.queryString, retention.Seconds())
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		*query.dest, err = pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return &expired, nil
+}
+
+// Purge permanently removes the expired entities which are still deleted, along with their relations.
+// The images of the purged movies and stars are returned, so that their content can be deleted once the rows are gone.
+// Reviews of purged movies and users go away too, the ratings of the movies that lose live reviews are recalculated.
+// Every removed entity is recorded as purged.
+func (r *Repository) Purge(ctx context.Context, expired *Purged) (*Purged, error) {
+	var purged Purged
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		// Locking the rows makes concurrent restores wait and then find nothing to restore
+		locks := []struct {
+			table     string
+			condition string
+			ids       []int
+			dest      *[]int
+		}{
+			{"movies", "deleted_at IS NOT NULL", expired.MovieIDs, &purged.MovieIDs},
+			{"stars", "deleted_at IS NOT NULL", expired.StarIDs, &purged.StarIDs},
+			{"users", "deleted_at IS NOT NULL", expired.UserIDs, &purged.UserIDs},
+			{"reviews", "deleted_at IS NOT NULL", expired.ReviewIDs, &purged.ReviewIDs},
+		}
+		for _, lock := range locks {
+			rows, err := tx.Query(ctx, "SELECT id FROM "+lock.table+" WHERE id = ANY($1) and "+lock.condition+" FOR UPDATE", lock.ids)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+			*lock.dest, err = pgx.CollectRows(rows, pgx.RowTo[int])
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		rows, err := tx.Query(ctx,
+			"SELECT DISTINCT movie_id FROM reviews WHERE user_id = ANY($1) and deleted_at IS NULL and movie_id <> ALL($2)",
+			purged.UserIDs, purged.MovieIDs)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		affectedMovieIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		movieIDs, starIDs, userIDs, reviewIDs := purged.MovieIDs, purged.StarIDs, purged.UserIDs, purged.ReviewIDs
+		purged.Images, err = r.imagesRepo.DeleteByOwners(ctx, movieIDs, starIDs)
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx,
+			"DELETE FROM reviews WHERE id = ANY($1) or movie_id = ANY($2) or user_id = ANY($3) RETURNING id, movie_id, user_id",
+			reviewIDs, movieIDs, userIDs)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		purgedReviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*events.Review, error) {
+			var review events.Review
+			err := row.Scan(&review.ID, &review.MovieID, &review.UserID)
+			return &review, err
+		})
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		statements := []struct {
+			queryString string
+			args        []any
+		}{
+			{"DELETE FROM movie_genres WHERE movie_id = ANY($1)", []any{movieIDs}},
+			{"DELETE FROM movie_stars WHERE movie_id = ANY($1) or star_id = ANY($2)", []any{movieIDs, starIDs}},
+			{"DELETE FROM collection_movies WHERE movie_id = ANY($1)", []any{movieIDs}},
+			{"DELETE FROM award_nominations WHERE movie_id = ANY($1) or star_id = ANY($2)", []any{movieIDs, starIDs}},
+			// Redirects to purged entities have nowhere to lead anymore
+			{"UPDATE movies SET merged_into = NULL WHERE merged_into = ANY($1)", []any{movieIDs}},
+			{"UPDATE stars SET merged_into = NULL WHERE merged_into = ANY($1)", []any{starIDs}},
+			{"DELETE FROM movies WHERE id = ANY($1)", []any{movieIDs}},
+			{"DELETE FROM stars WHERE id = ANY($1)", []any{starIDs}},
+			{"DELETE FROM users WHERE id = ANY($1)", []any{userIDs}},
+		}
+		for _, statement := range statements {
+			_, err = tx.Exec(ctx, statement.queryString, statement.args...)
+			if err != nil {
+				return apperrors.Internal(err)
+			}
+		}
+
+		for _, movieID := range affectedMovieIDs {
+			if err = r.moviesRepo.RecalculateRating(ctx, movieID); err != nil {
+				return err
+			}
+		}
+
+		return recordPurged(ctx, tx, &purged, purgedReviews)
+	})
+	if err != nil {
+		return nil, apperrors.EnsureInternal(err)
+	}
+	return &purged, nil
+}
+
+func recordPurged(ctx context.Context, tx pgx.Tx, purged *Purged, reviews []*events.Review) error {
+	for _, review := range reviews {
+		if err := events.Record(ctx, tx, events.ReviewPurged, review.ID, review); err != nil {
+			return err
+		}
+	}
+	for _, id := range purged.MovieIDs {
+		if err := events.Record(ctx, tx, events.MoviePurged, id, &events.Movie{ID: id}); err != nil {
+			return err
+		}
+	}
+	for _, id := range purged.StarIDs {
+		if err := events.Record(ctx, tx, events.StarPurged, id, &events.Star{ID: id}); err != nil {
+			return err
+		}
+	}
+	for _, id := range purged.UserIDs {
+		if err := events.Record(ctx, tx, events.UserPurged, id, &events.User{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) restore(ctx context.Context, kindName string, id int, eventType string, payload any) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		if err := r.lockDeleted(ctx, tx, kindName, id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, "UPDATE "+kinds[kindName].table+" SET deleted_at = NULL, version = version + 1 WHERE id = $1", id)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		return events.Record(ctx, tx, eventType, id, payload)
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
+	}
+	return nil
+}
+
+// lockDeleted locks the deleted entity for a restore. Merged entities can't be restored,
+// their redirects have taken their place.
+func (r *Repository) lockDeleted(ctx context.Context, tx pgx.Tx, kindName string, id int) error {
+	k := kinds[kindName]
+
+	var deleted bool
+	var mergedInto *int
+	queryString := "SELECT deleted_at IS NOT NULL, " + k.mergedInto + " FROM " + k.table + " WHERE id = $1 FOR UPDATE"
+	err := tx.QueryRow(ctx, queryString, id).Scan(&deleted, &mergedInto)
+	if dbx.IsNoRows(err) {
+		return apperrors.NotFound(k.subject, "id", id)
+	}
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	switch {
+	case !deleted:
+		return apperrors.BadRequest(fmt.Errorf("%s with id %d is not deleted", k.subject, id))
+	case mergedInto != nil:
+		return apperrors.BadRequest(fmt.Errorf("%s with id %d is merged into %d and can't be restored", k.subject, id, *mergedInto))
+	default:
+		return nil
+	}
+}
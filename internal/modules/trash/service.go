@@ -0,0 +1,94 @@
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Service struct {
+	repo          *Repository
+	imagesService *images.Service
+	retention     time.Duration
+}
+
+func NewService(repo *Repository, imagesService *images.Service, retention time.Duration) *Service {
+	return &Service{
+		repo:          repo,
+		imagesService: imagesService,
+		retention:     retention,
+	}
+}
+
+func (s *Service) GetAllPaginated(ctx context.Context, kindName string, params *pagination.Params) (*pagination.Page[Item], error) {
+	page, err := s.repo.GetAllPaginated(ctx, kindName, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range page.Items {
+		item.PurgeAt = item.DeletedAt.Add(s.retention)
+	}
+	return page, nil
+}
+
+func (s *Service) Restore(ctx context.Context, kindName string, id int) error {
+	var err error
+	switch kindName {
+	case KindMovies:
+		err = s.repo.RestoreMovie(ctx, id)
+	case KindStars:
+		err = s.repo.RestoreStar(ctx, id)
+	case KindUsers:
+		err = s.repo.RestoreUser(ctx, id)
+	case KindReviews:
+		err = s.repo.RestoreReview(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("entity restored",
+		"kind", kindName,
+		"id", id)
+
+	return nil
+}
+
+// Purge permanently removes the entities deleted longer than the retention ago.
+func (s *Service) Purge(ctx context.Context) error {
+	expired, err := s.repo.GetExpired(ctx, s.retention)
+	if err != nil {
+		return err
+	}
+	if expired.Empty() {
+		return nil
+	}
+
+	purged, err := s.repo.Purge(ctx, expired)
+	if err != nil {
+		return err
+	}
+	// The content goes only after the commit, a failed purge must leave the images of restorable entities intact
+	s.imagesService.DeleteContent(ctx, purged.Images)
+
+	logger := log.FromContext(ctx)
+	logger.Info("trash purged",
+		"movies", len(purged.MovieIDs),
+		"stars", len(purged.StarIDs),
+		"users", len(purged.UserIDs),
+		"reviews", len(purged.ReviewIDs),
+		"images", len(purged.Images))
+
+	return nil
+}
+
+// PurgeJob runs the purge as a background job, it takes no payload.
+func (s *Service) PurgeJob(ctx context.Context, _ json.RawMessage) error {
+	return s.Purge(ctx)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/mkuptsov/movie-reviews/contracts"
 	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/jwt"
 )
 
 type Handler struct {
@@ -27,8 +28,11 @@ func (h *Handler) GetUserByID(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	if echox.NotModified(c, user.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 
-	return c.JSON(http.StatusOK, user)
+	return c.JSON(http.StatusOK, withoutPrivate(c, user))
 }
 
 func (h *Handler) GetUserByUserName(c echo.Context) error {
@@ -41,8 +45,11 @@ func (h *Handler) GetUserByUserName(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	if echox.NotModified(c, user.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 
-	return c.JSON(http.StatusOK, user)
+	return c.JSON(http.StatusOK, withoutPrivate(c, user))
 }
 
 func (h *Handler) DeleteUser(c echo.Context) error {
@@ -50,8 +57,12 @@ func (h *Handler) DeleteUser(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
 
-	err = h.Service.DeleteUser(c.Request().Context(), req.UserID)
+	err = h.Service.DeleteUser(c.Request().Context(), req.UserID, version)
 	if err != nil {
 		return err
 	}
@@ -64,12 +75,53 @@ func (h *Handler) Update(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	return h.update(c, req, version)
+}
 
-	err = h.Service.Update(c.Request().Context(), req.UserID, *req.Bio)
+// Patch applies a merge patch to the user. Unless If-Match says otherwise, the patch is conditional
+// on the version it was applied to.
+func (h *Handler) Patch(c echo.Context) error {
+	var current *User
+	req, err := echox.BindMergePatch(c, func(req *contracts.UpdateUserRequest) error {
+		user, err := h.Service.GetUserByID(c.Request().Context(), req.UserID)
+		if err != nil {
+			return err
+		}
+
+		current = user
+		req.Bio = user.Bio
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	version, err := echox.IfMatch(c)
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		version = &current.Version
+	}
+	// A null bio removes it from the user
+	if req.Bio == nil {
+		req.Bio = contracts.Ptr("")
+	}
+
+	return h.update(c, req, version)
+}
+
+func (h *Handler) update(c echo.Context, req *contracts.UpdateUserRequest, version *int) error {
+	newVersion, err := h.Service.Update(c.Request().Context(), req.UserID, req.Bio, version)
 	if err != nil {
 		return err
 	}
 
+	echox.SetETag(c, newVersion)
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -86,3 +138,15 @@ func (h *Handler) SetUserRole(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// withoutPrivate leaves out the details of the user which only they and the admins may see.
+func withoutPrivate(c echo.Context, user *User) *User {
+	claims := jwt.GetClaims(c)
+	if claims != nil && (claims.Role == AdminRole || claims.UserID == user.ID) {
+		return user
+	}
+
+	public := *user
+	public.EraseAt = nil
+	return &public
+}
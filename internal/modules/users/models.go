@@ -16,6 +16,8 @@ type User struct {
 	Bio       *string    `json:"bio,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	EraseAt   *time.Time `json:"erase_at,omitempty"`
+	Version   int        `json:"version"`
 }
 
 type UserWithPassword struct {
@@ -2,10 +2,14 @@ package users
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
 )
 
 type Repository struct {
@@ -19,24 +23,31 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 }
 
 func (r *Repository) Create(ctx context.Context, user *UserWithPassword) error {
-	queryString := "INSERT INTO users (username, email, pass_hash, role) VALUES ($1, $2, $3, $4) returning id, created_at, role"
-	err := r.db.QueryRow(ctx, queryString, user.Username, user.Email, user.PasswordHash, user.Role).Scan(&user.ID, &user.CreatedAt, &user.Role)
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "INSERT INTO users (username, email, pass_hash, role) VALUES ($1, $2, $3, $4) returning id, created_at, role"
+		err := tx.QueryRow(ctx, queryString, user.Username, user.Email, user.PasswordHash, user.Role).Scan(&user.ID, &user.CreatedAt, &user.Role)
 
-	if dbx.IsUniqueViolation(err, "email") {
-		return apperrors.AlreadyExists("user", "email", user.Email)
-	}
-	if dbx.IsUniqueViolation(err, "username") {
-		return apperrors.AlreadyExists("user", "username", user.Username)
-	}
+		if dbx.IsUniqueViolation(err, "email") {
+			return apperrors.AlreadyExists("user", "email", user.Email)
+		}
+		if dbx.IsUniqueViolation(err, "username") {
+			return apperrors.AlreadyExists("user", "username", user.Username)
+		}
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return events.Record(ctx, tx, events.UserRegistered, user.ID, &events.User{ID: user.ID, Username: user.Username, Role: user.Role})
+	})
 	if err != nil {
-		return apperrors.Internal(err)
+		return apperrors.EnsureInternal(err)
 	}
 	return nil
 }
 
 func (r *Repository) GetUserWithPassword(ctx context.Context, email string) (*UserWithPassword, error) {
 	queryString := `
-	SELECT id, username, email, pass_hash, role, created_at, deleted_at, bio
+	SELECT id, username, email, pass_hash, role, created_at, deleted_at, erase_at, bio, version
 	FROM users
 	WHERE email = $1 and deleted_at IS NULL;`
 
@@ -53,7 +64,9 @@ func (r *Repository) GetUserWithPassword(ctx context.Context, email string) (*Us
 		&user.Role,
 		&user.CreatedAt,
 		&user.DeletedAt,
+		&user.EraseAt,
 		&user.Bio,
+		&user.Version,
 	)
 	if dbx.IsNoRows(err) {
 		return nil, apperrors.NotFound("user", "email", email)
@@ -66,7 +79,7 @@ func (r *Repository) GetUserWithPassword(ctx context.Context, email string) (*Us
 
 func (r *Repository) GetUserByID(ctx context.Context, id int) (*User, error) {
 	queryString := `
-	SELECT id, username, email, role, created_at, deleted_at, bio
+	SELECT id, username, email, role, created_at, deleted_at, erase_at, bio, version
 	FROM users
 	WHERE id = $1 and deleted_at IS NULL;`
 
@@ -80,7 +93,9 @@ func (r *Repository) GetUserByID(ctx context.Context, id int) (*User, error) {
 		&user.Role,
 		&user.CreatedAt,
 		&user.DeletedAt,
+		&user.EraseAt,
 		&user.Bio,
+		&user.Version,
 	)
 	if dbx.IsNoRows(err) {
 		return nil, apperrors.NotFound("user", "id", id)
@@ -91,9 +106,47 @@ func (r *Repository) GetUserByID(ctx context.Context, id int) (*User, error) {
 	return &user, nil
 }
 
+func (r *Repository) GetUsersByIDs(ctx context.Context, ids []int) (map[int]*User, error) {
+	queryString := `
+	SELECT id, username, email, role, created_at, deleted_at, erase_at, bio, version
+	FROM users
+	WHERE id = ANY($1) and deleted_at IS NULL`
+
+	rows, err := r.db.Query(ctx, queryString, ids)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	users := make(map[int]*User, len(ids))
+	for rows.Next() {
+		var user User
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Role,
+			&user.CreatedAt,
+			&user.DeletedAt,
+			&user.EraseAt,
+			&user.Bio,
+			&user.Version,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		users[user.ID] = &user
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return users, nil
+}
+
 func (r *Repository) GetUserByUserName(ctx context.Context, userName string) (*User, error) {
 	queryString := `
-	SELECT id, username, email, role, created_at, deleted_at, bio
+	SELECT id, username, email, role, created_at, deleted_at, erase_at, bio, version
 	FROM users
 	WHERE username = $1 and deleted_at IS NULL;`
 
@@ -107,7 +160,9 @@ func (r *Repository) GetUserByUserName(ctx context.Context, userName string) (*U
 		&user.Role,
 		&user.CreatedAt,
 		&user.DeletedAt,
+		&user.EraseAt,
 		&user.Bio,
+		&user.Version,
 	)
 	if dbx.IsNoRows(err) {
 		return nil, apperrors.NotFound("user", "username", userName)
@@ -118,41 +173,106 @@ func (r *Repository) GetUserByUserName(ctx context.Context, userName string) (*U
 	return &user, nil
 }
 
-func (r *Repository) DeleteUser(ctx context.Context, id int) error {
-	queryString := "UPDATE users SET deleted_at = NOW() WHERE id = $1 and deleted_at IS NULL;"
-	cmdTag, err := r.db.Exec(ctx, queryString, id)
-	if err != nil {
-		return apperrors.Internal(err)
-	}
+func (r *Repository) DeleteUser(ctx context.Context, id int, version *int) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "UPDATE users SET deleted_at = NOW() WHERE id = $1 and deleted_at IS NULL and ($2::int IS NULL or version = $2);"
+		cmdTag, err := tx.Exec(ctx, queryString, id, version)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
 
-	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("user", "id", id)
+		if cmdTag.RowsAffected() == 0 {
+			return r.modificationError(ctx, id, version)
+		}
+
+		return events.Record(ctx, tx, events.UserDeleted, id, &events.User{ID: id})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
 	}
 	return nil
 }
 
-func (r *Repository) Update(ctx context.Context, id int, bio string) error {
-	queryString := "UPDATE users SET bio = $2 WHERE id = $1 and deleted_at IS NULL;"
-	cmdTag, err := r.db.Exec(ctx, queryString, id, bio)
+// Update sets the bio of the user and returns the new version. A nil bio is left as it is,
+// a nil version updates the user whatever version it has.
+func (r *Repository) Update(ctx context.Context, id int, bio *string, version *int) (int, error) {
+	queryString := `
+	UPDATE users SET bio = coalesce($2, bio), version = version + 1
+	WHERE id = $1 and deleted_at IS NULL and ($3::int IS NULL or version = $3)
+	RETURNING version;`
+
+	var newVersion int
+	err := r.db.QueryRow(ctx, queryString, id, bio, version).Scan(&newVersion)
+	if dbx.IsNoRows(err) {
+		return 0, r.modificationError(ctx, id, version)
+	}
 	if err != nil {
-		return apperrors.Internal(err)
+		return 0, apperrors.Internal(err)
 	}
+	return newVersion, nil
+}
 
-	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("user", "id", id)
+func (r *Repository) SetUserRole(ctx context.Context, id int, roleName string) error {
+	err := dbx.InTransaction(ctx, r.db, func(ctx context.Context, tx pgx.Tx) error {
+		queryString := "UPDATE users SET role = $2, version = version + 1 WHERE id = $1"
+		cmdTag, err := tx.Exec(ctx, queryString, id, roleName)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return apperrors.NotFound("user", "id", id)
+		}
+
+		return events.Record(ctx, tx, events.UserRoleChanged, id, &events.User{ID: id, Role: roleName})
+	})
+	if err != nil {
+		return apperrors.EnsureInternal(err)
 	}
 	return nil
 }
 
-func (r *Repository) SetUserRole(ctx context.Context, id int, roleName string) error {
-	queryString := "UPDATE users SET role = $2 WHERE id = $1"
-	cmdTag, err := r.db.Exec(ctx, queryString, id, roleName)
+// ScheduleErasure sets the user to be erased after the grace period, unless the erasure is already scheduled,
+// and returns the time of the erasure.
+func (r *Repository) ScheduleErasure(ctx context.Context, id int, gracePeriod time.Duration) (time.Time, error) {
+	queryString := `
+	UPDATE users SET erase_at = coalesce(erase_at, NOW() + make_interval(secs => $2)), version = version + 1
+	WHERE id = $1 and deleted_at IS NULL
+	RETURNING erase_at`
+
+	var eraseAt time.Time
+	err := r.db.QueryRow(ctx, queryString, id, gracePeriod.Seconds()).Scan(&eraseAt)
+	if dbx.IsNoRows(err) {
+		return time.Time{}, apperrors.NotFound("user", "id", id)
+	}
+	if err != nil {
+		return time.Time{}, apperrors.Internal(err)
+	}
+	return eraseAt, nil
+}
+
+func (r *Repository) CancelErasure(ctx context.Context, id int) error {
+	queryString := "UPDATE users SET erase_at = NULL, version = version + 1 WHERE id = $1 and deleted_at IS NULL and erase_at IS NOT NULL"
+	cmdTag, err := r.db.Exec(ctx, queryString, id)
 	if err != nil {
 		return apperrors.Internal(err)
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return apperrors.NotFound("user", "id", id)
+		if _, err = r.GetUserByID(ctx, id); err != nil {
+			return err
+		}
+		return apperrors.BadRequest(errors.New("no erasure is scheduled"))
 	}
 	return nil
 }
+
+// modificationError tells whether the user wasn't modified because it doesn't exist or because of a stale version.
+func (r *Repository) modificationError(ctx context.Context, id int, version *int) error {
+	_, err := r.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return apperrors.VersionMismatch("user", "id", id, *version)
+}
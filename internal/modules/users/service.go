@@ -36,12 +36,16 @@ func (s *Service) GetUserByID(ctx context.Context, id int) (*User, error) {
 	return s.repo.GetUserByID(ctx, id)
 }
 
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []int) (map[int]*User, error) {
+	return s.repo.GetUsersByIDs(ctx, ids)
+}
+
 func (s *Service) GetUserByUserName(ctx context.Context, userName string) (*User, error) {
 	return s.repo.GetUserByUserName(ctx, userName)
 }
 
-func (s *Service) DeleteUser(ctx context.Context, id int) error {
-	err := s.repo.DeleteUser(ctx, id)
+func (s *Service) DeleteUser(ctx context.Context, id int, version *int) error {
+	err := s.repo.DeleteUser(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -52,8 +56,8 @@ func (s *Service) DeleteUser(ctx context.Context, id int) error {
 	return nil
 }
 
-func (s *Service) Update(ctx context.Context, id int, bio string) error {
-	return s.repo.Update(ctx, id, bio)
+func (s *Service) Update(ctx context.Context, id int, bio *string, version *int) (int, error) {
+	return s.repo.Update(ctx, id, bio, version)
 }
 
 func (s *Service) SetUserRole(ctx context.Context, id int, roleName string) error {
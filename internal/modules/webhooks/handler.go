@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Handler struct {
+	Service          *Service
+	PaginationConfig config.PaginationConfig
+}
+
+func NewHandler(service *Service, cfg config.PaginationConfig) *Handler {
+	return &Handler{
+		Service:          service,
+		PaginationConfig: cfg,
+	}
+}
+
+func (h *Handler) GetAll(c echo.Context) error {
+	webhooks, err := h.Service.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *Handler) Get(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetWebhookRequest](c)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := h.Service.GetByID(c.Request().Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (h *Handler) Create(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.CreateWebhookRequest](c)
+	if err != nil {
+		return err
+	}
+
+	webhook := &Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Active:     req.Active == nil || *req.Active,
+	}
+	if err = h.Service.Create(c.Request().Context(), webhook); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *Handler) Update(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.UpdateWebhookRequest](c)
+	if err != nil {
+		return err
+	}
+
+	err = h.Service.Update(c.Request().Context(), req.ID, &Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Active:     req.Active,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Delete(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.DeleteWebhookRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if err = h.Service.Delete(c.Request().Context(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) GetDeliveries(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.GetWebhookDeliveriesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Resolve(&req.PaginatedRequest, h.PaginationConfig)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Service.GetDeliveriesPaginated(c.Request().Context(), req.WebhookID, req.Status, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, pagination.Response(&req.PaginatedRequest, params, page))
+}
+
+func (h *Handler) Replay(c echo.Context) error {
+	req, err := echox.BindAndValidate[contracts.ReplayWebhookDeliveryRequest](c)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := h.Service.Replay(c.Request().Context(), req.WebhookID, req.DeliveryID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, delivery)
+}
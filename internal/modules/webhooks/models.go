@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeliverJobKind is the kind of the job posting a delivery to its webhook.
+const DeliverJobKind = "webhooks.deliver"
+
+// CleanupJobKind is the kind of the job removing the finished deliveries older than the retention.
+const CleanupJobKind = "webhooks.cleanup"
+
+// Statuses of a delivery. A pending delivery is yet to be posted or is going to be retried.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	webhookColumns  = "id, url, event_types, active, created_at"
+	deliveryColumns = "id, webhook_id, event_id, event_type, body, status, attempts, response_status, last_error, replay_of, created_at, last_attempt_at, delivered_at"
+)
+
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"-"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (w *Webhook) scanDest() []any {
+	return []any{
+		&w.ID,
+		&w.URL,
+		&w.EventTypes,
+		&w.Active,
+		&w.CreatedAt,
+	}
+}
+
+type Delivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Body           json.RawMessage `json:"body"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	ReplayOf       *int64          `json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func (d *Delivery) scanDest() []any {
+	return []any{
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&d.Body,
+		&d.Status,
+		&d.Attempts,
+		&d.ResponseStatus,
+		&d.LastError,
+		&d.ReplayOf,
+		&d.CreatedAt,
+		&d.LastAttemptAt,
+		&d.DeliveredAt,
+	}
+}
+
+// attempt is the outcome of posting a delivery, the response status is nil if there was no response.
+type attempt struct {
+	status         string
+	responseStatus *int
+	err            error
+}
+
+type deliverPayload struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
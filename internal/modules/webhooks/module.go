@@ -0,0 +1,25 @@
+package webhooks
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/modules/jobs"
+)
+
+type Module struct {
+	Handler    *Handler
+	Service    *Service
+	Repository *Repository
+}
+
+func NewModule(db *pgxpool.Pool, jobsModule *jobs.Module, cfg config.WebhooksConfig, paginationConfig config.PaginationConfig) *Module {
+	repo := NewRepository(db)
+	service := NewService(repo, jobsModule.Service, cfg)
+	handler := NewHandler(service, paginationConfig)
+
+	return &Module{
+		Handler:    handler,
+		Service:    service,
+		Repository: repo,
+	}
+}
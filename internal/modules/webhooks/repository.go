@@ -0,0 +1,284 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// InTransaction runs fn in a transaction, the repository methods called with its context take part in it.
+func (r *Repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbx.InTransaction(ctx, r.db, func(ctx context.Context, _ pgx.Tx) error {
+		return fn(ctx)
+	})
+}
+
+func (r *Repository) GetAll(ctx context.Context) ([]*Webhook, error) {
+	queryString := fmt.Sprintf("SELECT %s FROM webhooks ORDER BY id", webhookColumns)
+	rows, err := r.db.Query(ctx, queryString)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	webhooks, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Webhook, error) {
+		var webhook Webhook
+		err := row.Scan(webhook.scanDest()...)
+		return &webhook, err
+	})
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return webhooks, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Webhook, error) {
+	queryString := fmt.Sprintf("SELECT %s FROM webhooks WHERE id = $1", webhookColumns)
+
+	var webhook Webhook
+	err := r.db.QueryRow(ctx, queryString, id).Scan(webhook.scanDest()...)
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("webhook", "id", id)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return &webhook, nil
+}
+
+func (r *Repository) Create(ctx context.Context, webhook *Webhook) error {
+	queryString := "INSERT INTO webhooks (url, event_types, secret, active) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	err := r.db.QueryRow(ctx, queryString, webhook.URL, webhook.EventTypes, webhook.Secret, webhook.Active).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, id int, webhook *Webhook) error {
+	queryString := "UPDATE webhooks SET url = $2, event_types = $3, secret = $4, active = $5 WHERE id = $1"
+	cmdTag, err := r.db.Exec(ctx, queryString, id, webhook.URL, webhook.EventTypes, webhook.Secret, webhook.Active)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("webhook", "id", id)
+	}
+	return nil
+}
+
+// Delete removes the webhook together with its deliveries.
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	cmdTag, err := r.db.Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperrors.NotFound("webhook", "id", id)
+	}
+	return nil
+}
+
+// CreateDeliveries adds a delivery of the event to every active webhook subscribed to its type and returns their IDs.
+// The webhooks which already have a delivery of the event are skipped, so dispatching the event again is harmless.
+func (r *Repository) CreateDeliveries(ctx context.Context, event *events.Event, body []byte) ([]int64, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := `
+	INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, body)
+	SELECT id, $1::bigint, $2::text, $3::jsonb FROM webhooks
+	WHERE active and ($2 = ANY(event_types) or $4 = ANY(event_types))
+	ON CONFLICT (webhook_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	RETURNING id`
+
+	rows, err := q.Query(ctx, queryString, event.ID, event.Type, body, events.AllTypes)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return ids, nil
+}
+
+// Replay adds a new delivery of the event of the delivery to the same webhook.
+func (r *Repository) Replay(ctx context.Context, webhookID int, deliveryID int64) (*Delivery, error) {
+	q := dbx.FromContext(ctx, r.db)
+	queryString := fmt.Sprintf(`
+	INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, body, replay_of)
+	SELECT webhook_id, event_id, event_type, body, id FROM webhook_deliveries
+	WHERE id = $1 and webhook_id = $2
+	RETURNING %s`, deliveryColumns)
+
+	var delivery Delivery
+	err := q.QueryRow(ctx, queryString, deliveryID, webhookID).Scan(delivery.scanDest()...)
+	if dbx.IsNoRows(err) {
+		return nil, apperrors.NotFound("delivery", "id", deliveryID)
+	}
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return &delivery, nil
+}
+
+// GetDelivery returns the delivery with the webhook it is posted to, including its secret.
+func (r *Repository) GetDelivery(ctx context.Context, id int64) (*Delivery, *Webhook, error) {
+	queryString := `
+	SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.body, d.status, d.attempts, d.response_status,
+		d.last_error, d.replay_of, d.created_at, d.last_attempt_at, d.delivered_at,
+		w.url, w.secret, w.active
+	FROM webhook_deliveries d
+	INNER JOIN webhooks w on w.id = d.webhook_id
+	WHERE d.id = $1`
+
+	var delivery Delivery
+	var webhook Webhook
+	err := r.db.QueryRow(ctx, queryString, id).
+		Scan(append(delivery.scanDest(), &webhook.URL, &webhook.Secret, &webhook.Active)...)
+	if dbx.IsNoRows(err) {
+		return nil, nil, apperrors.NotFound("delivery", "id", id)
+	}
+	if err != nil {
+		return nil, nil, apperrors.Internal(err)
+	}
+
+	webhook.ID = delivery.WebhookID
+	return &delivery, &webhook, nil
+}
+
+// RecordAttempt saves the outcome of posting the delivery.
+func (r *Repository) RecordAttempt(ctx context.Context, id int64, a *attempt) error {
+	var lastError *string
+	if a.err != nil {
+		lastError = new(string)
+		*lastError = a.err.Error()
+	}
+
+	queryString := `
+	UPDATE webhook_deliveries
+	SET status = $2,
+		attempts = attempts + 1,
+		response_status = $3,
+		last_error = $4,
+		last_attempt_at = NOW(),
+		delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() END
+	WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, queryString, id, a.status, a.responseStatus, lastError)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// DeleteExpiredDeliveries removes the deliveries of the events whose deliveries to the webhook have all finished
+// before the retention, and returns their number. The replays go along with the deliveries they replay, so a delivery
+// is kept as long as a replay of its event is pending or recent.
+func (r *Repository) DeleteExpiredDeliveries(ctx context.Context, retention time.Duration) (int64, error) {
+	queryString := `
+	DELETE FROM webhook_deliveries d
+	WHERE d.created_at < NOW() - make_interval(secs => $1)
+		and NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries o
+			WHERE o.webhook_id = d.webhook_id and o.event_id = d.event_id
+				and (o.status = 'pending' or o.created_at >= NOW() - make_interval(secs => $1)))`
+
+	tag, err := r.db.Exec(ctx, queryString, retention.Seconds())
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetDeliveriesPaginated returns the deliveries of the webhook with the status if it is given, the most recent first.
+func (r *Repository) GetDeliveriesPaginated(ctx context.Context, webhookID int, status *string, params *pagination.Params) (*pagination.Page[Delivery], error) {
+	queryPage := dbx.StatementBuilder.
+		Select(deliveryColumns).
+		From("webhook_deliveries").
+		Where("webhook_id = ?", webhookID).
+		Limit(uint64(params.Limit + 1)).
+		Offset(uint64(params.Offset))
+	queryTotal := dbx.StatementBuilder.
+		Select("count(*)").
+		From("webhook_deliveries").
+		Where("webhook_id = ?", webhookID)
+
+	if status != nil {
+		queryPage = queryPage.Where("status = ?", *status)
+		queryTotal = queryTotal.Where("status = ?", *status)
+	}
+
+	keyset := dbx.Keyset{{Expr: "id", Type: dbx.KeyBigInt, Desc: true}}
+	queryPage, err := keyset.Apply(queryPage, params.Cursor, params.Backward)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &pgx.Batch{}
+
+	err = dbx.QueueBatchSelect(b, queryPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WithTotal {
+		err = dbx.QueueBatchSelect(b, queryTotal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	br := r.db.SendBatch(ctx, b)
+	defer br.Close()
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var items []*Delivery
+	var keys [][]string
+	for rows.Next() {
+		var delivery Delivery
+		key := keyset.NewKey()
+		err = rows.Scan(append(delivery.scanDest(), keyset.ScanDest(key)...)...)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		items = append(items, &delivery)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var total *int
+	if params.WithTotal {
+		total = new(int)
+		err = br.QueryRow().Scan(total)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	return pagination.NewPage(params, items, keys, total), nil
+}
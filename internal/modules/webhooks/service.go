@@ -0,0 +1,226 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/events"
+	"github.com/mkuptsov/movie-reviews/internal/log"
+	"github.com/mkuptsov/movie-reviews/internal/modules/jobs"
+	"github.com/mkuptsov/movie-reviews/internal/pagination"
+)
+
+var errInactive = errors.New("webhook is inactive")
+
+type Service struct {
+	repo      *Repository
+	jobs      *jobs.Service
+	client    *http.Client
+	retention time.Duration
+}
+
+func NewService(repo *Repository, jobsService *jobs.Service, cfg config.WebhooksConfig) *Service {
+	return &Service{
+		repo:      repo,
+		jobs:      jobsService,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		retention: cfg.Retention,
+	}
+}
+
+func (s *Service) GetAll(ctx context.Context) ([]*Webhook, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *Service) GetByID(ctx context.Context, id int) (*Webhook, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, webhook *Webhook) error {
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("webhook created",
+		"id", webhook.ID,
+		"eventTypes", webhook.EventTypes)
+	return nil
+}
+
+func (s *Service) Update(ctx context.Context, id int, webhook *Webhook) error {
+	if err := s.repo.Update(ctx, id, webhook); err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("webhook updated",
+		"id", id,
+		"eventTypes", webhook.EventTypes,
+		"active", webhook.Active)
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("webhook deleted", "id", id)
+	return nil
+}
+
+func (s *Service) GetDeliveriesPaginated(ctx context.Context, webhookID int, status *string, params *pagination.Params) (*pagination.Page[Delivery], error) {
+	if _, err := s.repo.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveriesPaginated(ctx, webhookID, status, params)
+}
+
+// Replay posts the event of the delivery to the webhook again, as a new delivery.
+func (s *Service) Replay(ctx context.Context, webhookID int, deliveryID int64) (*Delivery, error) {
+	var delivery *Delivery
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if delivery, err = s.repo.Replay(ctx, webhookID, deliveryID); err != nil {
+			return err
+		}
+		_, err = s.jobs.Enqueue(ctx, DeliverJobKind, &deliverPayload{DeliveryID: delivery.ID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("webhook delivery replayed",
+		"webhook", webhookID,
+		"delivery", deliveryID,
+		"replay", delivery.ID)
+	return delivery, nil
+}
+
+// HandleEvent creates the deliveries of the event to the webhooks subscribed to it, it is an events.Handler.
+// The deliveries are posted by the background jobs enqueued in the same transaction.
+func (s *Service) HandleEvent(ctx context.Context, event *events.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		ids, err := s.repo.CreateDeliveries(ctx, event, body)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if _, err = s.jobs.Enqueue(ctx, DeliverJobKind, &deliverPayload{DeliveryID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CleanupJob removes the finished deliveries older than the retention, it runs as a background job.
+func (s *Service) CleanupJob(ctx context.Context, _ json.RawMessage) error {
+	n, err := s.repo.DeleteExpiredDeliveries(ctx, s.retention)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("webhook deliveries cleaned up", "count", n)
+	return nil
+}
+
+// DeliverJob posts a delivery to its webhook, it runs as a background job. A failed attempt is recorded
+// and returned, so that the job is retried, unless it is the last one.
+func (s *Service) DeliverJob(ctx context.Context, payload json.RawMessage) error {
+	var p deliverPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	delivery, webhook, err := s.repo.GetDelivery(ctx, p.DeliveryID)
+	if apperrors.Is(err, apperrors.NotFoundCode) {
+		// the webhook has been deleted with its deliveries, or the delivery has been erased with its user
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != DeliveryPending {
+		return nil
+	}
+
+	logger := log.FromContext(ctx).With("webhook", webhook.ID, "delivery", delivery.ID, "eventType", delivery.EventType)
+
+	if !webhook.Active {
+		logger.Info("webhook is inactive, delivery dropped")
+		return s.repo.RecordAttempt(ctx, delivery.ID, &attempt{status: DeliveryFailed, err: errInactive})
+	}
+
+	a := s.post(ctx, webhook, delivery)
+	if a.err != nil && !jobs.IsLastAttempt(ctx) {
+		a.status = DeliveryPending
+	}
+	if err = s.repo.RecordAttempt(ctx, delivery.ID, a); err != nil {
+		return err
+	}
+
+	if a.err != nil {
+		logger.Warn("webhook delivery failed", "err", a.err)
+		return a.err
+	}
+
+	logger.Info("webhook delivered")
+	return nil
+}
+
+// post sends the body of the delivery signed with the secret of the webhook. The X-Webhook-Signature header
+// is the hex encoded HMAC-SHA256 of the timestamp and the body joined by a dot, e.g. "sha256=5257a869...".
+// The timestamp lets the receiver reject old deliveries, it is sent in the X-Webhook-Timestamp header.
+func (s *Service) post(ctx context.Context, webhook *Webhook, delivery *Delivery) *attempt {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(delivery.Body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return &attempt{status: DeliveryFailed, err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", strconv.Itoa(webhook.ID))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return &attempt{status: DeliveryFailed, err: fmt.Errorf("post delivery: %w", err)}
+	}
+	defer res.Body.Close()
+
+	a := &attempt{status: DeliverySucceeded, responseStatus: &res.StatusCode}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		a.status = DeliveryFailed
+		a.err = fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return a
+}
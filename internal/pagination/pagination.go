@@ -1,10 +1,33 @@
 package pagination
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
 	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/config"
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
 )
 
+// Params is a paginated request resolved into what the repositories need to select a page.
+type Params struct {
+	Offset    int
+	Limit     int
+	Cursor    []string
+	Backward  bool
+	WithTotal bool
+}
+
+// Page is a selected page along with the keyset keys of its items, see dbx.Keyset.
+type Page[T any] struct {
+	Items   []*T
+	Keys    [][]string
+	Total   *int
+	HasMore bool
+}
+
 func SetDefaults(r *contracts.PaginatedRequest, cfg config.PaginationConfig) {
 	if r.Page == 0 {
 		r.Page = 1
@@ -24,11 +47,106 @@ func OffsetLimit(r *contracts.PaginatedRequest) (int, int) {
 	return offset, limit
 }
 
-func Response[T any](r *contracts.PaginatedRequest, total int, items []*T) *contracts.PaginatedResponse[T] {
-	return &contracts.PaginatedResponse[T]{
-		Page:  r.Page,
-		Size:  r.Size,
-		Total: total,
-		Items: items,
+// Resolve applies the defaults and decodes the cursor of the request. The total is counted
+// for page-numbered requests unless it is explicitly disabled and only on demand for cursor ones.
+func Resolve(r *contracts.PaginatedRequest, cfg config.PaginationConfig) (*Params, error) {
+	if r.After != "" && r.Before != "" {
+		return nil, apperrors.BadRequest(errors.New("only one of after and before can be provided"))
+	}
+
+	SetDefaults(r, cfg)
+	params := &Params{}
+
+	switch {
+	case r.After != "" || r.Before != "":
+		cursor, err := decodeCursor(r.After + r.Before)
+		if err != nil {
+			return nil, err
+		}
+		r.Page = 0
+		params.Cursor = cursor
+		params.Backward = r.Before != ""
+		params.Limit = r.Size
+		params.WithTotal = r.WithTotal != nil && *r.WithTotal
+	default:
+		params.Offset, params.Limit = OffsetLimit(r)
+		params.WithTotal = r.WithTotal == nil || *r.WithTotal
+	}
+
+	return params, nil
+}
+
+// NewPage builds a page out of rows selected with Limit+1 so that the extra row tells whether there are more of them.
+func NewPage[T any](p *Params, items []*T, keys [][]string, total *int) *Page[T] {
+	page := &Page[T]{Total: total}
+	if len(items) > p.Limit {
+		items, keys = items[:p.Limit], keys[:p.Limit]
+		page.HasMore = true
+	}
+
+	if p.Backward {
+		reverse(items)
+		reverse(keys)
+	}
+
+	page.Items, page.Keys = items, keys
+	return page
+}
+
+func Response[T any](r *contracts.PaginatedRequest, p *Params, page *Page[T]) *contracts.PaginatedResponse[T] {
+	res := &contracts.PaginatedResponse[T]{
+		Page:      r.Page,
+		Size:      r.Size,
+		Uncounted: page.Total == nil,
+		Items:     page.Items,
+	}
+	if page.Total != nil {
+		res.Total = *page.Total
+	}
+
+	if len(page.Keys) == 0 {
+		return res
+	}
+
+	first, last := page.Keys[0], page.Keys[len(page.Keys)-1]
+	if p.Backward {
+		if page.HasMore {
+			res.Prev = encodeCursor(first)
+		}
+		res.Next = encodeCursor(last)
+	} else {
+		if page.HasMore {
+			res.Next = encodeCursor(last)
+		}
+		if p.Offset > 0 || len(p.Cursor) > 0 {
+			res.Prev = encodeCursor(first)
+		}
+	}
+
+	return res
+}
+
+func encodeCursor(key []string) *string {
+	data, _ := json.Marshal(key)
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	return &cursor
+}
+
+func decodeCursor(cursor string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, apperrors.BadRequest(dbx.ErrInvalidCursor)
+	}
+
+	var key []string
+	if err = json.Unmarshal(data, &key); err != nil || len(key) == 0 {
+		return nil, apperrors.BadRequest(dbx.ErrInvalidCursor)
+	}
+	return key, nil
+}
+
+func reverse[T any](s []T) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
 	}
 }
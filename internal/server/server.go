@@ -13,14 +13,27 @@ import (
 	"github.com/mkuptsov/movie-reviews/internal/apperrors"
 	"github.com/mkuptsov/movie-reviews/internal/config"
 	"github.com/mkuptsov/movie-reviews/internal/echox"
+	"github.com/mkuptsov/movie-reviews/internal/events"
 	"github.com/mkuptsov/movie-reviews/internal/jwt"
+	"github.com/mkuptsov/movie-reviews/internal/live"
 	"github.com/mkuptsov/movie-reviews/internal/log"
 	"github.com/mkuptsov/movie-reviews/internal/modules/auth"
+	"github.com/mkuptsov/movie-reviews/internal/modules/awards"
+	"github.com/mkuptsov/movie-reviews/internal/modules/bulk"
+	"github.com/mkuptsov/movie-reviews/internal/modules/collections"
 	"github.com/mkuptsov/movie-reviews/internal/modules/genres"
+	"github.com/mkuptsov/movie-reviews/internal/modules/graphql"
+	"github.com/mkuptsov/movie-reviews/internal/modules/images"
+	"github.com/mkuptsov/movie-reviews/internal/modules/jobs"
 	"github.com/mkuptsov/movie-reviews/internal/modules/movies"
+	"github.com/mkuptsov/movie-reviews/internal/modules/privacy"
 	"github.com/mkuptsov/movie-reviews/internal/modules/reviews"
 	"github.com/mkuptsov/movie-reviews/internal/modules/stars"
+	"github.com/mkuptsov/movie-reviews/internal/modules/streams"
+	"github.com/mkuptsov/movie-reviews/internal/modules/trash"
 	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/modules/webhooks"
+	"github.com/mkuptsov/movie-reviews/internal/storage"
 	"github.com/mkuptsov/movie-reviews/internal/validation"
 	"golang.org/x/exp/slog"
 	"gopkg.in/validator.v2"
@@ -32,9 +45,12 @@ const (
 )
 
 type Server struct {
-	e       *echo.Echo
-	cfg     *config.Config
-	closers []func() error
+	e          *echo.Echo
+	cfg        *config.Config
+	worker     *jobs.Worker
+	dispatcher *events.Dispatcher
+	broker     *live.Broker
+	closers    []func() error
 }
 
 func New(ctx context.Context, cfg *config.Config) (*Server, error) {
@@ -53,18 +69,82 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 	}
 	closers = append(closers, func() error { db.Close(); return nil })
 
+	blobStorage, err := storage.New(ctx, cfg.Storage)
+	if err != nil {
+		return nil, withClosers(closers, fmt.Errorf("setup storage: %w", err))
+	}
+
 	jwtService := jwt.NewService(cfg.Jwt.Secret, cfg.Jwt.AccessExpiration)
 	usersModule := users.NewModule(db)
 	authModule := auth.NewModule(usersModule.Service, jwtService)
 	genresModule := genres.NewModule(db)
-	starsModule := stars.NewModule(db, cfg.Pagination)
-	moviesModule := movies.NewModule(db, genresModule, starsModule, cfg.Pagination)
+	imagesModule := images.NewModule(db, blobStorage, cfg.Images)
+	awardsModule := awards.NewModule(db)
+	starsModule := stars.NewModule(db, imagesModule, awardsModule, cfg.Pagination)
+	collectionsModule := collections.NewModule(db, cfg.Pagination)
+	moviesModule := movies.NewModule(db, genresModule, starsModule, imagesModule, collectionsModule, awardsModule, cfg.Pagination)
 	reviewsModule := reviews.NewModule(db, moviesModule, cfg.Pagination)
+	trashModule := trash.NewModule(db, moviesModule, imagesModule, cfg.Trash, cfg.Pagination)
+	privacyModule := privacy.NewModule(db, usersModule, moviesModule, reviewsModule, cfg.Privacy)
+	bulkModule := bulk.NewModule(db)
+	jobsModule := jobs.NewModule(db, cfg.Jobs, cfg.Pagination)
+
+	webhooksModule := webhooks.NewModule(db, jobsModule, cfg.Webhooks, cfg.Pagination)
+	broker := live.NewBroker(db, cfg.Live)
+	streamsModule := streams.NewModule(broker, moviesModule, cfg.Live)
+	graphqlModule := graphql.NewModule(moviesModule, genresModule, starsModule, reviewsModule, usersModule, cfg.GraphQL, cfg.Pagination)
+
+	dispatcher := events.NewDispatcher(db, cfg.Events)
+	if cfg.Events.SinkURL != "" {
+		dispatcher.AddSink(events.NewHTTPSink(cfg.Events.SinkURL, cfg.Events.SinkTimeout))
+	}
+	dispatcher.Subscribe(events.AllTypes, webhooksModule.Service.HandleEvent)
+
+	jobsModule.Service.Register(trash.PurgeJobKind, trashModule.Service.PurgeJob)
+	if cfg.Trash.PurgeSchedule != "" {
+		if err = jobsModule.Service.Schedule("trash-purge", trash.PurgeJobKind, cfg.Trash.PurgeSchedule); err != nil {
+			return nil, withClosers(closers, fmt.Errorf("schedule trash purge: %w", err))
+		}
+	}
+	jobsModule.Service.Register(privacy.EraseJobKind, privacyModule.Service.EraseJob)
+	if cfg.Privacy.ErasureSchedule != "" {
+		if err = jobsModule.Service.Schedule("privacy-erase", privacy.EraseJobKind, cfg.Privacy.ErasureSchedule); err != nil {
+			return nil, withClosers(closers, fmt.Errorf("schedule user erasure: %w", err))
+		}
+	}
+	jobsModule.Service.Register(webhooks.DeliverJobKind, webhooksModule.Service.DeliverJob)
+	jobsModule.Service.Register(webhooks.CleanupJobKind, webhooksModule.Service.CleanupJob)
+	if cfg.Webhooks.CleanupSchedule != "" {
+		if err = jobsModule.Service.Schedule("webhooks-cleanup", webhooks.CleanupJobKind, cfg.Webhooks.CleanupSchedule); err != nil {
+			return nil, withClosers(closers, fmt.Errorf("schedule webhook deliveries cleanup: %w", err))
+		}
+	}
+	jobsModule.Service.Register(events.CleanupJobKind, dispatcher.Cleanup)
+	if cfg.Events.CleanupSchedule != "" {
+		if err = jobsModule.Service.Schedule("events-cleanup", events.CleanupJobKind, cfg.Events.CleanupSchedule); err != nil {
+			return nil, withClosers(closers, fmt.Errorf("schedule events cleanup: %w", err))
+		}
+	}
 
 	if err = createInitialAdminUser(cfg.Admin, authModule.Service); err != nil {
 		return nil, withClosers(closers, fmt.Errorf("create initial admin user: %w", err))
 	}
 
+	if cfg.Jobs.Workers > 0 {
+		if err = jobsModule.Worker.Start(ctx); err != nil {
+			return nil, withClosers(closers, fmt.Errorf("start job worker: %w", err))
+		}
+		closers = append(closers, func() error { return jobsModule.Worker.Shutdown(context.Background()) })
+	}
+
+	if cfg.Events.Dispatch {
+		dispatcher.Start()
+		closers = append(closers, func() error { return dispatcher.Shutdown(context.Background()) })
+	}
+
+	broker.Start()
+	closers = append(closers, func() error { return broker.Shutdown(context.Background()) })
+
 	e := echo.New()
 	e.HTTPErrorHandler = echox.ErrorHandler
 
@@ -84,8 +164,12 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 	api.GET("/users/:userId", usersModule.Handler.GetUserByID)
 	api.GET("/users/username/:username", usersModule.Handler.GetUserByUserName)
 	api.PUT("/users/:userId", usersModule.Handler.Update, auth.Self)
+	api.PATCH("/users/:userId", usersModule.Handler.Patch, auth.Self)
 	api.DELETE("/users/:userId", usersModule.Handler.DeleteUser, auth.Self)
 	api.PUT("/users/:userId/role/:role", usersModule.Handler.SetUserRole, auth.Admin)
+	api.GET("/users/:userId/export", privacyModule.Handler.Export, auth.Self)
+	api.POST("/users/:userId/erasure", privacyModule.Handler.ScheduleErasure, auth.Self)
+	api.DELETE("/users/:userId/erasure", privacyModule.Handler.CancelErasure, auth.Self)
 
 	// Genres API routes
 
@@ -93,23 +177,73 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 	api.GET("/genres/:id", genresModule.Handler.GetGenreByID)
 	api.POST("/genres", genresModule.Handler.CreateGenre, auth.Editor)
 	api.PUT("/genres/:id", genresModule.Handler.UpdateGenre, auth.Editor)
+	api.PATCH("/genres/:id", genresModule.Handler.PatchGenre, auth.Editor)
 	api.DELETE("/genres/:id", genresModule.Handler.DeleteGenre, auth.Editor)
+	api.POST("/genres/:id/aliases", genresModule.Handler.CreateAlias, auth.Editor)
+	api.DELETE("/genres/:id/aliases/:alias", genresModule.Handler.DeleteAlias, auth.Editor)
+	api.POST("/genres/:id/merge", genresModule.Handler.MergeGenre, auth.Editor)
 
 	// Stars API
 
+	api.GET("/stars/suggestions", starsModule.Handler.GetSuggestions)
+	api.GET("/stars/duplicates", starsModule.Handler.GetDuplicates, auth.Editor)
 	api.GET("/stars/:id", starsModule.Handler.GetStarByID)
+	api.GET("/stars/:id/filmography", starsModule.Handler.GetFilmography)
 	api.GET("/stars", starsModule.Handler.GetAll)
 	api.POST("/stars", starsModule.Handler.CreateStar, auth.Editor)
+	api.GET("/credit-roles", starsModule.Handler.GetCreditRoles)
+	api.POST("/credit-roles", starsModule.Handler.CreateCreditRole, auth.Editor)
 	api.PUT("/stars/:id", starsModule.Handler.UpdateStar, auth.Editor)
+	api.PATCH("/stars/:id", starsModule.Handler.PatchStar, auth.Editor)
 	api.DELETE("/stars/:id", starsModule.Handler.DeleteStar, auth.Editor)
+	api.POST("/stars/:id/merge", starsModule.Handler.MergeStar, auth.Editor)
 
 	// Movies API
 
+	api.GET("/movies/suggestions", moviesModule.Handler.GetSuggestions)
+	api.GET("/movies/duplicates", moviesModule.Handler.GetDuplicates, auth.Editor)
 	api.GET("/movies/:id", moviesModule.Handler.GetMovieByID)
 	api.GET("/movies", moviesModule.Handler.GetAll)
 	api.POST("/movies", moviesModule.Handler.CreateMovie, auth.Editor)
 	api.PUT("/movies/:id", moviesModule.Handler.UpdateMovie, auth.Editor)
+	api.PATCH("/movies/:id", moviesModule.Handler.PatchMovie, auth.Editor)
 	api.DELETE("/movies/:id", moviesModule.Handler.DeleteMovie, auth.Editor)
+	api.POST("/movies/:id/merge", moviesModule.Handler.MergeMovie, auth.Editor)
+	api.GET("/movies/:id/translations", moviesModule.Handler.GetTranslations)
+	api.PUT("/movies/:id/translations/:locale", moviesModule.Handler.PutTranslation, auth.Editor)
+	api.DELETE("/movies/:id/translations/:locale", moviesModule.Handler.DeleteTranslation, auth.Editor)
+
+	// Collections API
+
+	api.GET("/collections", collectionsModule.Handler.GetAll)
+	api.GET("/collections/:id", collectionsModule.Handler.GetByID)
+	api.POST("/collections", collectionsModule.Handler.Create, auth.Editor)
+	api.PUT("/collections/:id", collectionsModule.Handler.Update, auth.Editor)
+	api.DELETE("/collections/:id", collectionsModule.Handler.Delete, auth.Editor)
+
+	// Awards API
+
+	api.GET("/awards", awardsModule.Handler.GetCeremonies)
+	api.GET("/awards/categories", awardsModule.Handler.GetCategories)
+	api.POST("/awards/categories", awardsModule.Handler.CreateCategory, auth.Editor)
+	api.GET("/awards/:id", awardsModule.Handler.GetCeremonyByID)
+	api.POST("/awards", awardsModule.Handler.CreateCeremony, auth.Editor)
+	api.PUT("/awards/:id", awardsModule.Handler.UpdateCeremony, auth.Editor)
+	api.DELETE("/awards/:id", awardsModule.Handler.DeleteCeremony, auth.Editor)
+	api.POST("/awards/:id/nominations", awardsModule.Handler.CreateNomination, auth.Editor)
+	api.PUT("/awards/:id/nominations/:nominationId", awardsModule.Handler.UpdateNomination, auth.Editor)
+	api.DELETE("/awards/:id/nominations/:nominationId", awardsModule.Handler.DeleteNomination, auth.Editor)
+
+	// Images API
+
+	api.GET("/movies/:id/images", imagesModule.Handler.GetMovieImages)
+	api.POST("/movies/:id/images", imagesModule.Handler.UploadMovieImage, auth.Editor)
+	api.GET("/stars/:id/images", imagesModule.Handler.GetStarImages)
+	api.POST("/stars/:id/images", imagesModule.Handler.UploadStarImage, auth.Editor)
+	api.GET("/images/:id", imagesModule.Handler.GetImage)
+	api.GET("/images/:id/:variant", imagesModule.Handler.GetImageContent)
+	api.PUT("/images/:id/primary", imagesModule.Handler.SetPrimaryImage, auth.Editor)
+	api.DELETE("/images/:id", imagesModule.Handler.DeleteImage, auth.Editor)
 
 	// Reviews API
 
@@ -119,7 +253,43 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 	api.PUT("/users/:userId/reviews/:reviewId", reviewsModule.Handler.Update, auth.Self)
 	api.DELETE("/users/:userId/reviews/:reviewId", reviewsModule.Handler.Delete, auth.Self)
 
-	return &Server{e: e, cfg: cfg, closers: closers}, nil
+	// Trash API
+
+	api.GET("/trash/:kind", trashModule.Handler.GetAll, auth.Admin)
+	api.POST("/trash/:kind/:id/restore", trashModule.Handler.Restore, auth.Admin)
+
+	// Bulk API
+
+	api.POST("/bulk/:entity", bulkModule.Handler.Import, auth.Admin)
+	api.GET("/bulk/:entity", bulkModule.Handler.Export, auth.Admin)
+
+	// Jobs API
+
+	api.GET("/jobs", jobsModule.Handler.GetAll, auth.Admin)
+	api.GET("/jobs/:id", jobsModule.Handler.Get, auth.Admin)
+	api.POST("/jobs", jobsModule.Handler.Enqueue, auth.Admin)
+	api.POST("/jobs/:id/retry", jobsModule.Handler.Retry, auth.Admin)
+
+	// Streams API
+
+	api.GET("/movies/:id/events", streamsModule.Handler.MovieUpdates)
+	api.GET("/events", streamsModule.Handler.AllUpdates, auth.Editor)
+
+	// Webhooks API
+
+	api.GET("/webhooks", webhooksModule.Handler.GetAll, auth.Admin)
+	api.GET("/webhooks/:id", webhooksModule.Handler.Get, auth.Admin)
+	api.POST("/webhooks", webhooksModule.Handler.Create, auth.Admin)
+	api.PUT("/webhooks/:id", webhooksModule.Handler.Update, auth.Admin)
+	api.DELETE("/webhooks/:id", webhooksModule.Handler.Delete, auth.Admin)
+	api.GET("/webhooks/:id/deliveries", webhooksModule.Handler.GetDeliveries, auth.Admin)
+	api.POST("/webhooks/:id/deliveries/:deliveryId/replay", webhooksModule.Handler.Replay, auth.Admin)
+
+	// GraphQL API
+
+	api.POST("/graphql", graphqlModule.Handler.Query)
+
+	return &Server{e: e, cfg: cfg, worker: jobsModule.Worker, dispatcher: dispatcher, broker: broker, closers: closers}, nil
 }
 
 func (s *Server) Start() error {
@@ -128,8 +298,12 @@ func (s *Server) Start() error {
 	return s.e.Start(fmt.Sprintf(":%d", port))
 }
 
+// Shutdown ends the live update streams, stops serving requests and then waits for the running jobs
+// and the event deliveries to finish, all within the context.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.e.Shutdown(ctx)
+	// the streams would keep the requests running until the context is done
+	err := s.broker.Shutdown(ctx)
+	return errors.Join(err, s.e.Shutdown(ctx), s.worker.Shutdown(ctx), s.dispatcher.Shutdown(ctx))
 }
 
 func (s *Server) Close() error {
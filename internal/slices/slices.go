@@ -8,6 +8,14 @@ func MapIndex[S, T any](slice []S, fn func(int, S) T) []T {
 	return result
 }
 
+func Map[S, T any](slice []S, fn func(S) T) []T {
+	result := make([]T, len(slice))
+	for i, item := range slice {
+		result[i] = fn(item)
+	}
+	return result
+}
+
 func ToMap[S any, K comparable, V any](slice []S, keyFn func(S) K, valueFn func(S) V) map[K]V {
 	result := make(map[K]V, len(slice))
 	for _, item := range slice {
@@ -16,6 +24,15 @@ func ToMap[S any, K comparable, V any](slice []S, keyFn func(S) K, valueFn func(
 	return result
 }
 
+func Contains[S comparable](slice []S, item S) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
 func NoChangeFunc[S any]() func(S) S {
 	return func(item S) S { return item }
 }
@@ -0,0 +1,106 @@
+package sparse
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mkuptsov/movie-reviews/contracts"
+	"github.com/mkuptsov/movie-reviews/internal/apperrors"
+)
+
+// Includes is a set of relations requested with the include parameter, e.g. "genres,cast".
+type Includes map[string]bool
+
+// ParseIncludes parses the include parameter. When the parameter is absent, the defaults are included.
+func ParseIncludes(include *string, defaults ...string) Includes {
+	names := defaults
+	if include != nil {
+		names = ParseList(*include)
+	}
+
+	includes := make(Includes, len(names))
+	for _, name := range names {
+		includes[name] = true
+	}
+	return includes
+}
+
+func (i Includes) Has(name string) bool {
+	return i[name]
+}
+
+// ParseList splits a comma separated query parameter skipping empty entries.
+func ParseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Fields returns the fields requested with the fields parameter along with "id" and the included relations,
+// or nil if all of them are wanted.
+func Fields(fields *string, includes Includes) []string {
+	if fields == nil {
+		return nil
+	}
+
+	result := append(ParseList(*fields), "id")
+	for name := range includes {
+		result = append(result, name)
+	}
+	return result
+}
+
+// Project keeps only the given top-level fields of the JSON representation of v. Nil fields keep everything.
+func Project(v any, fields []string) (any, error) {
+	if fields == nil {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var all map[string]json.RawMessage
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected, nil
+}
+
+// ProjectPage applies Project to every item of the page.
+func ProjectPage[T any](res *contracts.PaginatedResponse[T], fields []string) (any, error) {
+	if fields == nil {
+		return res, nil
+	}
+
+	items := make([]*any, 0, len(res.Items))
+	for _, item := range res.Items {
+		projected, err := Project(item, fields)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &projected)
+	}
+
+	return &contracts.PaginatedResponse[any]{
+		Page:      res.Page,
+		Size:      res.Size,
+		Total:     res.Total,
+		Uncounted: res.Uncounted,
+		Next:      res.Next,
+		Prev:      res.Prev,
+		Items:     items,
+	}, nil
+}
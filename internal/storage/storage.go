@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mkuptsov/movie-reviews/internal/config"
+)
+
+const (
+	LocalDriver = "local"
+	S3Driver    = "s3"
+)
+
+// ErrNotFound is returned by Get when there is no object with the given key.
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps binary objects, e.g. images, under slash separated keys.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func New(ctx context.Context, cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case LocalDriver:
+		return NewLocal(cfg.LocalDir)
+	case S3Driver:
+		s3 := NewS3(cfg.S3)
+		if cfg.S3.CreateBucket {
+			if err := s3.CreateBucket(ctx); err != nil {
+				return nil, fmt.Errorf("create bucket: %w", err)
+			}
+		}
+		return s3, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
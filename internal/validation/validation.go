@@ -3,16 +3,26 @@ package validation
 import (
 	"fmt"
 	"net/mail"
+	"net/url"
+	"regexp"
 	"strings"
 
+	"github.com/mkuptsov/movie-reviews/internal/dbx"
+	"github.com/mkuptsov/movie-reviews/internal/events"
 	"github.com/mkuptsov/movie-reviews/internal/modules/users"
+	"github.com/mkuptsov/movie-reviews/internal/slices"
+	"github.com/mkuptsov/movie-reviews/internal/sparse"
 	"gopkg.in/validator.v2"
 )
 
 var (
+	codeRegexp                = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+	externalSourceRegexp      = regexp.MustCompile(`^[a-z0-9_]{1,20}$`)
+	externalIDMaxLength       = 100
 	passwordMinLength         = 8
 	passwordMaxLenth          = 72
 	emailMaxLegth             = 127
+	urlMaxLength              = 2048
 	passwordSpecialCharacters = "!%$#()[]{}?+*~@^&-_"
 	passwordRequiredEntries   = []struct {
 		name  string
@@ -34,6 +44,11 @@ func SetupValidators() {
 		{"email", email},
 		{"role", role},
 		{"sort", sort},
+		{"include", include},
+		{"codes", codes},
+		{"externalids", externalIDs},
+		{"url", webURL},
+		{"eventtypes", eventTypes},
 	}
 
 	for _, v := range validators {
@@ -93,16 +108,21 @@ func role(v interface{}, param string) error {
 	return nil
 }
 
-func sort(v interface{}, _ string) error {
+// sort validates a comma separated list of fields, each optionally prefixed with "-" for descending order,
+// against the whitelist given as the parameter, e.g. `validate:"sort=name|created_at"`.
+func sort(v interface{}, param string) error {
 	validate := func(s *string) error {
 		if s == nil {
 			return nil
 		}
-		switch *s {
-		case "desc", "asc":
-			return nil
+
+		allowed := strings.Split(param, "|")
+		for _, field := range dbx.ParseSort(*s) {
+			if !slices.Contains(allowed, field.Name) {
+				return fmt.Errorf("sort field must be one of %s", strings.Join(allowed, ", "))
+			}
 		}
-		return fmt.Errorf("sort must be one of desc or asc")
+		return nil
 	}
 
 	switch s := v.(type) {
@@ -114,3 +134,106 @@ func sort(v interface{}, _ string) error {
 		return fmt.Errorf("sort only validates string or pointer to string")
 	}
 }
+
+// include validates a comma separated list of relations against the whitelist given as the parameter,
+// e.g. `validate:"include=genres|cast"`.
+func include(v interface{}, param string) error {
+	validate := func(s *string) error {
+		if s == nil {
+			return nil
+		}
+
+		allowed := strings.Split(param, "|")
+		for _, name := range sparse.ParseList(*s) {
+			if !slices.Contains(allowed, name) {
+				return fmt.Errorf("include must be a list of %s", strings.Join(allowed, ", "))
+			}
+		}
+		return nil
+	}
+
+	switch s := v.(type) {
+	case string:
+		return validate(&s)
+	case *string:
+		return validate(s)
+	default:
+		return fmt.Errorf("include only validates string or pointer to string")
+	}
+}
+
+// codes validates a list of two letter ISO codes, e.g. of countries or languages.
+//
+//nolint:revive // function requires param
+func codes(v interface{}, param string) error {
+	list, ok := v.([]string)
+	if !ok {
+		return fmt.Errorf("codes only validates slices of strings")
+	}
+
+	for _, code := range list {
+		if !codeRegexp.MatchString(code) {
+			return fmt.Errorf("invalid code %q, two letter codes are expected", code)
+		}
+	}
+	return nil
+}
+
+// externalIDs validates external IDs keyed by their source, e.g. {"imdb": "tt0076759"}.
+//
+//nolint:revive // function requires param
+func externalIDs(v interface{}, param string) error {
+	ids, ok := v.(map[string]string)
+	if !ok {
+		return fmt.Errorf("externalids only validates maps of strings")
+	}
+
+	for source, id := range ids {
+		if !externalSourceRegexp.MatchString(source) {
+			return fmt.Errorf("invalid external source %q", source)
+		}
+		if id == "" || len(id) > externalIDMaxLength {
+			return fmt.Errorf("external id of %s must be at least 1 and not more than %d characters long", source, externalIDMaxLength)
+		}
+	}
+	return nil
+}
+
+// webURL validates an absolute http or https URL.
+//
+//nolint:revive // function requires param
+func webURL(v interface{}, param string) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("url only validates strings")
+	}
+
+	if len(s) > urlMaxLength {
+		return fmt.Errorf("url must not be longer than %d characters", urlMaxLength)
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q, an absolute http or https url is expected", s)
+	}
+	return nil
+}
+
+// eventTypes validates a non-empty list of domain event types, "*" stands for all of them.
+//
+//nolint:revive // function requires param
+func eventTypes(v interface{}, param string) error {
+	list, ok := v.([]string)
+	if !ok {
+		return fmt.Errorf("eventtypes only validates slices of strings")
+	}
+
+	if len(list) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, t := range list {
+		if t != events.AllTypes && !slices.Contains(events.Types, t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
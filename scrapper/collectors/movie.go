@@ -44,6 +44,8 @@ func NewMovieCollector(c *colly.Collector, castCollector *CastCollector, logger
 			Description   string   `json:"description"`
 			Genre         []string `json:"genre"`
 			DatePublished string   `json:"datePublished"`
+			Duration      string   `json:"duration"`
+			ContentRating string   `json:"contentRating"`
 		}
 
 		var info movieInfo
@@ -60,6 +62,11 @@ func NewMovieCollector(c *colly.Collector, castCollector *CastCollector, logger
 		movie.Description = info.Description
 		movie.Genres = info.Genre
 		movie.ReleaseDate = mustParseDate(info.DatePublished)
+		movie.Runtime = parseDuration(info.Duration)
+		movie.Certification = info.ContentRating
+		movie.Tagline = wordsSanitizer(e.ChildText("li[data-testid='storyline-taglines'] .ipc-metadata-list-item__list-content-item"))
+		movie.Countries = queryValues(e, "li[data-testid='title-details-origin'] a", "country_of_origin")
+		movie.Languages = queryValues(e, "li[data-testid='title-details-languages'] a", "primary_language")
 
 		collector.toAllGenres(movie.Genres)
 
@@ -129,3 +136,30 @@ func mustParseDate(date string) time.Time {
 
 	return t
 }
+
+// parseDuration converts an ISO 8601 duration, e.g. PT2H1M, to minutes.
+func parseDuration(duration string) int {
+	d, err := time.ParseDuration(strings.ToLower(strings.TrimPrefix(duration, "PT")))
+	if err != nil {
+		return 0
+	}
+
+	return int(d.Minutes())
+}
+
+// queryValues collects the values of a query parameter from the links matched by the selector,
+// e.g. the country codes from links like /search/title/?country_of_origin=US.
+func queryValues(e *colly.HTMLElement, selector, param string) []string {
+	var values []string
+	e.ForEach(selector, func(_ int, el *colly.HTMLElement) {
+		link, err := url.Parse(el.Attr("href"))
+		if err != nil {
+			return
+		}
+		if value := link.Query().Get(param); value != "" {
+			values = append(values, value)
+		}
+	})
+
+	return values
+}
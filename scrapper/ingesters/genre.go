@@ -41,6 +41,12 @@ func (i *GenreIngester) Ingest(genres []string) error {
 	}
 
 	nameToGenreMap := slices.ToMap(existingGenres, getGenreName, slices.NoChangeFunc[*contracts.Genre]())
+	// Aliases resolve to their canonical genres, so near-duplicates such as "Sci-Fi" are not created again
+	for _, genre := range existingGenres {
+		for _, alias := range genre.Aliases {
+			nameToGenreMap[alias] = genre
+		}
+	}
 	var mx sync.RWMutex
 
 	group, _ := errgroup.WithContext(context.Background())
@@ -75,8 +81,8 @@ func (i *GenreIngester) Ingest(genres []string) error {
 	}
 
 	i.conversionMap = make(map[string]int, len(nameToGenreMap))
-	for _, genre := range nameToGenreMap {
-		i.conversionMap[genre.Name] = genre.ID
+	for name, genre := range nameToGenreMap {
+		i.conversionMap[name] = genre.ID
 	}
 
 	i.logger.Info("Successfully ingested genres")
@@ -3,6 +3,7 @@ package ingesters
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +17,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// similarTitleThreshold is the minimal trigram similarity for an existing movie with the same
+// release date to be treated as the movie being ingested, e.g. when titles differ by a typo.
+const similarTitleThreshold = 0.6
+
 type MovieIngester struct {
 	c                *client.Client
 	token            string
@@ -67,12 +72,35 @@ func (i *MovieIngester) Ingest(movies map[string]*models.Movie, casts map[string
 		}
 
 		group.Go(func() error {
-			var created bool
+			var created, matched bool
 			_, created, err = maps.GetOrCreateLocked(idToMovieMap, commonID, &mx, func(name movieCommonIdentifier) (*contracts.Movie, error) {
+				similar, ok, serr := i.findSimilar(movie)
+				if serr != nil {
+					return nil, fmt.Errorf("find similar movie: %w", serr)
+				}
+				if ok {
+					matched = true
+					return similar, nil
+				}
+
 				req := &contracts.CreateMovieRequest{
 					Title:       movie.Title,
 					ReleaseDate: movie.ReleaseDate,
 					Description: movie.Description,
+					MovieMetadata: contracts.MovieMetadata{
+						Countries:       movie.Countries,
+						SpokenLanguages: movie.Languages,
+						ExternalIDs:     map[string]string{"imdb": movie.ID},
+					},
+				}
+				if movie.Tagline != "" {
+					req.Tagline = &movie.Tagline
+				}
+				if movie.Runtime > 0 {
+					req.Runtime = &movie.Runtime
+				}
+				if movie.Certification != "" {
+					req.Certifications = []*contracts.Certification{{Country: "US", Certification: movie.Certification}}
 				}
 
 				// Prepare genres
@@ -104,9 +132,7 @@ func (i *MovieIngester) Ingest(movies map[string]*models.Movie, casts map[string
 						StarID: starID,
 						Role:   credit.Role,
 					}
-					if credit.Details != "" {
-						creditInfo.Details = &credit.Details
-					}
+					applyCreditDetails(creditInfo, credit.Details)
 
 					req.Cast = append(req.Cast, creditInfo)
 				}
@@ -123,7 +149,13 @@ func (i *MovieIngester) Ingest(movies map[string]*models.Movie, casts map[string
 				return err
 			}
 
-			if created {
+			switch {
+			case matched:
+				i.logger.
+					With("movie_id", movie.ID).
+					With("movie_common_id", commonID).
+					Debug("Matched similar movie")
+			case created:
 				i.logger.
 					With("movie_id", movie.ID).
 					With("movie_common_id", commonID).
@@ -141,3 +173,43 @@ func (i *MovieIngester) Ingest(movies map[string]*models.Movie, casts map[string
 	i.logger.Info("Successfully ingested movies")
 	return nil
 }
+
+func (i *MovieIngester) findSimilar(movie *models.Movie) (*contracts.Movie, bool, error) {
+	suggestions, err := i.c.GetMovieSuggestions(&contracts.GetMovieSuggestionsRequest{Query: movie.Title})
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, s := range suggestions {
+		if s.Similarity >= similarTitleThreshold && s.ReleaseDate.Equal(movie.ReleaseDate) {
+			return &contracts.Movie{
+				ID:          s.ID,
+				Title:       s.Title,
+				ReleaseDate: s.ReleaseDate,
+			}, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// applyCreditDetails maps scraped free-form details to characters for cast roles and to the job for crew ones.
+func applyCreditDetails(info *contracts.MovieCreditInfo, details string) {
+	if details == "" {
+		return
+	}
+
+	if info.Role != "actor" && info.Role != "voice actor" {
+		info.Job = &details
+		return
+	}
+
+	info.Voice = info.Role == "voice actor" || strings.Contains(details, "(voice)")
+	info.Uncredited = strings.Contains(details, "(uncredited)")
+	details = strings.NewReplacer("(voice)", "", "(uncredited)", "").Replace(details)
+	for _, character := range strings.FieldsFunc(details, func(r rune) bool { return r == ',' || r == '/' }) {
+		if character = strings.TrimSpace(character); character != "" {
+			info.Characters = append(info.Characters, character)
+		}
+	}
+}
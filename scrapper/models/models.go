@@ -8,6 +8,12 @@ type Movie struct {
 	Description string    `json:"description"`
 	ReleaseDate time.Time `json:"release_date"`
 	Genres      []string  `json:"genres"`
+	Tagline     string    `json:"tagline"`
+	Runtime     int       `json:"runtime"`
+	Countries   []string  `json:"countries"`
+	Languages   []string  `json:"languages"`
+	// Certification is the US one, IMDb pages are scrapped with the US locale
+	Certification string `json:"certification"`
 
 	Link string `json:"_link"`
 }
@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_stars_name_trgm ON stars USING GIN ((first_name || ' ' || last_name) gin_trgm_ops);
+---- create above / drop below ----
+DROP INDEX idx_stars_name_trgm;
+DROP INDEX idx_movies_title_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
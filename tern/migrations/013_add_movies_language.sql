@@ -0,0 +1,65 @@
+CREATE TABLE languages (
+    code CHAR(2) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    search_config REGCONFIG NOT NULL
+);
+
+INSERT INTO languages (code, name, search_config) VALUES
+    ('ar', 'Arabic', 'arabic'),
+    ('da', 'Danish', 'danish'),
+    ('de', 'German', 'german'),
+    ('el', 'Greek', 'greek'),
+    ('en', 'English', 'english'),
+    ('es', 'Spanish', 'spanish'),
+    ('fi', 'Finnish', 'finnish'),
+    ('fr', 'French', 'french'),
+    ('ga', 'Irish', 'irish'),
+    ('hi', 'Hindi', 'simple'),
+    ('hu', 'Hungarian', 'hungarian'),
+    ('id', 'Indonesian', 'indonesian'),
+    ('it', 'Italian', 'italian'),
+    ('ja', 'Japanese', 'simple'),
+    ('ko', 'Korean', 'simple'),
+    ('lt', 'Lithuanian', 'lithuanian'),
+    ('ne', 'Nepali', 'nepali'),
+    ('nl', 'Dutch', 'dutch'),
+    ('no', 'Norwegian', 'norwegian'),
+    ('pl', 'Polish', 'simple'),
+    ('pt', 'Portuguese', 'portuguese'),
+    ('ro', 'Romanian', 'romanian'),
+    ('ru', 'Russian', 'russian'),
+    ('sv', 'Swedish', 'swedish'),
+    ('ta', 'Tamil', 'tamil'),
+    ('tr', 'Turkish', 'turkish'),
+    ('zh', 'Chinese', 'simple');
+
+ALTER TABLE movies ADD COLUMN language CHAR(2) NOT NULL DEFAULT 'en' REFERENCES languages(code);
+
+CREATE OR REPLACE FUNCTION movies_search_vector_trigger() RETURNS TRIGGER AS $$
+declare
+    config regconfig;
+begin
+    SELECT search_config INTO config FROM languages WHERE code = new.language;
+    config := coalesce(config, 'simple'::regconfig);
+
+    new.search_vector :=
+        setweight(to_tsvector(config, new.title), 'A') ||
+        setweight(to_tsvector(config, new.description), 'B');
+    return new;
+end
+$$ LANGUAGE plpgsql;
+---- create above / drop below ----
+CREATE OR REPLACE FUNCTION movies_search_vector_trigger() RETURNS TRIGGER AS $$
+begin
+    new.search_vector :=
+        setweight(to_tsvector('english', new.title), 'A') ||
+        setweight(to_tsvector('english', new.description), 'B');
+    return new;
+end
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE movies DROP COLUMN language;
+DROP TABLE languages;
+
+-- re-run the trigger so that vectors are rebuilt with the english configuration
+UPDATE movies SET title = title;
@@ -0,0 +1,9 @@
+CREATE TABLE movie_translations (
+    movie_id INTEGER NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    locale VARCHAR(5) NOT NULL,
+    title VARCHAR(255),
+    description TEXT,
+    PRIMARY KEY (movie_id, locale)
+);
+---- create above / drop below ----
+DROP TABLE movie_translations;
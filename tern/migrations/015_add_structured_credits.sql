@@ -0,0 +1,69 @@
+CREATE TABLE credit_roles (
+    name VARCHAR(50) PRIMARY KEY,
+    department VARCHAR(50) NOT NULL,
+    is_cast BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+INSERT INTO credit_roles (name, department, is_cast) VALUES
+    ('actor', 'Acting', TRUE),
+    ('voice actor', 'Acting', TRUE),
+    ('writer', 'Writing', FALSE),
+    ('producer', 'Production', FALSE),
+    ('director', 'Directing', FALSE),
+    ('composer', 'Sound', FALSE);
+
+ALTER TABLE movie_stars
+    ALTER COLUMN role TYPE VARCHAR(50) USING role::text,
+    ADD CONSTRAINT movie_stars_role_fkey FOREIGN KEY (role) REFERENCES credit_roles(name),
+    ADD COLUMN characters TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN job VARCHAR(100),
+    ADD COLUMN uncredited BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN voice BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE movie_stars SET voice = TRUE WHERE role = 'voice actor';
+
+UPDATE movie_stars
+SET
+    uncredited = details ~* '\(uncredited\)',
+    voice = voice OR details ~* '\(voice\)'
+WHERE details IS NOT NULL;
+
+-- Cast details are character names separated by commas or slashes, e.g. "Luke Skywalker / Red Five (voice)"
+UPDATE movie_stars ms
+SET characters = ARRAY(
+    SELECT btrim(c)
+    FROM unnest(regexp_split_to_array(regexp_replace(ms.details, '\((uncredited|voice)\)', '', 'gi'), '[,/]')) AS c
+    WHERE btrim(c) <> ''
+)
+FROM credit_roles cr
+WHERE cr.name = ms.role and cr.is_cast and ms.details IS NOT NULL;
+
+-- Crew details describe the exact job, e.g. "screenplay" for a writer
+UPDATE movie_stars ms
+SET job = left(btrim(ms.details), 100)
+FROM credit_roles cr
+WHERE cr.name = ms.role and NOT cr.is_cast and btrim(ms.details) <> '';
+
+ALTER TABLE movie_stars DROP COLUMN details;
+DROP TYPE movie_role;
+---- create above / drop below ----
+CREATE TYPE movie_role AS ENUM ('actor', 'voice actor', 'writer', 'producer', 'director', 'composer');
+
+ALTER TABLE movie_stars ADD COLUMN details TEXT NULL;
+
+UPDATE movie_stars
+SET details = nullif(concat_ws(' ',
+    nullif(coalesce(job, array_to_string(characters, ', ')), ''),
+    CASE WHEN voice and role <> 'voice actor' THEN '(voice)' END,
+    CASE WHEN uncredited THEN '(uncredited)' END
+), '');
+
+ALTER TABLE movie_stars
+    DROP CONSTRAINT movie_stars_role_fkey,
+    ALTER COLUMN role TYPE movie_role USING role::movie_role,
+    DROP COLUMN characters,
+    DROP COLUMN job,
+    DROP COLUMN uncredited,
+    DROP COLUMN voice;
+
+DROP TABLE credit_roles;
@@ -0,0 +1,29 @@
+ALTER TABLE movies
+    ADD COLUMN runtime INTEGER CHECK (runtime > 0),
+    ADD COLUMN tagline VARCHAR(255),
+    ADD COLUMN countries CHAR(2)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN spoken_languages CHAR(2)[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE movie_certifications (
+    movie_id INTEGER NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    certification VARCHAR(20) NOT NULL,
+    PRIMARY KEY (movie_id, country)
+);
+
+CREATE TABLE movie_external_ids (
+    movie_id INTEGER NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (movie_id, source),
+    CONSTRAINT movie_external_ids_source_external_id_key UNIQUE (source, external_id)
+);
+---- create above / drop below ----
+DROP TABLE movie_external_ids;
+DROP TABLE movie_certifications;
+
+ALTER TABLE movies
+    DROP COLUMN runtime,
+    DROP COLUMN tagline,
+    DROP COLUMN countries,
+    DROP COLUMN spoken_languages;
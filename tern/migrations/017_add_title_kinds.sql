@@ -0,0 +1,38 @@
+ALTER TABLE movies
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'movie' CHECK (kind IN ('movie', 'series', 'season', 'episode')),
+    ADD COLUMN parent_id INTEGER REFERENCES movies(id),
+    ADD COLUMN number INTEGER CHECK (number > 0),
+    -- seasons and episodes are numbered within their parents, movies and series are top level titles
+    ADD CONSTRAINT movies_parent_check CHECK ((kind IN ('season', 'episode')) = (parent_id IS NOT NULL and number IS NOT NULL));
+
+CREATE UNIQUE INDEX movies_parent_id_number_key ON movies(parent_id, number) WHERE deleted_at IS NULL;
+
+-- title_subtree returns the title itself and all its non-deleted descendants, e.g. seasons and episodes of a series
+CREATE FUNCTION title_subtree(root INTEGER) RETURNS SETOF INTEGER AS $$
+    WITH RECURSIVE subtree AS (
+        SELECT root AS id
+        UNION ALL
+        SELECT m.id FROM movies m JOIN subtree s ON m.parent_id = s.id WHERE m.deleted_at IS NULL
+    )
+    SELECT id FROM subtree;
+$$ LANGUAGE sql STABLE;
+
+-- title_lineage returns the title itself and all its ancestors up to the series
+CREATE FUNCTION title_lineage(leaf INTEGER) RETURNS SETOF INTEGER AS $$
+    WITH RECURSIVE lineage AS (
+        SELECT id, parent_id FROM movies WHERE id = leaf
+        UNION ALL
+        SELECT m.id, m.parent_id FROM movies m JOIN lineage l ON m.id = l.parent_id
+    )
+    SELECT id FROM lineage;
+$$ LANGUAGE sql STABLE;
+---- create above / drop below ----
+DROP FUNCTION title_lineage(INTEGER);
+DROP FUNCTION title_subtree(INTEGER);
+DROP INDEX movies_parent_id_number_key;
+
+ALTER TABLE movies
+    DROP CONSTRAINT movies_parent_check,
+    DROP COLUMN number,
+    DROP COLUMN parent_id,
+    DROP COLUMN kind;
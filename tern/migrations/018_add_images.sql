@@ -0,0 +1,22 @@
+CREATE TABLE images (
+    id SERIAL PRIMARY KEY,
+    movie_id INTEGER REFERENCES movies(id) ON DELETE CASCADE,
+    star_id INTEGER REFERENCES stars(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('poster', 'backdrop', 'still', 'photo')),
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    variants JSONB NOT NULL DEFAULT '[]',
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- an image belongs either to a movie or to a star
+    CONSTRAINT images_owner_check CHECK ((movie_id IS NULL) <> (star_id IS NULL))
+);
+
+CREATE INDEX idx_images_movie_id ON images(movie_id);
+CREATE INDEX idx_images_star_id ON images(star_id);
+CREATE UNIQUE INDEX images_movie_id_primary_key ON images(movie_id) WHERE is_primary;
+CREATE UNIQUE INDEX images_star_id_primary_key ON images(star_id) WHERE is_primary;
+---- create above / drop below ----
+DROP TABLE images;
@@ -0,0 +1,18 @@
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE collection_movies (
+    collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL REFERENCES movies(id),
+    order_no INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, movie_id)
+);
+
+CREATE INDEX idx_collection_movies_movie_id ON collection_movies(movie_id);
+---- create above / drop below ----
+DROP TABLE collection_movies;
+DROP TABLE collections;
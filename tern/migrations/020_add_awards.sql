@@ -0,0 +1,41 @@
+CREATE TABLE award_ceremonies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    year INTEGER NOT NULL,
+    held_on DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT award_ceremonies_name_year_key UNIQUE (name, year)
+);
+
+CREATE TABLE award_categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE
+);
+
+INSERT INTO award_categories (name) VALUES
+    ('Best Picture'),
+    ('Best Director'),
+    ('Best Actor'),
+    ('Best Actress'),
+    ('Best Supporting Actor'),
+    ('Best Supporting Actress'),
+    ('Best Original Screenplay'),
+    ('Best Original Score');
+
+CREATE TABLE award_nominations (
+    id SERIAL PRIMARY KEY,
+    ceremony_id INTEGER NOT NULL REFERENCES award_ceremonies(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES award_categories(id),
+    movie_id INTEGER NOT NULL REFERENCES movies(id),
+    -- star is set for personal categories, e.g. Best Director
+    star_id INTEGER REFERENCES stars(id),
+    winner BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT award_nominations_nominee_key UNIQUE NULLS NOT DISTINCT (ceremony_id, category_id, movie_id, star_id)
+);
+
+CREATE INDEX idx_award_nominations_movie_id ON award_nominations(movie_id);
+CREATE INDEX idx_award_nominations_star_id ON award_nominations(star_id);
+---- create above / drop below ----
+DROP TABLE award_nominations;
+DROP TABLE award_categories;
+DROP TABLE award_ceremonies;
@@ -0,0 +1,18 @@
+ALTER TABLE genres
+    ADD COLUMN parent_id INTEGER REFERENCES genres(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_genres_parent_id ON genres(parent_id);
+
+-- aliases are alternative names resolving to a canonical genre, e.g. Sci-Fi to Science Fiction
+CREATE TABLE genre_aliases (
+    name VARCHAR(50) NOT NULL,
+    genre_id INTEGER NOT NULL REFERENCES genres(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX genre_aliases_name_key ON genre_aliases(lower(name));
+CREATE INDEX idx_genre_aliases_genre_id ON genre_aliases(genre_id);
+---- create above / drop below ----
+DROP TABLE genre_aliases;
+
+ALTER TABLE genres
+    DROP COLUMN parent_id;
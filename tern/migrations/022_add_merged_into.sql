@@ -0,0 +1,12 @@
+-- merged_into points deleted duplicates to their canonical entity, so that old links keep working
+ALTER TABLE stars
+    ADD COLUMN merged_into INTEGER REFERENCES stars(id);
+
+ALTER TABLE movies
+    ADD COLUMN merged_into INTEGER REFERENCES movies(id);
+---- create above / drop below ----
+ALTER TABLE movies
+    DROP COLUMN merged_into;
+
+ALTER TABLE stars
+    DROP COLUMN merged_into;
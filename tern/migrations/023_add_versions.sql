@@ -0,0 +1,10 @@
+ALTER TABLE stars ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE genres ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE reviews ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+---- create above / drop below ----
+
+ALTER TABLE reviews DROP COLUMN version;
+ALTER TABLE users DROP COLUMN version;
+ALTER TABLE genres DROP COLUMN version;
+ALTER TABLE stars DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- erase_at is set when the user requests the erasure of their account, it can be cancelled until then
+ALTER TABLE users ADD COLUMN erase_at TIMESTAMP;
+---- create above / drop below ----
+ALTER TABLE users DROP COLUMN erase_at;
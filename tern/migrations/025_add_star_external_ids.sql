@@ -0,0 +1,10 @@
+-- external ids identify stars across environments and data sources, e.g. in bulk imports
+CREATE TABLE star_external_ids (
+    star_id INTEGER NOT NULL REFERENCES stars(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (star_id, source),
+    CONSTRAINT star_external_ids_source_external_id_key UNIQUE (source, external_id)
+);
+---- create above / drop below ----
+DROP TABLE star_external_ids;
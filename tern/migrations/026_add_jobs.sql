@@ -0,0 +1,28 @@
+-- jobs are processed by the workers of any instance, a pending job is claimed with SELECT ... FOR UPDATE SKIP LOCKED
+CREATE TABLE jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_pending_run_at ON jobs(run_at) WHERE status = 'pending';
+CREATE INDEX idx_jobs_running_locked_at ON jobs(locked_at) WHERE status = 'running';
+
+-- schedules enqueue a job of their kind whenever next_run_at comes, only one instance does so
+CREATE TABLE job_schedules (
+    name VARCHAR(50) PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    cron VARCHAR(100) NOT NULL,
+    next_run_at TIMESTAMP NOT NULL
+);
+---- create above / drop below ----
+DROP TABLE job_schedules;
+DROP TABLE jobs;
@@ -0,0 +1,17 @@
+-- events are written in the same transaction as the change they describe and dispatched at least once afterwards
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    subject_id INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    dispatched_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(next_attempt_at) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_outbox_events_dispatched_at ON outbox_events(dispatched_at) WHERE dispatched_at IS NOT NULL;
+---- create above / drop below ----
+DROP TABLE outbox_events;
@@ -0,0 +1,14 @@
+-- events which have run out of delivery attempts are set aside as dead letters, they are kept until handled manually
+ALTER TABLE outbox_events
+    ADD COLUMN failed_at TIMESTAMP;
+
+DROP INDEX idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(next_attempt_at) WHERE dispatched_at IS NULL and failed_at IS NULL;
+CREATE INDEX idx_outbox_events_failed_at ON outbox_events(failed_at) WHERE failed_at IS NOT NULL;
+---- create above / drop below ----
+DROP INDEX idx_outbox_events_failed_at;
+DROP INDEX idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(next_attempt_at) WHERE dispatched_at IS NULL;
+
+ALTER TABLE outbox_events
+    DROP COLUMN failed_at;
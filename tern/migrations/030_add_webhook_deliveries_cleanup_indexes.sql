@@ -0,0 +1,6 @@
+-- the cleanup looks up the old deliveries and then all the deliveries of their events, replays included
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+CREATE INDEX idx_webhook_deliveries_webhook_event ON webhook_deliveries(webhook_id, event_id);
+---- create above / drop below ----
+DROP INDEX idx_webhook_deliveries_webhook_event;
+DROP INDEX idx_webhook_deliveries_created_at;
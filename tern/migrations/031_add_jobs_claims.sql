@@ -0,0 +1,7 @@
+-- claims counts the claims of a job and is never reset, unlike attempts which a retry starts over,
+-- so that the worker of a former claim can't finish the job once it is claimed again
+ALTER TABLE jobs
+    ADD COLUMN claims INTEGER NOT NULL DEFAULT 0;
+---- create above / drop below ----
+ALTER TABLE jobs
+    DROP COLUMN claims;